@@ -0,0 +1,61 @@
+// Command openapi writes the OpenAPI document of the API, or with -check
+// verifies that the committed document is up to date:
+//
+//	go run ./cmd/openapi              # writes api/openapi.json
+//	go run ./cmd/openapi -check       # exits 1 if it is stale
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"backend-go-fiber/internal/routes"
+)
+
+func main() {
+	output := flag.String("o", "api/openapi.json", "document path")
+	check := flag.Bool("check", false, "fail if the document at -o is out of date instead of writing it")
+	flag.Parse()
+
+	versions, err := routes.Versions()
+	if err != nil {
+		fail("invalid API versions: %v", err)
+	}
+
+	doc, problems := routes.Spec(routes.DocsApp(versions), versions)
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		fail("routes and routes.Docs don't match")
+	}
+
+	data, err := doc.JSON()
+	if err != nil {
+		fail("failed to encode document: %v", err)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*output)
+		if err != nil {
+			fail("failed to read document: %v", err)
+		}
+		if !bytes.Equal(current, data) {
+			fail("%s is out of date, run: go run ./cmd/openapi", *output)
+		}
+		fmt.Printf("%s is up to date\n", *output)
+		return
+	}
+
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fail("failed to write document: %v", err)
+	}
+	fmt.Printf("Wrote %s\n", *output)
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
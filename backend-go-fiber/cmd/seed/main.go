@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"backend-go-fiber/internal/config"
 	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/utils"
 
@@ -20,15 +21,15 @@ func main() {
 	// Configure logging
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
-	// Get database URL
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		dbURL = "file:./data/db/sqlite/app.db?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL&_cache_size=1000000000&_foreign_keys=ON"
+	// Same configuration as the server (config file, environment, flags)
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
 	}
+	dbURL := cfg.Database.URL
 
 	// Connect to database
 	var db *gorm.DB
-	var err error
 
 	if strings.HasPrefix(dbURL, "postgres://") || strings.HasPrefix(dbURL, "postgresql://") {
 		log.Info().Msg("Connecting to PostgreSQL database")
@@ -44,10 +45,16 @@ func main() {
 
 	// Run migrations
 	log.Info().Msg("Running migrations...")
+	if err := models.MigratePlaintextTokens(db, models.TokenModels()...); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate tokens")
+	}
 	if err := db.AutoMigrate(
 		&models.User{},
 		&models.RefreshToken{},
 		&models.PasswordResetToken{},
+		&models.Organization{},
+		&models.Membership{},
+		&models.OrganizationInvitation{},
 	); err != nil {
 		log.Fatal().Err(err).Msg("Failed to run migrations")
 	}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/config"
+	"backend-go-fiber/internal/health"
+	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/services/email"
+	"backend-go-fiber/internal/services/storage"
+	"backend-go-fiber/internal/shutdown"
+	"backend-go-fiber/internal/tlsconfig"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Components built from the configuration, at startup and again on reload.
+// Only the ones registered in registerReloadable change at runtime.
+
+// logLevel maps log.level to zerolog
+func logLevel(level string) zerolog.Level {
+	switch level {
+	case "debug":
+		return zerolog.DebugLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "error":
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// configureGlobals applies the settings read by package-level state
+func configureGlobals(cfg *config.Config) {
+	zerolog.SetGlobalLevel(logLevel(cfg.Log.Level))
+	utils.ConfigureJWT(utils.JWTConfig{
+		Secret:           cfg.JWT.Secret,
+		ExpiresIn:        cfg.JWT.ExpiresIn,
+		RefreshTokenDays: cfg.JWT.RefreshTokenDays,
+	})
+	utils.SetProblemTypeBaseURL(cfg.ProblemTypeBaseURL)
+	apperror.ExposeCauses(cfg.Env == config.EnvDevelopment)
+}
+
+// newEmailSender returns the SMTP sender in production, the mock (emails
+// logged to console) otherwise, and the provider name
+func newEmailSender(cfg *config.Config) (email.Sender, string) {
+	if !cfg.IsProduction() {
+		return email.NewInstrumentedSender(email.NewMockSender(email.Config{}), "mock"), "mock"
+	}
+
+	sender := email.NewSMTPSender(email.Config{
+		FromName:     cfg.SMTP.FromName,
+		FromAddress:  cfg.SMTP.FromAddress,
+		SMTPHost:     cfg.SMTP.Host,
+		SMTPPort:     cfg.SMTP.Port,
+		SMTPUser:     cfg.SMTP.User,
+		SMTPPassword: cfg.SMTP.Password,
+		SMTPUseTLS:   cfg.SMTP.UseTLS,
+	})
+	return email.NewInstrumentedSender(sender, "smtp"), "smtp"
+}
+
+// newStorage returns S3 storage when a bucket is configured, local storage
+// in ./data/uploads otherwise, and the backend name
+func newStorage(cfg *config.Config) (storage.Storage, string, error) {
+	if cfg.S3.Bucket == "" {
+		local, err := storage.NewLocalStorage(uploadsDir, "/uploads")
+		if err != nil {
+			return nil, "", err
+		}
+		return storage.NewTracedStorage(local, "local"), "local", nil
+	}
+
+	s3, err := storage.NewS3Storage(cfg.S3.Bucket, cfg.S3.Region, cfg.S3.Endpoint, cfg.S3.AccessKey, cfg.S3.SecretKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return storage.NewTracedStorage(s3, "s3"), "s3", nil
+}
+
+// uploadsDir is where local storage keeps files
+const uploadsDir = "./data/uploads"
+
+// healthSentinelKey is looked up by the storage check; it doesn't need to exist
+const healthSentinelKey = ".health"
+
+// errShuttingDown fails readiness while the server drains
+var errShuttingDown = errors.New("shutting down")
+
+// registerHealthChecks lists what /ready checks. Critical checks make the
+// instance not ready; the others are reported as warnings.
+func registerHealthChecks(r *health.Registry, cfg *config.Config, db *sql.DB, store storage.Storage, sender email.Sender, lifecycle *shutdown.Manager) {
+	r.Register(health.Check{
+		Name:          "shutdown",
+		ComponentType: health.TypeSystem,
+		Critical:      true,
+		Run: func(context.Context) error {
+			if lifecycle.Draining() {
+				return errShuttingDown
+			}
+			return nil
+		},
+	})
+
+	r.Register(health.Check{
+		Name:          "database",
+		ComponentType: health.TypeDatastore,
+		Critical:      true,
+		Timeout:       2 * time.Second,
+		CacheTTL:      time.Second,
+		Run:           db.PingContext,
+	})
+
+	r.Register(health.Check{
+		Name:          "storage",
+		ComponentType: health.TypeDatastore,
+		Timeout:       3 * time.Second,
+		CacheTTL:      30 * time.Second,
+		Run: func(ctx context.Context) error {
+			_, err := store.Exists(ctx, healthSentinelKey)
+			return err
+		},
+	})
+
+	// The mock sender has nothing to check
+	if cfg.IsProduction() {
+		r.Register(health.Check{
+			Name:          "smtp",
+			ComponentType: health.TypeComponent,
+			Timeout:       5 * time.Second,
+			CacheTTL:      time.Minute,
+			Run: func(ctx context.Context) error {
+				return email.Check(ctx, sender)
+			},
+		})
+	}
+
+	minFree := uint64(cfg.Health.DiskMinFreeMB) << 20
+	if dir, ok := sqliteDir(cfg.Database.URL); ok {
+		r.Register(health.Check{
+			Name:          "disk:database",
+			ComponentType: health.TypeSystem,
+			Critical:      true,
+			CacheTTL:      30 * time.Second,
+			Run:           health.DiskFree(dir, minFree),
+		})
+	}
+	if cfg.S3.Bucket == "" {
+		r.Register(health.Check{
+			Name:          "disk:uploads",
+			ComponentType: health.TypeSystem,
+			CacheTTL:      30 * time.Second,
+			Run:           health.DiskFree(uploadsDir, minFree),
+		})
+	}
+}
+
+// sqliteDir returns the directory of a SQLite database URL
+func sqliteDir(url string) (string, bool) {
+	var path string
+	switch {
+	case strings.HasPrefix(url, "file:"):
+		path = strings.TrimPrefix(url, "file:")
+	case strings.HasPrefix(url, "sqlite:"):
+		path = strings.TrimPrefix(url, "sqlite:")
+	default:
+		return "", false
+	}
+	path, _, _ = strings.Cut(path, "?")
+	if path == "" || path == ":memory:" {
+		return "", false
+	}
+	return filepath.Dir(path), true
+}
+
+// tlsFiles and clientAuth map the tls.* settings
+func tlsFiles(cfg *config.Config) tlsconfig.Files {
+	return tlsconfig.Files{
+		CertFile:     cfg.TLS.CertFile,
+		KeyFile:      cfg.TLS.KeyFile,
+		ClientCAFile: cfg.TLS.ClientCAFile,
+	}
+}
+
+func clientAuth(cfg *config.Config) tlsconfig.ClientAuth {
+	return tlsconfig.ClientAuth{
+		Routes:     cfg.TLS.ClientAuthRoutes,
+		Identities: cfg.TLS.ClientIdentities,
+	}
+}
+
+// registerReloadable lists what a reload (SIGHUP, POST /api/admin/config/reload) swaps
+// (certs is nil without TLS)
+func registerReloadable(r *config.Reloader, sender *email.SwappableSender, store *storage.SwappableStorage, certs *tlsconfig.Store, settings *services.SettingsCache) {
+	r.Register(config.Component{
+		Name: "globals",
+		Keys: []string{"log.level", "jwt.", "problem_type_base_url"},
+		Prepare: func(cfg *config.Config) (func(), error) {
+			return func() { configureGlobals(cfg) }, nil
+		},
+	})
+
+	r.Register(config.Component{
+		Name: "email",
+		Keys: []string{"smtp."},
+		Prepare: func(cfg *config.Config) (func(), error) {
+			next, _ := newEmailSender(cfg)
+			return func() { sender.Swap(next) }, nil
+		},
+	})
+
+	r.Register(config.Component{
+		Name: "storage",
+		Keys: []string{"s3."},
+		Prepare: func(cfg *config.Config) (func(), error) {
+			next, _, err := newStorage(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return func() { store.Swap(next) }, nil
+		},
+	})
+
+	// Certificate paths and client auth; switching TLS on or off needs a restart
+	if certs != nil {
+		r.Register(config.Component{
+			Name: "tls",
+			Keys: []string{"tls.cert_file", "tls.key_file", "tls.client_"},
+			Prepare: func(cfg *config.Config) (func(), error) {
+				if !cfg.TLS.Enabled() {
+					return nil, errors.New("disabling TLS requires a restart")
+				}
+				next, err := tlsconfig.Load(tlsFiles(cfg))
+				if err != nil {
+					return nil, err
+				}
+				return func() { certs.Swap(next, clientAuth(cfg)) }, nil
+			},
+		})
+	}
+
+	// Rate limits, CORS and IP rules live in settings: pick up changes now
+	// instead of at the next poll
+	r.Register(config.Component{
+		Name: "settings",
+		Keys: []string{""},
+		Prepare: func(*config.Config) (func(), error) {
+			return func() {
+				if err := settings.Reload(); err != nil {
+					log.Error().Err(err).Msg("Failed to reload settings")
+				}
+			}, nil
+		},
+	})
+}
+
+// cleanupInterval is how often expired invitations and reset tokens are removed
+const cleanupInterval = time.Hour
+
+// cleanupExpired removes expired organization invitations and password reset
+// tokens every cleanupInterval until ctx is cancelled
+func cleanupExpired(ctx context.Context, orgs *services.OrganizationService, resets *services.PasswordResetService) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := orgs.CleanupExpiredInvitations(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to clean up expired invitations")
+		}
+		if err := resets.CleanupExpiredTokens(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to clean up expired password reset tokens")
+		}
+	}
+}
@@ -1,25 +1,49 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/config"
 	"backend-go-fiber/internal/handlers"
 	adminHandlers "backend-go-fiber/internal/handlers/admin"
+	"backend-go-fiber/internal/health"
+	"backend-go-fiber/internal/i18n"
+	"backend-go-fiber/internal/metrics"
 	"backend-go-fiber/internal/middleware"
 	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/openapi"
+	"backend-go-fiber/internal/routes"
 	"backend-go-fiber/internal/services"
 	adminServices "backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/services/cors"
+	"backend-go-fiber/internal/services/csp"
 	"backend-go-fiber/internal/services/email"
+	"backend-go-fiber/internal/services/idempotency"
+	"backend-go-fiber/internal/services/ipaccess"
+	"backend-go-fiber/internal/services/ratelimit"
 	"backend-go-fiber/internal/services/storage"
 	"backend-go-fiber/internal/services/upload"
+	"backend-go-fiber/internal/shutdown"
+	"backend-go-fiber/internal/tlsconfig"
+	"backend-go-fiber/internal/tracing"
+	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -29,53 +53,67 @@ import (
 )
 
 func main() {
+	// "server config print [flags]" shows the effective configuration
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(args[2:])
+		return
+	}
+
+	// Configuration: defaults < config file < environment < flags
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "Usage: server [config print] [flags]")
+		config.Usage(os.Stderr)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
 	// Set timezone to UTC
 	time.Local = time.UTC
 
 	// Configure logging
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
-	// Set log level from environment (LOG_LEVEL: debug, info, warn, error)
-	switch os.Getenv("LOG_LEVEL") {
-	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	case "warn":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-	default:
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	}
-
 	// Use console writer in development for prettier output
-	if os.Getenv("NODE_ENV") != "production" {
+	if !cfg.IsProduction() {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
-	// Get config from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3001"
+	// Services log with log.Ctx(ctx); outside a request that falls back to the global logger
+	zerolog.DefaultContextLogger = &log.Logger
+
+	// Log level, JWT and error settings (reloadable, see registerReloadable)
+	configureGlobals(cfg)
+
+	// Tracing (none, stdout, file, otlp)
+	tracingConfig := tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		FilePath:    cfg.Tracing.File,
 	}
-	host := os.Getenv("HOST")
-	if host == "" {
-		host = "0.0.0.0"
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
 	}
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		// SQLite with WAL mode for better concurrency:
-		// - _journal_mode=WAL: Write-Ahead Logging (readers don't block writers)
-		// - _busy_timeout=5000: Wait 5s before "database is locked" error
-		// - _synchronous=NORMAL: Good balance of safety and performance
-		// - _cache_size=-262144: ~1GB cache (negative = KB, so 262144KB = 256MB)
-		//   Note: SQLite cache_size is in pages (4KB each) or negative for KB
-		// - _foreign_keys=ON: Enforce foreign key constraints
-		dbURL = "file:./data/db/sqlite/app.db?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL&_cache_size=-262144&_foreign_keys=ON"
+	log.Info().Str("exporter", tracingConfig.Exporter).Msg("Tracing configured")
+
+	// Message catalogs (i18n.dir adds or overrides the built-in ones)
+	i18nBundle, err := i18n.Load(cfg.I18n.Dir, cfg.I18n.DefaultLocale)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load message catalogs")
 	}
+	i18n.SetDefault(i18nBundle)
+	log.Info().Strs("locales", i18nBundle.Locales()).Str("default", i18nBundle.Fallback()).Msg("Message catalogs loaded")
+
+	dbURL := cfg.Database.URL
 
 	// Prefork mode: multiple processes for high-load (only with PostgreSQL!)
 	// SQLite doesn't support multi-process access
-	prefork := os.Getenv("PREFORK") == "true"
+	prefork := cfg.Server.Prefork
 	if prefork {
 		log.Info().Msg("Prefork mode enabled - spawning multiple processes")
 	}
@@ -86,7 +124,6 @@ func main() {
 
 	// Connect to database with appropriate driver
 	var db *gorm.DB
-	var err error
 
 	if isPostgres {
 		log.Info().Msg("Connecting to PostgreSQL database")
@@ -100,12 +137,24 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 
+	dbSystem := "sqlite"
+	if isPostgres {
+		dbSystem = "postgresql"
+	}
+	if err := db.Use(tracing.GormPlugin{DBSystem: dbSystem}); err != nil {
+		log.Fatal().Err(err).Msg("Failed to enable database tracing")
+	}
+
 	// Configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to get database connection")
 	}
 
+	if err := metrics.RegisterDB(sqlDB, "main"); err != nil {
+		log.Fatal().Err(err).Msg("Failed to register database metrics")
+	}
+
 	if isSQLite {
 		// SQLite optimizations:
 		// - Single connection for writes (SQLite limitation)
@@ -125,56 +174,202 @@ func main() {
 		sqlDB.SetConnMaxLifetime(time.Hour)
 	}
 
+	// Graceful shutdown: flip readiness, drain, stop workers, close the pool.
+	// Workers and resources are registered as they are created.
+	lifecycle := shutdown.New(shutdown.Config{
+		DrainDelay:    cfg.Shutdown.DrainDelay,
+		Timeout:       cfg.Shutdown.Timeout,
+		WorkerTimeout: cfg.Shutdown.WorkerTimeout,
+	})
+	lifecycle.Closer("database", sqlDB.Close)
+	lifecycle.Closer("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
+
+	// Hash tokens left in plaintext by older versions (must run before AutoMigrate)
+	if err := models.MigratePlaintextTokens(db, models.TokenModels()...); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate tokens")
+	}
+
 	// Auto-migrate
-	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.PasswordResetToken{}, &models.AppSettings{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.AppSettings{},
+		&models.Organization{},
+		&models.Membership{},
+		&models.OrganizationInvitation{},
+		&models.CSPReport{},
+		&models.AuditLog{},
+	); err != nil {
 		log.Fatal().Err(err).Msg("Failed to migrate database")
 	}
 
-	// Seed default settings if not exist
-	var settingsCount int64
-	db.Model(&models.AppSettings{}).Count(&settingsCount)
-	if settingsCount == 0 {
-		defaultSettings := models.DefaultSettings()
-		for _, setting := range defaultSettings {
-			db.Create(&setting)
+	// Seed default settings that don't exist yet (new settings are added on upgrade)
+	defaultSettings := append(models.DefaultSettings(), ratelimit.PoliciesSetting(), cors.PoliciesSetting(cfg.CORS.Origins), ipaccess.RulesSetting())
+	for _, setting := range defaultSettings {
+		result := db.Where("key = ?", setting.Key).FirstOrCreate(&setting)
+		if result.Error != nil {
+			log.Fatal().Err(result.Error).Str("key", setting.Key).Msg("Failed to seed settings")
+		}
+		if result.RowsAffected > 0 {
+			log.Info().Str("key", setting.Key).Msg("Default setting seeded")
 		}
-		log.Info().Msg("Default settings seeded")
 	}
 
+	// Settings cache: lets middleware read settings per request and reload them without restart
+	settingsCache, err := services.NewSettingsCache(db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load settings")
+	}
+	settingsCache.Watch(10 * time.Second)
+	lifecycle.Worker("settings", settingsCache.Close)
+
 	// Create Fiber app
 	fiberConfig := fiber.Config{
-		Prefork:      prefork, // Enable with PREFORK=true (requires PostgreSQL!)
+		Prefork:      prefork, // Enable with server.prefork (requires PostgreSQL!)
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  30 * time.Second,
 		BodyLimit:    10 * 1024 * 1024, // 10MB
-		ErrorHandler: errorHandler,
+		ErrorHandler: apperror.Handler,
 	}
 
 	// Trust reverse proxy headers (nginx, Cloudflare) for correct c.IP()
 	// TRUSTED_PROXIES=127.0.0.1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16
-	if trustedProxies := os.Getenv("TRUSTED_PROXIES"); trustedProxies != "" {
+	trustedProxies, err := utils.ParseIPList(strings.Join(cfg.Server.TrustedProxies, ","))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+	if len(trustedProxies) > 0 {
 		fiberConfig.EnableTrustedProxyCheck = true
-		fiberConfig.TrustedProxies = strings.Split(trustedProxies, ",")
+		for _, network := range trustedProxies {
+			fiberConfig.TrustedProxies = append(fiberConfig.TrustedProxies, network.String())
+		}
 		fiberConfig.ProxyHeader = fiber.HeaderXForwardedFor
 		log.Info().Strs("proxies", fiberConfig.TrustedProxies).Msg("Trusted proxies configured")
 	}
 
 	app := fiber.New(fiberConfig)
 
+	// Rate limiter storage (memory by default; sql or redis to share counters
+	// between prefork processes and instances)
+	limiterConfig := ratelimit.Config{
+		Backend:    cfg.RateLimit.Storage,
+		RedisURL:   cfg.RateLimit.RedisURL,
+		GCInterval: time.Minute,
+	}
+	limiterStorage, err := ratelimit.NewStorage(limiterConfig, db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize rate limit storage")
+	}
+	if limiterConfig.Backend == ratelimit.BackendMemory && prefork {
+		log.Warn().Msg("Rate limits are counted per process in prefork mode - set RATE_LIMIT_STORAGE=sql or redis")
+	}
+	lifecycle.Worker("rate limit storage", func() { limiterStorage.Close() })
+	log.Info().Str("backend", limiterConfig.Backend).Msg("Rate limit storage")
+
+	// Rate limit policies come from the rate_limit_policies setting and are hot-reloaded
+	rateLimiter := ratelimit.NewLimiter(limiterStorage, ratelimit.DefaultPolicies())
+	settingsCache.Subscribe(ratelimit.PoliciesSettingKey, func(value string) {
+		if err := rateLimiter.ApplySetting(value); err != nil {
+			log.Error().Err(err).Msg("Invalid rate limit policies, keeping previous")
+			return
+		}
+		log.Info().Int("policies", len(rateLimiter.Policies())).Msg("Rate limit policies loaded")
+	})
+
+	// CORS policies per route group come from the cors_policies setting and are hot-reloaded
+	corsPolicies := cors.NewPolicies(cors.DefaultPolicies(cfg.CORS.Origins))
+	settingsCache.Subscribe(cors.PoliciesSettingKey, func(value string) {
+		if err := corsPolicies.ApplySetting(value); err != nil {
+			log.Error().Err(err).Msg("Invalid CORS policies, keeping previous")
+			return
+		}
+		log.Info().Msg("CORS policies loaded")
+	})
+
+	// IP allow/deny rules per route come from the ip_access_rules setting and are hot-reloaded
+	ipRules := ipaccess.NewRules()
+	settingsCache.Subscribe(ipaccess.RulesSettingKey, func(value string) {
+		if err := ipRules.ApplySetting(value); err != nil {
+			log.Error().Err(err).Msg("Invalid IP access rules, keeping previous")
+			return
+		}
+		log.Info().Int("rules", ipRules.Len()).Msg("IP access rules loaded")
+	})
+
+	// CSP reports have their own limit: one page view can send a report per blocked resource
+	cspReportLimiter := ratelimit.NewLimiter(limiterStorage, []ratelimit.Policy{csp.ReportRateLimit()})
+
+	// Content-Security-Policy: environment defaults with the csp.* overrides, nonce per request
+	cspConfig, err := csp.NewConfig(cfg.IsProduction(), csp.Options{
+		Directives:      cfg.CSP.Directives,
+		NonceDirectives: cfg.CSP.NonceDirectives,
+		ReportOnly:      cfg.CSP.ReportOnly,
+		ReportURI:       cfg.CSP.ReportURI,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid Content-Security-Policy configuration")
+	}
+
+	// Idempotency-Key store: replays responses of retried mutating requests
+	idempotencyConfig := idempotency.Config{
+		Backend:    cfg.Idempotency.Store,
+		TTL:        cfg.Idempotency.TTL,
+		GCInterval: 10 * time.Minute,
+	}
+	idempotencyStore, err := idempotency.NewStore(idempotencyConfig, db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize idempotency store")
+	}
+	lifecycle.Worker("idempotency store", func() { idempotencyStore.Close() })
+	idempotent := middleware.Idempotency(idempotencyStore, idempotencyConfig.TTL, []byte(cfg.JWT.Secret))
+
+	// Native TLS (tls.cert_file): certificates are reloaded when the files
+	// change; client certificates are verified with tls.client_ca_file
+	var certStore *tlsconfig.Store
+	if cfg.TLS.Enabled() {
+		certs, err := tlsconfig.Load(tlsFiles(cfg))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load TLS certificates")
+		}
+		certStore = tlsconfig.NewStore(certs, clientAuth(cfg))
+		certStore.Watch(cfg.TLS.ReloadInterval)
+		lifecycle.Worker("tls", certStore.Close)
+		log.Info().
+			Time("not_after", certs.NotAfter()).
+			Bool("client_auth", cfg.TLS.ClientCAFile != "").
+			Msg("TLS enabled")
+	}
+
 	// Global middleware
 	app.Use(recover.New())
 	app.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed, // Fast compression for high-load
 	}))
 	app.Use(middleware.RequestIDMiddleware())
-	app.Use(middleware.HelmetMiddleware())
-	app.Use(middleware.CORSMiddleware())
-	app.Use(middleware.RateLimiterMiddleware())
-	app.Use(logger.New(logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${locals:requestId}\n",
-		TimeFormat: "2006-01-02 15:04:05",
+	app.Use(middleware.LocaleMiddleware())
+	app.Use(middleware.TracingMiddleware(trustedProxies))
+	app.Use(middleware.AccessLogMiddleware(middleware.AccessLogConfig{
+		Sample2xx:      uint32(cfg.AccessLog.Sample2xx),
+		LogHeaders:     cfg.AccessLog.Headers,
+		TrustedProxies: trustedProxies,
 	}))
+	app.Use(middleware.MetricsMiddleware())
+	app.Use(middleware.ErrorMiddleware())
+	app.Use(middleware.IPAccessMiddleware(ipRules, trustedProxies))
+	app.Use(middleware.AuditMiddleware(trustedProxies))
+	if certStore != nil {
+		app.Use(middleware.ClientCertMiddleware(certStore))
+	}
+	app.Use(middleware.HelmetMiddleware(cspConfig))
+	app.Use(middleware.CORSMiddleware(corsPolicies))
+	app.Use(middleware.MaintenanceMiddleware(settingsCache, db, trustedProxies))
+	app.Use(middleware.RateLimitMiddleware(rateLimiter, nil, trustedProxies))
 
 	// ==========================================================================
 	// Services
@@ -183,209 +378,236 @@ func main() {
 	// Auth service
 	authService := services.NewAuthService(db)
 
-	// Email service (use MockSender in development, SMTPSender in production)
-	var emailSender email.Sender
-	if os.Getenv("NODE_ENV") == "production" {
-		emailSender = email.NewSMTPSender(email.Config{
-			FromName:     os.Getenv("SMTP_FROM_NAME"),
-			FromAddress:  os.Getenv("SMTP_FROM_ADDRESS"),
-			SMTPHost:     os.Getenv("SMTP_HOST"),
-			SMTPPort:     587, // TLS port
-			SMTPUser:     os.Getenv("SMTP_USER"),
-			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
-			SMTPUseTLS:   true,
-		})
-		log.Info().Msg("Email service: SMTP")
-	} else {
-		emailSender = email.NewMockSender(email.Config{})
-		log.Info().Msg("Email service: Mock (emails logged to console)")
-	}
+	// Email service (use MockSender in development, SMTPSender in production).
+	// Swappable so that reloads can change SMTP credentials.
+	initialSender, provider := newEmailSender(cfg)
+	emailSender := email.NewSwappableSender(initialSender)
+	log.Info().Str("provider", provider).Msg("Email service")
 
 	// Password reset service
-	passwordResetService := services.NewPasswordResetService(db, emailSender)
-
-	// Storage service (local by default, S3 when configured)
-	var storageService storage.Storage
-	if os.Getenv("S3_BUCKET") != "" {
-		s3Storage, err := storage.NewS3Storage(
-			os.Getenv("S3_BUCKET"),
-			os.Getenv("S3_REGION"),
-			os.Getenv("S3_ENDPOINT"),
-			os.Getenv("S3_ACCESS_KEY"),
-			os.Getenv("S3_SECRET_KEY"),
-		)
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to initialize S3 storage")
-		}
-		storageService = s3Storage
-		log.Info().Str("bucket", os.Getenv("S3_BUCKET")).Msg("Storage service: S3")
-	} else {
-		localStorage, err := storage.NewLocalStorage("./data/uploads", "/uploads")
-		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to initialize local storage")
-		}
-		storageService = localStorage
-		log.Info().Msg("Storage service: Local (./data/uploads)")
+	passwordResetService := services.NewPasswordResetService(db, emailSender, cfg.FrontendURL)
+
+	// Storage service (local by default, S3 when configured), swappable like email
+	initialStorage, backend, err := newStorage(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize storage")
 	}
+	storageService := storage.NewSwappableStorage(initialStorage)
+	log.Info().Str("backend", backend).Msg("Storage service")
 
 	// Upload service
 	uploadService := upload.NewService(storageService, upload.DefaultConfig())
 
+	// Organization service (tenants, memberships, invitations)
+	orgService := services.NewOrganizationService(db, emailSender, cfg.FrontendURL)
+
+	// Shutdown waits for a running cleanup
+	lifecycle.Go("cleanup", func(ctx context.Context) {
+		cleanupExpired(ctx, orgService, passwordResetService)
+	})
+
+	// Audit log of admin mutations and security events
+	auditLog := audit.New(db)
+
 	// ==========================================================================
 	// Handlers
 	// ==========================================================================
-	authHandler := handlers.NewAuthHandler(authService)
-	healthHandler := handlers.NewHealthHandler(db)
-	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
+	authHandler := handlers.NewAuthHandler(authService, auditLog, handlers.CookieConfig{
+		Secure:   cfg.IsProduction(),
+		SameSite: cfg.Cookie.SameSite,
+	})
+	// Readiness checks reported by /ready
+	healthChecks := health.NewRegistry()
+	registerHealthChecks(healthChecks, cfg, sqlDB, storageService, emailSender, lifecycle)
+	healthHandler := handlers.NewHealthHandler(healthChecks)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService, auditLog)
 	uploadHandler := handlers.NewUploadHandler(uploadService)
+	orgHandler := handlers.NewOrganizationHandler(orgService, authService)
+	cspReportHandler := handlers.NewCSPReportHandler(csp.NewReportService(db))
 
 	// Health routes
 	app.Get("/health", healthHandler.Health)
 	app.Get("/ready", healthHandler.Ready)
 
-	// ==========================================================================
-	// API Routes
-	// ==========================================================================
-	// Current: /api/auth/*
-	// For versioning, change to: /api/v1/auth/*
-	//
-	// To enable versioning:
-	// 1. Change "/api" to "/api/v1" below
-	// 2. Update frontend API client base URL
-	// 3. Optionally keep "/api" as alias to latest version
-	// ==========================================================================
-	api := app.Group("/api")
-
-	// Uncomment for explicit versioning:
-	// v1 := app.Group("/api/v1")
-	// api := v1 // Use v1 as the main API group
-
-	// Auth routes: /api/auth/*
-	auth := api.Group("/auth")
-	auth.Post("/register", middleware.RegisterRateLimiter(), authHandler.Register)
-	auth.Post("/login", middleware.LoginRateLimiter(), authHandler.Login)
-	auth.Post("/refresh", authHandler.Refresh)
-	auth.Post("/logout", authHandler.Logout)
-	auth.Get("/me", middleware.AuthMiddleware(), authHandler.Me)
-	auth.Put("/profile", middleware.AuthMiddleware(), authHandler.UpdateProfile)
-	auth.Put("/change-password", middleware.AuthMiddleware(), authHandler.ChangePassword)
-
-	// Password reset routes: /api/auth/*
-	auth.Post("/forgot-password", passwordResetHandler.ForgotPassword)
-	auth.Post("/validate-reset-token", passwordResetHandler.ValidateToken)
-	auth.Post("/reset-password", passwordResetHandler.ResetPassword)
-
-	// Upload routes: /api/upload/*
-	uploads := api.Group("/upload")
-	uploads.Post("/", middleware.AuthMiddleware(), uploadHandler.UploadSingle)
-	uploads.Post("/multiple", middleware.AuthMiddleware(), uploadHandler.UploadMultiple)
-	uploads.Delete("/*", middleware.AuthMiddleware(), uploadHandler.Delete)
-
-	// Serve uploaded files (local storage only)
-	app.Static("/uploads", "./data/uploads")
+	// Prometheus metrics: metrics.token (Bearer) or metrics.allowed_ips (default: localhost only)
+	metricsAllowed, err := utils.ParseIPList(strings.Join(cfg.Metrics.AllowedIPs, ","))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid metrics allowed IPs")
+	}
+	app.Get("/metrics", middleware.MetricsAccess(cfg.Metrics.Token, metricsAllowed, trustedProxies), metrics.Handler())
 
-	// ==========================================================================
-	// Admin Routes: /api/admin/*
-	// ==========================================================================
 	// Admin services
-	dashboardService := adminServices.NewDashboardService(db)
+	auditService := adminServices.NewAuditService(db)
+	dashboardService := adminServices.NewDashboardService(db, auditService)
 	usersService := adminServices.NewUsersService(db)
 	settingsService := adminServices.NewSettingsService(db)
+	settingsService.RegisterValidator(ratelimit.PoliciesSettingKey, func(value string) error {
+		policies, err := ratelimit.ParsePolicies(value)
+		if err != nil {
+			return err
+		}
+		// There are no API keys to resolve yet (RateLimitMiddleware gets a nil
+		// resolver), so api_key policies would silently count by IP
+		return ratelimit.RejectKeyBy(policies, ratelimit.KeyByAPIKey)
+	})
+	settingsService.RegisterValidator(cors.PoliciesSettingKey, func(value string) error {
+		_, err := cors.ParsePolicies(value)
+		return err
+	})
+	settingsService.RegisterValidator(ipaccess.RulesSettingKey, func(value string) error {
+		_, err := ipaccess.ParseRules(value)
+		return err
+	})
+	settingsService.RegisterValidator(middleware.SettingMaintenanceAllowedIPs, func(value string) error {
+		_, err := utils.ParseIPList(value)
+		return err
+	})
+	settingsService.RegisterValidator(middleware.SettingMaintenanceRetryAfter, func(value string) error {
+		if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+			return errors.New("must be a non-negative number of seconds")
+		}
+		return nil
+	})
+	settingsService.OnChange(func() {
+		if err := settingsCache.Reload(); err != nil {
+			log.Error().Err(err).Msg("Failed to reload settings")
+		}
+	})
+	organizationsService := adminServices.NewOrganizationsService(db)
+	cspReportsService := adminServices.NewCSPReportsService(db)
 
 	// Admin handlers
 	dashboardHandler := adminHandlers.NewDashboardHandler(dashboardService)
-	usersHandler := adminHandlers.NewUsersHandler(usersService)
-	filesHandler := adminHandlers.NewFilesHandler("./data/uploads")
-	settingsHandler := adminHandlers.NewSettingsHandler(settingsService)
-
-	// Admin routes group with auth + admin middleware
-	adminGroup := api.Group("/admin", middleware.AuthMiddleware(), middleware.AdminOnly(db))
-
-	// Dashboard
-	adminGroup.Get("/dashboard", dashboardHandler.GetStats)
+	usersHandler := adminHandlers.NewUsersHandler(usersService, auditLog)
+	filesHandler := adminHandlers.NewFilesHandler(uploadsDir, auditLog)
+	settingsHandler := adminHandlers.NewSettingsHandler(settingsService, auditLog)
+	organizationsHandler := adminHandlers.NewOrganizationsHandler(organizationsService, auditLog)
+	cspReportsHandler := adminHandlers.NewCSPReportsHandler(cspReportsService, auditLog)
+	auditHandler := adminHandlers.NewAuditHandler(auditService)
+
+	// Configuration reload: SIGHUP or POST /api/admin/config/reload
+	reloader := config.NewReloader(cfg, args)
+	registerReloadable(reloader, emailSender, storageService, certStore, settingsCache)
+	configHandler := adminHandlers.NewConfigHandler(reloader, auditLog)
 
-	// Users CRUD
-	adminGroup.Get("/users", usersHandler.List)
-	adminGroup.Get("/users/:id", usersHandler.Get)
-	adminGroup.Post("/users", usersHandler.Create)
-	adminGroup.Put("/users/:id", usersHandler.Update)
-	adminGroup.Delete("/users/:id", usersHandler.Delete)
-
-	// Files
-	adminGroup.Get("/files", filesHandler.List)
-	adminGroup.Delete("/files/*", filesHandler.Delete)
-
-	// Settings
-	adminGroup.Get("/settings", settingsHandler.GetAll)
-	adminGroup.Get("/settings/:key", settingsHandler.Get)
-	adminGroup.Put("/settings/:key", settingsHandler.Update)
-	adminGroup.Put("/settings", settingsHandler.UpdateBatch)
+	// Serve uploaded files (local storage only)
+	app.Static("/uploads", uploadsDir)
 
 	// ==========================================================================
-	// Add your routes here
+	// API Routes
 	// ==========================================================================
-	// Example:
-	// users := api.Group("/users")
-	// users.Get("/", middleware.AuthMiddleware(), userHandler.List)
-	// users.Get("/:id", middleware.AuthMiddleware(), userHandler.Get)
+	// Routes are mounted under /api (latest version, or the one requested with
+	// the API-Version header) and under /api/<version> for every version.
+	// Versions are listed in routes.Versions, routes in routes.RegisterAPI.
 	// ==========================================================================
+	apiVersions, err := routes.Versions()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid API versions")
+	}
+	app.Use(apiversion.Prefix, apiVersions.Middleware())
+
+	routes.Mount(app, apiVersions, routes.Handlers{
+		DB:             db,
+		ActiveAccount:  middleware.ActiveAccount(db),
+		Idempotent:     idempotent,
+		CSPReportLimit: middleware.RateLimitMiddleware(cspReportLimiter, nil, trustedProxies),
+		Auth:           authHandler,
+		PasswordReset:  passwordResetHandler,
+		Upload:         uploadHandler,
+		Organization:   orgHandler,
+		CSPReport:      cspReportHandler,
+		Dashboard:      dashboardHandler,
+		Users:          usersHandler,
+		Files:          filesHandler,
+		Settings:       settingsHandler,
+		Organizations:  organizationsHandler,
+		CSPReports:     cspReportsHandler,
+		Config:         configHandler,
+		Audit:          auditHandler,
+	})
+
+	// OpenAPI document and interactive docs (api/openapi.json is generated
+	// from the same routes by cmd/openapi)
+	apiDoc, problems := routes.Spec(app, apiVersions)
+	for _, problem := range problems {
+		log.Warn().Str("problem", problem).Msg("OpenAPI document is incomplete")
+	}
+	specHandler, err := openapi.SpecHandler(apiDoc)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to build OpenAPI document")
+	}
+	app.Get("/api/openapi.json", specHandler)
+	app.Get("/api/docs/*", openapi.UIHandler("/api/docs", "/api/openapi.json"))
 
 	// 404 handler
 	app.Use(func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"code":    "NOT_FOUND",
-				"message": "Resource not found",
-			},
-			"meta": fiber.Map{
-				"timestamp": time.Now().UTC().Format(time.RFC3339),
-				"requestId": c.Locals("requestId"),
-			},
-		})
+		return fiber.NewError(fiber.StatusNotFound, "Resource not found")
 	})
 
-	// Graceful shutdown
-	c := make(chan os.Signal, 1)
+	// Graceful shutdown on SIGTERM/SIGINT; a second signal exits immediately
+	c := make(chan os.Signal, 2)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		<-c
 		log.Info().Msg("Shutting down gracefully...")
-		app.Shutdown()
+		go lifecycle.Shutdown(app)
+		<-c
+		log.Warn().Msg("Second signal, exiting without waiting")
+		os.Exit(1)
+	}()
+
+	// Reload configuration on SIGHUP; connections stay open
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for range hup {
+			result, err := reloader.Reload()
+			if err != nil {
+				log.Error().Err(err).Msg("Invalid configuration, keeping the current one")
+				continue
+			}
+			log.Info().
+				Strs("applied", result.Applied).
+				Strs("restart_required", result.RestartRequired).
+				Msg("Configuration reloaded")
+		}
 	}()
 
 	// Start server
-	addr := host + ":" + port
-	log.Info().Str("addr", addr).Msg("Server starting")
+	addr := cfg.Addr()
+	log.Info().Str("addr", addr).Bool("tls", certStore != nil).Msg("Server starting")
 
-	if err := app.Listen(addr); err != nil {
+	if err := listen(app, addr, certStore); err != nil {
 		log.Fatal().Err(err).Msg("Server failed to start")
 	}
-}
 
-func errorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	message := "Internal server error"
+	// Listen returns as soon as the listener closes; wait for the rest of the shutdown
+	<-lifecycle.Done()
+}
 
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
-		message = e.Message
+// listen serves plain HTTP, or HTTPS with the certificates of certs
+func listen(app *fiber.App, addr string, certs *tlsconfig.Store) error {
+	if certs == nil {
+		return app.Listen(addr)
 	}
-
-	if os.Getenv("NODE_ENV") == "development" {
-		message = err.Error()
+	ln, err := net.Listen(app.Config().Network, addr)
+	if err != nil {
+		return err
 	}
+	return app.Listener(tls.NewListener(ln, certs.ServerConfig()))
+}
 
-	return c.Status(code).JSON(fiber.Map{
-		"success": false,
-		"error": fiber.Map{
-			"code":    "INTERNAL_ERROR",
-			"message": message,
-		},
-		"meta": fiber.Map{
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-			"requestId": c.Locals("requestId"),
-		},
-	})
+// printConfig writes the effective configuration with secrets redacted
+func printConfig(args []string) {
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,212 @@
+// Package apiversion selects the API version of a request and lets routes
+// have per-version implementations.
+//
+// The same routes are mounted under /api (latest version, or the one asked
+// for in the API-Version header) and under /api/<version>. Deprecated
+// versions and routes answer with Deprecation, Sunset and Link headers and
+// are counted in metrics.DeprecatedRequests.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/metrics"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header selects the version for requests to the unversioned /api prefix
+const Header = "API-Version"
+
+// Prefix is where the API is mounted
+const Prefix = "/api"
+
+// versionedPath matches /api/v<N> prefixes
+var versionedPath = regexp.MustCompile(`^/api/(v[0-9]+)(/|$)`)
+
+// Deprecation describes when a version or route stops being supported
+type Deprecation struct {
+	// Since is when it was deprecated (Deprecation header)
+	Since time.Time
+
+	// Sunset is when it will be removed (Sunset header, optional)
+	Sunset time.Time
+
+	// Link documents the migration (Link rel="deprecation", optional)
+	Link string
+}
+
+// Version is an API version
+type Version struct {
+	// Name is the path segment, e.g. "v1"
+	Name string
+
+	// Deprecated is set once clients should migrate away from the version
+	Deprecated *Deprecation
+}
+
+// Registry holds the supported versions, oldest first
+type Registry struct {
+	versions []Version
+	index    map[string]int
+}
+
+// NewRegistry creates a registry of versions, oldest first.
+// The last version is the default for unversioned requests.
+func NewRegistry(versions ...Version) (*Registry, error) {
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("at least one API version is required")
+	}
+
+	r := &Registry{index: make(map[string]int, len(versions))}
+	for i, v := range versions {
+		if !versionedPath.MatchString(Prefix + "/" + v.Name) {
+			return nil, fmt.Errorf("invalid API version name: %q", v.Name)
+		}
+		if _, ok := r.index[v.Name]; ok {
+			return nil, fmt.Errorf("duplicate API version: %s", v.Name)
+		}
+		r.index[v.Name] = i
+		r.versions = append(r.versions, v)
+	}
+	return r, nil
+}
+
+// Versions returns the supported versions, oldest first
+func (r *Registry) Versions() []Version {
+	return r.versions
+}
+
+// Latest returns the newest version
+func (r *Registry) Latest() Version {
+	return r.versions[len(r.versions)-1]
+}
+
+// Lookup finds a version by name ("v1", also "1")
+func (r *Registry) Lookup(name string) (Version, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "v") {
+		name = "v" + name
+	}
+	i, ok := r.index[name]
+	if !ok {
+		return Version{}, false
+	}
+	return r.versions[i], true
+}
+
+// Middleware resolves the version of every /api request: the path segment
+// of /api/<version>/... wins over the API-Version header, which wins over
+// the latest version. The version is stored in c.Locals("apiVersion") and
+// echoed in the API-Version response header.
+func (r *Registry) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var version Version
+		if match := versionedPath.FindStringSubmatch(c.Path()); match != nil {
+			v, ok := r.Lookup(match[1])
+			if !ok {
+				return utils.SendError(c, "NOT_FOUND", "Unknown API version "+match[1], fiber.StatusNotFound)
+			}
+			version = v
+		} else if requested := c.Get(Header); requested != "" {
+			v, ok := r.Lookup(requested)
+			if !ok {
+				return utils.SendError(c, "UNSUPPORTED_API_VERSION", "Unsupported API version "+requested, fiber.StatusBadRequest)
+			}
+			version = v
+		} else {
+			version = r.Latest()
+		}
+
+		c.Locals("apiVersion", version.Name)
+		c.Set(Header, version.Name)
+		c.Append(fiber.HeaderVary, Header)
+
+		if version.Deprecated == nil {
+			return c.Next()
+		}
+
+		setDeprecationHeaders(c, version.Deprecated)
+		err := c.Next()
+		metrics.DeprecatedRequests.WithLabelValues(version.Name, c.Route().Path).Inc()
+		return err
+	}
+}
+
+// FromContext returns the version resolved by Middleware
+func FromContext(c *fiber.Ctx) string {
+	version, _ := c.Locals("apiVersion").(string)
+	return version
+}
+
+// Handlers maps version names to implementations of one route
+type Handlers map[string]fiber.Handler
+
+// Switch dispatches to the implementation for the request's version.
+// A version without its own implementation uses the one of the newest older
+// version, so a route only needs a new implementation when it changes.
+func (r *Registry) Switch(handlers Handlers) fiber.Handler {
+	for name := range handlers {
+		if _, ok := r.index[name]; !ok {
+			panic("apiversion: handler for unknown version " + name)
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		i, ok := r.index[FromContext(c)]
+		if !ok {
+			i = len(r.versions) - 1
+		}
+		for ; i >= 0; i-- {
+			if handler, ok := handlers[r.versions[i].Name]; ok {
+				return handler(c)
+			}
+		}
+		return utils.SendError(c, "NOT_FOUND", "Resource not found in API version "+FromContext(c), fiber.StatusNotFound)
+	}
+}
+
+// Deprecated marks a single route as deprecated in every version
+func Deprecated(d Deprecation) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		setDeprecationHeaders(c, &d)
+		err := c.Next()
+		metrics.DeprecatedRequests.WithLabelValues(FromContext(c), c.Route().Path).Inc()
+		return err
+	}
+}
+
+// CanonicalPath strips the version segment (/api/v1/auth/login -> /api/auth/login),
+// so path-based rules (rate limit policies, maintenance allowlist) apply to all versions.
+// It also lower-cases the path: routing is case-insensitive, so /API/Admin/users
+// reaches the same handler as /api/admin/users and must match the same rules.
+func CanonicalPath(path string) string {
+	path = strings.ToLower(path)
+	match := versionedPath.FindStringSubmatchIndex(path)
+	if match == nil {
+		return path
+	}
+	// match[3] is the end of the version segment
+	return Prefix + path[match[3]:]
+}
+
+// setDeprecationHeaders sets Deprecation (RFC 9745), Sunset (RFC 8594) and Link
+func setDeprecationHeaders(c *fiber.Ctx, d *Deprecation) {
+	if d.Since.IsZero() {
+		c.Set("Deprecation", "true")
+	} else {
+		c.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		c.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.Append(fiber.HeaderLink, "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
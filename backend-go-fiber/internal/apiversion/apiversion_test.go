@@ -0,0 +1,154 @@
+package apiversion
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func setupApp(t *testing.T) *fiber.App {
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry, err := NewRegistry(
+		Version{Name: "v1", Deprecated: &Deprecation{Since: time.Unix(1700000000, 0), Sunset: sunset, Link: "https://example.com/migrate"}},
+		Version{Name: "v2"},
+		Version{Name: "v3"},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(Prefix, registry.Middleware())
+
+	routes := func(api fiber.Router) {
+		api.Get("/items", registry.Switch(Handlers{
+			"v1": func(c *fiber.Ctx) error { return c.SendString("items v1") },
+			"v2": func(c *fiber.Ctx) error { return c.SendString("items v2") },
+		}))
+		api.Get("/legacy", Deprecated(Deprecation{Since: time.Unix(1700000000, 0)}), func(c *fiber.Ctx) error {
+			return c.SendString("legacy")
+		})
+	}
+	routes(app.Group(Prefix))
+	for _, v := range registry.Versions() {
+		routes(app.Group(Prefix + "/" + v.Name))
+	}
+	return app
+}
+
+func get(t *testing.T, app *fiber.App, path, version string) (int, string, map[string]string) {
+	req := httptest.NewRequest("GET", path, nil)
+	if version != "" {
+		req.Header.Set(Header, version)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	headers := map[string]string{}
+	for _, name := range []string{Header, "Deprecation", "Sunset", "Link"} {
+		headers[name] = resp.Header.Get(name)
+	}
+	return resp.StatusCode, string(body), headers
+}
+
+func TestVersionSelection(t *testing.T) {
+	app := setupApp(t)
+
+	tests := []struct {
+		path     string
+		header   string
+		status   int
+		body     string
+		resolved string
+	}{
+		{"/api/items", "", 200, "items v2", "v3"}, // latest, falls back to v2 implementation
+		{"/api/items", "1", 200, "items v1", "v1"},
+		{"/api/items", "v2", 200, "items v2", "v2"},
+		{"/api/v1/items", "", 200, "items v1", "v1"},
+		{"/api/v2/items", "v1", 200, "items v2", "v2"}, // path wins over header
+		{"/api/items", "v9", 400, "", ""},
+		{"/api/v9/items", "", 404, "", ""},
+	}
+	for _, tt := range tests {
+		status, body, headers := get(t, app, tt.path, tt.header)
+		if status != tt.status {
+			t.Errorf("%s (%s): expected status %d, got %d", tt.path, tt.header, tt.status, status)
+			continue
+		}
+		if tt.body != "" && body != tt.body {
+			t.Errorf("%s (%s): expected %q, got %q", tt.path, tt.header, tt.body, body)
+		}
+		if tt.resolved != "" && headers[Header] != tt.resolved {
+			t.Errorf("%s (%s): expected API-Version %s, got %s", tt.path, tt.header, tt.resolved, headers[Header])
+		}
+	}
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	app := setupApp(t)
+
+	before := testutil.ToFloat64(metrics.DeprecatedRequests.WithLabelValues("v1", "/api/v1/items"))
+	_, _, headers := get(t, app, "/api/v1/items", "")
+	if headers["Deprecation"] != "@1700000000" {
+		t.Errorf("Expected Deprecation @1700000000, got %q", headers["Deprecation"])
+	}
+	if headers["Sunset"] != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset %q", headers["Sunset"])
+	}
+	if headers["Link"] != `<https://example.com/migrate>; rel="deprecation"` {
+		t.Errorf("Unexpected Link %q", headers["Link"])
+	}
+	if got := testutil.ToFloat64(metrics.DeprecatedRequests.WithLabelValues("v1", "/api/v1/items")); got != before+1 {
+		t.Errorf("Expected deprecated usage to be counted, got %v -> %v", before, got)
+	}
+
+	if _, _, headers := get(t, app, "/api/v2/items", ""); headers["Deprecation"] != "" {
+		t.Errorf("Expected no Deprecation header for v2, got %q", headers["Deprecation"])
+	}
+
+	// Deprecated route in a current version
+	if _, _, headers := get(t, app, "/api/v3/legacy", ""); headers["Deprecation"] == "" {
+		t.Error("Expected Deprecation header on deprecated route")
+	}
+	if got := testutil.ToFloat64(metrics.DeprecatedRequests.WithLabelValues("v3", "/api/v3/legacy")); got != 1 {
+		t.Errorf("Expected deprecated route usage to be counted, got %v", got)
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/auth/login": "/api/auth/login",
+		"/API/V1/Auth/Login": "/api/auth/login",
+		"/API/Admin/users":   "/api/admin/users",
+		"/api/v12/users":     "/api/users",
+		"/api/v1":            "/api",
+		"/api/auth/login":    "/api/auth/login",
+		"/api/version":       "/api/version",
+		"/health":            "/health",
+	}
+	for path, expected := range tests {
+		if got := CanonicalPath(path); got != expected {
+			t.Errorf("CanonicalPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestNewRegistryValidation(t *testing.T) {
+	if _, err := NewRegistry(); err == nil {
+		t.Error("Expected error for no versions")
+	}
+	if _, err := NewRegistry(Version{Name: "beta"}); err == nil {
+		t.Error("Expected error for invalid name")
+	}
+	if _, err := NewRegistry(Version{Name: "v1"}, Version{Name: "v1"}); err == nil {
+		t.Error("Expected error for duplicate version")
+	}
+}
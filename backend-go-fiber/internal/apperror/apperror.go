@@ -0,0 +1,218 @@
+// Package apperror defines typed domain errors.
+//
+// Services return *Error values (usually package-level sentinels) instead of
+// ad-hoc errors.New strings. Each carries a machine-readable code, an HTTP
+// status and a message that is safe to show to clients; the underlying cause
+// is kept for logs only. Handlers return these errors unchanged and Handler,
+// the app's fiber ErrorHandler, turns them into the standard error envelope,
+// so new error cases need no handler changes.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Codes shared by many errors
+const (
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeForbidden          = "FORBIDDEN"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodePreconditionFailed = "PRECONDITION_FAILED"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+// Error is a domain error
+type Error struct {
+	// Code identifies the error for clients, e.g. "USER_EXISTS"
+	Code string
+
+	// Status is the HTTP status of the response
+	Status int
+
+	// Message is shown to clients
+	Message string
+
+	// Details lists invalid fields of validation errors
+	Details []utils.FieldError
+
+	// Err is the cause; it is logged but never sent to clients
+	Err error
+
+	// sentinel is the error this one was derived from with Wrap or WithMessage
+	sentinel *Error
+}
+
+// New creates an error
+func New(status int, code, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// BadRequest creates a 400 error
+func BadRequest(code, message string) *Error {
+	return New(fiber.StatusBadRequest, code, message)
+}
+
+// Unauthorized creates a 401 error
+func Unauthorized(code, message string) *Error {
+	return New(fiber.StatusUnauthorized, code, message)
+}
+
+// Forbidden creates a 403 error
+func Forbidden(code, message string) *Error {
+	return New(fiber.StatusForbidden, code, message)
+}
+
+// NotFound creates a 404 error
+func NotFound(code, message string) *Error {
+	return New(fiber.StatusNotFound, code, message)
+}
+
+// Conflict creates a 409 error
+func Conflict(code, message string) *Error {
+	return New(fiber.StatusConflict, code, message)
+}
+
+// PreconditionFailed creates a 412 error
+func PreconditionFailed(message string) *Error {
+	return New(fiber.StatusPreconditionFailed, CodePreconditionFailed, message)
+}
+
+// Validation creates a 400 VALIDATION_ERROR listing the invalid fields
+func Validation(details ...utils.FieldError) *Error {
+	e := BadRequest(CodeValidation, "Validation failed")
+	e.Details = details
+	return e
+}
+
+// Internal creates a 500 INTERNAL_ERROR with a public message and a cause
+func Internal(message string, cause error) *Error {
+	e := New(fiber.StatusInternalServerError, CodeInternal, message)
+	e.Err = cause
+	return e
+}
+
+// OrInternal returns err unchanged if it carries an *Error, otherwise an
+// INTERNAL_ERROR with the given public message and err as the cause.
+// Handlers use it to keep their fallback message for unexpected errors.
+func OrInternal(err error, message string) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return Internal(message, err)
+}
+
+// Error returns the cause if there is one (that is what gets logged),
+// otherwise the public message
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether e was derived from target, so errors.Is(err, ErrX)
+// matches ErrX.Wrap(...) and ErrX.WithMessage(...)
+func (e *Error) Is(target error) bool {
+	return e.sentinel != nil && target == error(e.sentinel)
+}
+
+// Wrap returns a copy of e with cause as the underlying error
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.Err = cause
+	return c
+}
+
+// WithMessage returns a copy of e with a different public message
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	if c.sentinel == nil {
+		c.sentinel = e
+	}
+	return &c
+}
+
+// From translates any error into an *Error: domain errors are returned as
+// they are, fiber errors keep their status, missing records become 404 and
+// everything else is an INTERNAL_ERROR that hides the cause.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, StatusCode(fiberErr.Code), fiberErr.Message)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound(CodeNotFound, "Resource not found").Wrap(err)
+	}
+
+	return Internal("Internal server error", err)
+}
+
+// StatusCode derives an error code from an HTTP status,
+// e.g. 413 -> "REQUEST_ENTITY_TOO_LARGE"
+func StatusCode(status int) string {
+	switch status {
+	case fiber.StatusInternalServerError:
+		return CodeInternal
+	case fiber.StatusTooManyRequests:
+		return "RATE_LIMIT_EXCEEDED"
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return CodeInternal
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+var exposeCauses atomic.Bool
+
+// ExposeCauses makes 5xx responses show the cause; enabled in development
+func ExposeCauses(expose bool) {
+	exposeCauses.Store(expose)
+}
+
+// Handler is the app's ErrorHandler: it writes err as an error response.
+// The access log records the cause. In development, 5xx responses show it too.
+func Handler(c *fiber.Ctx, err error) error {
+	e := From(err)
+
+	if e.Status >= fiber.StatusInternalServerError && c.Locals("error") == nil {
+		// Not passed through ErrorMiddleware (a panic or an error of an outer
+		// middleware), so the access log doesn't have the cause
+		utils.Logger(c).Error().Err(err).Msg("Unhandled error")
+	}
+
+	message := e.Message
+	if e.Status >= fiber.StatusInternalServerError && exposeCauses.Load() {
+		message = err.Error()
+	}
+
+	return utils.SendError(c, e.Code, message, e.Status, e.Details)
+}
@@ -0,0 +1,123 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errThingNotFound = NotFound("THING_NOT_FOUND", "Thing not found")
+
+func TestSentinelMatching(t *testing.T) {
+	cause := errors.New("connection reset")
+	wrapped := errThingNotFound.Wrap(cause)
+
+	if !errors.Is(wrapped, errThingNotFound) {
+		t.Error("Wrapped error should match its sentinel")
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("Wrapped error should match its cause")
+	}
+	if wrapped.Error() != "connection reset" {
+		t.Errorf("Expected the cause as error string, got %q", wrapped.Error())
+	}
+	if errThingNotFound.Err != nil {
+		t.Error("Wrap must not modify the sentinel")
+	}
+
+	renamed := errThingNotFound.WithMessage("Widget not found")
+	if !errors.Is(fmt.Errorf("lookup: %w", renamed), errThingNotFound) {
+		t.Error("Error with a new message should still match its sentinel")
+	}
+	if errors.Is(NotFound("THING_NOT_FOUND", "Thing not found"), errThingNotFound) {
+		t.Error("Distinct errors with the same code must not match")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"domain error", fmt.Errorf("wrapped: %w", errThingNotFound), fiber.StatusNotFound, "THING_NOT_FOUND", "Thing not found"},
+		{"fiber error", fiber.NewError(fiber.StatusRequestEntityTooLarge, "Body too large"), fiber.StatusRequestEntityTooLarge, "REQUEST_ENTITY_TOO_LARGE", "Body too large"},
+		{"method not allowed", fiber.ErrMethodNotAllowed, fiber.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method Not Allowed"},
+		{"record not found", gorm.ErrRecordNotFound, fiber.StatusNotFound, CodeNotFound, "Resource not found"},
+		{"unknown error", errors.New("pq: syntax error"), fiber.StatusInternalServerError, CodeInternal, "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			if e.Status != tt.status || e.Code != tt.code || e.Message != tt.message {
+				t.Errorf("Expected %d %s %q, got %d %s %q", tt.status, tt.code, tt.message, e.Status, e.Code, e.Message)
+			}
+		})
+	}
+}
+
+func TestOrInternal(t *testing.T) {
+	if err := OrInternal(errThingNotFound, "Failed"); err != errThingNotFound {
+		t.Errorf("Domain errors should pass through, got %v", err)
+	}
+
+	cause := errors.New("disk full")
+	e := From(OrInternal(cause, "Failed to save thing"))
+	if e.Code != CodeInternal || e.Message != "Failed to save thing" || !errors.Is(e, cause) {
+		t.Errorf("Expected INTERNAL_ERROR with fallback message and cause, got %+v", e)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: Handler})
+	app.Get("/thing", func(c *fiber.Ctx) error {
+		return errThingNotFound
+	})
+	app.Get("/invalid", func(c *fiber.Ctx) error {
+		return Validation(utils.FieldError{Field: "name", Message: "name is required"})
+	})
+	app.Get("/broken", func(c *fiber.Ctx) error {
+		return OrInternal(errors.New("secret connection string"), "Failed to load thing")
+	})
+
+	tests := []struct {
+		path    string
+		status  int
+		code    string
+		message string
+		details int
+	}{
+		{"/thing", fiber.StatusNotFound, "THING_NOT_FOUND", "Thing not found", 0},
+		{"/invalid", fiber.StatusBadRequest, CodeValidation, "Validation failed", 1},
+		{"/broken", fiber.StatusInternalServerError, CodeInternal, "Failed to load thing", 0},
+		{"/missing", fiber.StatusNotFound, "NOT_FOUND", "Cannot GET /missing", 0},
+	}
+
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		var body utils.APIResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", tt.path, err)
+		}
+		if resp.StatusCode != tt.status || body.Success || body.Error == nil {
+			t.Fatalf("%s: expected error %d, got %d %+v", tt.path, tt.status, resp.StatusCode, body)
+		}
+		if body.Error.Code != tt.code || body.Error.Message != tt.message || len(body.Error.Details) != tt.details {
+			t.Errorf("%s: expected %s %q with %d details, got %+v", tt.path, tt.code, tt.message, tt.details, body.Error)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+// Package config holds the typed server configuration. Values come from, in
+// order of precedence: flags, environment variables (NAME or NAME_FILE),
+// an optional YAML or TOML file and the defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/utils"
+)
+
+// Environments
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// Config is the server configuration.
+//
+// Every leaf field has a yaml/toml key, a flag named after its dotted key
+// path (--server.port) and usually an environment variable. Fields tagged
+// secret:"true" are redacted by Print.
+type Config struct {
+	// Env is NODE_ENV: development, production or test
+	Env string `yaml:"env" toml:"env" env:"NODE_ENV"`
+
+	// FrontendURL is the base of links sent by email
+	FrontendURL string `yaml:"frontend_url" toml:"frontend_url" env:"FRONTEND_URL"`
+
+	// ProblemTypeBaseURL is the base of RFC 9457 problem type URIs, about:blank if empty
+	ProblemTypeBaseURL string `yaml:"problem_type_base_url" toml:"problem_type_base_url" env:"PROBLEM_TYPE_BASE_URL"`
+
+	Log         Log         `yaml:"log" toml:"log"`
+	Server      Server      `yaml:"server" toml:"server"`
+	TLS         TLS         `yaml:"tls" toml:"tls"`
+	Shutdown    Shutdown    `yaml:"shutdown" toml:"shutdown"`
+	Database    Database    `yaml:"database" toml:"database"`
+	JWT         JWT         `yaml:"jwt" toml:"jwt"`
+	Cookie      Cookie      `yaml:"cookie" toml:"cookie"`
+	SMTP        SMTP        `yaml:"smtp" toml:"smtp"`
+	S3          S3          `yaml:"s3" toml:"s3"`
+	CORS        CORS        `yaml:"cors" toml:"cors"`
+	CSP         CSP         `yaml:"csp" toml:"csp"`
+	RateLimit   RateLimit   `yaml:"rate_limit" toml:"rate_limit"`
+	Idempotency Idempotency `yaml:"idempotency" toml:"idempotency"`
+	I18n        I18n        `yaml:"i18n" toml:"i18n"`
+	Metrics     Metrics     `yaml:"metrics" toml:"metrics"`
+	Health      Health      `yaml:"health" toml:"health"`
+	Tracing     Tracing     `yaml:"tracing" toml:"tracing"`
+	AccessLog   AccessLog   `yaml:"access_log" toml:"access_log"`
+}
+
+// Log configures the global logger
+type Log struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+}
+
+// Server configures the HTTP listener
+type Server struct {
+	Host string `yaml:"host" toml:"host" env:"HOST"`
+	Port int    `yaml:"port" toml:"port" env:"PORT"`
+
+	// Prefork spawns a process per CPU (PostgreSQL only)
+	Prefork bool `yaml:"prefork" toml:"prefork" env:"PREFORK"`
+
+	// TrustedProxies are the IPs and CIDR ranges whose X-Forwarded-For is used
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// TLS serves HTTPS from PEM files that are reloaded when they change
+type TLS struct {
+	// CertFile and KeyFile enable TLS (both or neither)
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"TLS_KEY_FILE"`
+
+	// ReloadInterval is how often the files are checked for changes
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval" env:"TLS_RELOAD_INTERVAL"`
+
+	// ClientCAFile enables client certificates (mTLS), verified against its CAs
+	ClientCAFile string `yaml:"client_ca_file" toml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+
+	// ClientAuthRoutes require a client certificate ("/api/admin/*")
+	ClientAuthRoutes []string `yaml:"client_auth_routes" toml:"client_auth_routes" env:"TLS_CLIENT_AUTH_ROUTES"`
+
+	// ClientIdentities maps certificate subjects or common names to identities
+	// (empty = the common name is the identity)
+	ClientIdentities map[string]string `yaml:"client_identities" toml:"client_identities" env:"TLS_CLIENT_IDENTITIES"`
+}
+
+// Enabled reports whether the server listens with TLS
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Shutdown times the graceful shutdown on SIGTERM or SIGINT
+type Shutdown struct {
+	// DrainDelay is how long /ready reports not ready before connections are
+	// refused, so that load balancers stop routing to the instance first
+	DrainDelay time.Duration `yaml:"drain_delay" toml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+
+	// Timeout bounds waiting for in-flight requests
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+
+	// WorkerTimeout bounds waiting for background work after requests drained
+	WorkerTimeout time.Duration `yaml:"worker_timeout" toml:"worker_timeout" env:"SHUTDOWN_WORKER_TIMEOUT"`
+}
+
+// Database configures the connection
+type Database struct {
+	// URL is a postgres:// URL or a SQLite file: DSN
+	URL string `yaml:"url" toml:"url" env:"DATABASE_URL" secret:"true"`
+}
+
+// JWT configures access and refresh tokens
+type JWT struct {
+	Secret           string        `yaml:"secret" toml:"secret" env:"JWT_SECRET" secret:"true"`
+	ExpiresIn        time.Duration `yaml:"expires_in" toml:"expires_in" env:"JWT_EXPIRES_IN"`
+	RefreshTokenDays int           `yaml:"refresh_token_days" toml:"refresh_token_days" env:"REFRESH_TOKEN_EXPIRES_DAYS"`
+}
+
+// Cookie configures the refresh token cookie
+type Cookie struct {
+	// SameSite is Lax, Strict or None (embedded contexts like Telegram WebApp)
+	SameSite string `yaml:"same_site" toml:"same_site" env:"COOKIE_SAMESITE"`
+}
+
+// SMTP configures outgoing email in production
+type SMTP struct {
+	Host        string `yaml:"host" toml:"host" env:"SMTP_HOST"`
+	Port        int    `yaml:"port" toml:"port" env:"SMTP_PORT"`
+	User        string `yaml:"user" toml:"user" env:"SMTP_USER"`
+	Password    string `yaml:"password" toml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	UseTLS      bool   `yaml:"use_tls" toml:"use_tls" env:"SMTP_USE_TLS"`
+	FromName    string `yaml:"from_name" toml:"from_name" env:"SMTP_FROM_NAME"`
+	FromAddress string `yaml:"from_address" toml:"from_address" env:"SMTP_FROM_ADDRESS"`
+}
+
+// S3 configures object storage; local storage is used without a bucket
+type S3 struct {
+	Bucket    string `yaml:"bucket" toml:"bucket" env:"S3_BUCKET"`
+	Region    string `yaml:"region" toml:"region" env:"S3_REGION"`
+	Endpoint  string `yaml:"endpoint" toml:"endpoint" env:"S3_ENDPOINT"`
+	AccessKey string `yaml:"access_key" toml:"access_key" env:"S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" toml:"secret_key" env:"S3_SECRET_KEY" secret:"true"`
+}
+
+// CORS holds the origins seeding the cors_policies setting
+type CORS struct {
+	Origins []string `yaml:"origins" toml:"origins" env:"CORS_ORIGINS"`
+}
+
+// CSP configures the Content-Security-Policy, see csp.Options
+type CSP struct {
+	// Directives are merged over the environment defaults, [] removes one
+	Directives map[string][]string `yaml:"directives" toml:"directives" env:"CSP_DIRECTIVES"`
+
+	// NonceDirectives get the request nonce; unset keeps the environment default
+	NonceDirectives []string `yaml:"nonce_directives,omitempty" toml:"nonce_directives,omitempty" env:"CSP_NONCE_DIRECTIVES"`
+
+	ReportOnly bool `yaml:"report_only" toml:"report_only" env:"CSP_REPORT_ONLY"`
+
+	// ReportURI is the report endpoint, "off" disables reporting
+	ReportURI string `yaml:"report_uri" toml:"report_uri" env:"CSP_REPORT_URI"`
+}
+
+// RateLimit configures the rate limiter storage
+type RateLimit struct {
+	// Storage is memory, sql or redis
+	Storage  string `yaml:"storage" toml:"storage" env:"RATE_LIMIT_STORAGE"`
+	RedisURL string `yaml:"redis_url" toml:"redis_url" env:"REDIS_URL" secret:"true"`
+}
+
+// Idempotency configures the Idempotency-Key store
+type Idempotency struct {
+	// Store is sql or memory
+	Store string        `yaml:"store" toml:"store" env:"IDEMPOTENCY_STORE"`
+	TTL   time.Duration `yaml:"ttl" toml:"ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+// I18n configures the message catalogs
+type I18n struct {
+	// Dir holds catalogs adding to or overriding the built-in ones
+	Dir           string `yaml:"dir" toml:"dir" env:"I18N_DIR"`
+	DefaultLocale string `yaml:"default_locale" toml:"default_locale" env:"I18N_DEFAULT_LOCALE"`
+}
+
+// Metrics configures access to /metrics
+type Metrics struct {
+	Token      string   `yaml:"token" toml:"token" env:"METRICS_TOKEN" secret:"true"`
+	AllowedIPs []string `yaml:"allowed_ips" toml:"allowed_ips" env:"METRICS_ALLOWED_IPS"`
+}
+
+// Health configures the readiness checks of /ready
+type Health struct {
+	// DiskMinFreeMB is the free space required on the disks of the SQLite
+	// database and local uploads
+	DiskMinFreeMB int `yaml:"disk_min_free_mb" toml:"disk_min_free_mb" env:"HEALTH_DISK_MIN_FREE_MB"`
+}
+
+// Tracing configures span export. The OTLP exporter reads its endpoint and
+// headers from the standard OTEL_EXPORTER_OTLP_* variables.
+type Tracing struct {
+	// Exporter is none, stdout, file or otlp. Unset means otlp when an
+	// OTEL_EXPORTER_OTLP_*ENDPOINT variable is set, none otherwise.
+	Exporter    string `yaml:"exporter" toml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+	File        string `yaml:"file" toml:"file" env:"OTEL_TRACES_FILE"`
+}
+
+// AccessLog configures the request log
+type AccessLog struct {
+	// Sample2xx logs every Nth successful response (0 or 1 = all)
+	Sample2xx int `yaml:"sample_2xx" toml:"sample_2xx" env:"ACCESS_LOG_SAMPLE_2XX"`
+
+	// Headers adds the (redacted) request headers
+	Headers bool `yaml:"headers" toml:"headers" env:"ACCESS_LOG_HEADERS"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	return Config{
+		Env:         EnvDevelopment,
+		FrontendURL: "http://localhost:3000",
+		Log:         Log{Level: "info"},
+		Server:      Server{Host: "0.0.0.0", Port: 3001},
+		TLS:         TLS{ReloadInterval: time.Minute},
+		Shutdown:    Shutdown{DrainDelay: 5 * time.Second, Timeout: 30 * time.Second, WorkerTimeout: 10 * time.Second},
+		Database: Database{
+			// SQLite with WAL mode for better concurrency:
+			// - _journal_mode=WAL: Write-Ahead Logging (readers don't block writers)
+			// - _busy_timeout=5000: Wait 5s before "database is locked" error
+			// - _synchronous=NORMAL: Good balance of safety and performance
+			// - _cache_size=-262144: 256MB cache (negative = KB)
+			// - _foreign_keys=ON: Enforce foreign key constraints
+			URL: "file:./data/db/sqlite/app.db?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL&_cache_size=-262144&_foreign_keys=ON",
+		},
+		JWT:         JWT{ExpiresIn: 15 * time.Minute, RefreshTokenDays: 7},
+		Cookie:      Cookie{SameSite: "Lax"},
+		SMTP:        SMTP{Port: 587, UseTLS: true},
+		CORS:        CORS{Origins: []string{"http://localhost:3000"}},
+		RateLimit:   RateLimit{Storage: "memory"},
+		Idempotency: Idempotency{Store: "sql", TTL: 24 * time.Hour},
+		Metrics:     Metrics{AllowedIPs: []string{"127.0.0.1", "::1"}},
+		Health:      Health{DiskMinFreeMB: 256},
+		Tracing:     Tracing{ServiceName: "backend-go-fiber", File: "./data/traces.jsonl"},
+	}
+}
+
+// IsProduction reports whether Env is production
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// Addr is the listen address
+func (c *Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+}
+
+// minJWTSecretLength is the shortest JWT secret accepted in production
+const minJWTSecretLength = 32
+
+// Validate returns every problem of the configuration joined into one error
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		problems = append(problems, fmt.Errorf("%s: %q is not one of %v", key, value, allowed))
+	}
+	ipList := func(key string, entries []string) {
+		for _, entry := range entries {
+			if _, err := utils.ParseIPList(entry); err != nil || entry == "" {
+				problems = append(problems, fmt.Errorf("%s: invalid IP or CIDR range %q", key, entry))
+			}
+		}
+	}
+	absoluteURL := func(key, value string) {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Errorf("%s: %q is not an absolute URL", key, value))
+		}
+	}
+
+	oneOf("env", c.Env, EnvDevelopment, EnvProduction, EnvTest)
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	absoluteURL("frontend_url", c.FrontendURL)
+	if c.ProblemTypeBaseURL != "" {
+		absoluteURL("problem_type_base_url", c.ProblemTypeBaseURL)
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port: %d is out of range", c.Server.Port)
+	ipList("server.trusted_proxies", c.Server.TrustedProxies)
+
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "tls.cert_file and tls.key_file must be set together")
+	check(c.TLS.ReloadInterval > 0, "tls.reload_interval must be positive")
+	check(!c.TLS.Enabled() || !c.Server.Prefork, "tls is not supported with server.prefork")
+	check(c.TLS.ClientCAFile == "" || c.TLS.Enabled(), "tls.client_ca_file requires tls.cert_file")
+	check(len(c.TLS.ClientAuthRoutes) == 0 || c.TLS.ClientCAFile != "", "tls.client_auth_routes requires tls.client_ca_file")
+	for _, route := range c.TLS.ClientAuthRoutes {
+		check(strings.HasPrefix(route, "/") || route == "*", "tls.client_auth_routes: %q must start with / or be *", route)
+	}
+
+	check(c.Shutdown.DrainDelay >= 0, "shutdown.drain_delay must not be negative")
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout must be positive")
+	check(c.Shutdown.WorkerTimeout > 0, "shutdown.worker_timeout must be positive")
+
+	check(c.Database.URL != "", "database.url is required")
+
+	if c.IsProduction() {
+		check(c.JWT.Secret != "", "jwt.secret is required in production")
+		check(c.JWT.Secret == "" || len(c.JWT.Secret) >= minJWTSecretLength,
+			"jwt.secret must be at least %d characters in production", minJWTSecretLength)
+	}
+	check(c.JWT.ExpiresIn > 0, "jwt.expires_in must be positive")
+	check(c.JWT.RefreshTokenDays > 0, "jwt.refresh_token_days must be positive")
+
+	oneOf("cookie.same_site", c.Cookie.SameSite, "Lax", "Strict", "None")
+
+	if c.IsProduction() {
+		check(c.SMTP.Host != "", "smtp.host is required in production")
+		check(c.SMTP.FromAddress != "", "smtp.from_address is required in production")
+	}
+	check(c.SMTP.Port > 0 && c.SMTP.Port <= 65535, "smtp.port: %d is out of range", c.SMTP.Port)
+
+	if c.S3.Bucket != "" {
+		check(c.S3.Region != "", "s3.region is required with s3.bucket")
+	}
+	check((c.S3.AccessKey == "") == (c.S3.SecretKey == ""), "s3.access_key and s3.secret_key must be set together")
+
+	check(len(c.CORS.Origins) > 0, "cors.origins is required")
+
+	oneOf("rate_limit.storage", c.RateLimit.Storage, "memory", "sql", "redis")
+	if c.RateLimit.Storage == "redis" {
+		check(c.RateLimit.RedisURL != "", "rate_limit.redis_url is required for redis storage")
+	}
+
+	oneOf("idempotency.store", c.Idempotency.Store, "sql", "memory")
+	check(c.Idempotency.TTL > 0, "idempotency.ttl must be positive")
+
+	ipList("metrics.allowed_ips", c.Metrics.AllowedIPs)
+
+	check(c.Health.DiskMinFreeMB >= 0, "health.disk_min_free_mb must not be negative")
+
+	oneOf("tracing.exporter", c.Tracing.Exporter, "none", "stdout", "file", "otlp")
+	check(c.AccessLog.Sample2xx >= 0, "access_log.sample_2xx must not be negative")
+
+	return errors.Join(problems...)
+}
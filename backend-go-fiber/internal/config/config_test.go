@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("NODE_ENV", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Addr() != "0.0.0.0:3001" || cfg.SMTP.Port != 587 || cfg.Idempotency.TTL != 24*time.Hour {
+		t.Errorf("Unexpected defaults: %+v", cfg)
+	}
+	if cfg.Tracing.Exporter != "none" {
+		t.Errorf("Tracing.Exporter = %q, expected none", cfg.Tracing.Exporter)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+server:
+  port: 4000
+  host: 127.0.0.1
+smtp:
+  host: smtp.example.com
+jwt:
+  expires_in: 1h
+csp:
+  directives:
+    img-src: ["'self'"]
+`)
+	t.Setenv(FileEnv, file)
+	t.Setenv("PORT", "5000")
+	t.Setenv("SMTP_PORT", "2525")
+
+	cfg, err := Load([]string{"--server.port=6000", "--server.prefork"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Server.Port != 6000 {
+		t.Errorf("flag must win: port = %d", cfg.Server.Port)
+	}
+	if cfg.SMTP.Port != 2525 || cfg.SMTP.Host != "smtp.example.com" {
+		t.Errorf("env must win over the file: smtp = %+v", cfg.SMTP)
+	}
+	if cfg.Server.Host != "127.0.0.1" || cfg.JWT.ExpiresIn != time.Hour || !cfg.Server.Prefork {
+		t.Errorf("Unexpected server/jwt: %+v %+v", cfg.Server, cfg.JWT)
+	}
+	if got := cfg.CSP.Directives["img-src"]; len(got) != 1 || got[0] != "'self'" {
+		t.Errorf("CSP directives = %v", cfg.CSP.Directives)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	file := writeFile(t, "config.toml", `
+env = "test"
+
+[cors]
+origins = ["https://app.example.com", "https://admin.example.com"]
+
+[idempotency]
+ttl = "2h"
+`)
+
+	cfg, err := Load([]string{"--config", file})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Env != EnvTest || len(cfg.CORS.Origins) != 2 || cfg.Idempotency.TTL != 2*time.Hour {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	secret := strings.Repeat("s", 40)
+	t.Setenv("JWT_SECRET_FILE", writeFile(t, "jwt", secret+"\n"))
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWT.Secret != secret {
+		t.Errorf("JWT.Secret = %q, expected the file content", cfg.JWT.Secret)
+	}
+
+	t.Setenv("JWT_SECRET", "inline")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "JWT_SECRET_FILE") {
+		t.Errorf("Load() with NAME and NAME_FILE: error = %v", err)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Setenv("NODE_ENV", "production")
+	t.Setenv("PORT", "eighty")
+	t.Setenv("IDEMPOTENCY_TTL", "soon")
+	t.Setenv("CSP_DIRECTIVES", `script-src 'self'`)
+	t.Setenv("RATE_LIMIT_STORAGE", "redis")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/33")
+
+	_, err := Load([]string{"--cookie.same_site=Loose", "--tls.cert_file=/etc/tls/tls.crt"})
+	if err == nil {
+		t.Fatal("Load() accepted an invalid configuration")
+	}
+
+	for _, want := range []string{
+		"PORT", "IDEMPOTENCY_TTL", "CSP_DIRECTIVES",
+		"jwt.secret is required", "smtp.host is required", "rate_limit.redis_url",
+		"server.trusted_proxies", "cookie.same_site", "tls.key_file",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadUnknownKeys(t *testing.T) {
+	for _, file := range []string{
+		writeFile(t, "config.yaml", "server:\n  prot: 80\n"),
+		writeFile(t, "config.toml", "[server]\nprot = 80\n"),
+		writeFile(t, "config.json", "{}"),
+	} {
+		if _, err := Load([]string{"--config", file}); err == nil {
+			t.Errorf("Load(%s): expected an error", filepath.Base(file))
+		}
+	}
+	if _, err := Load([]string{"--server.prot=80"}); err == nil {
+		t.Error("Load() accepted an unknown flag")
+	}
+}
+
+func TestTracingExporter(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	if cfg, _ := Load(nil); cfg.Tracing.Exporter != "otlp" {
+		t.Errorf("Expected otlp when an endpoint is set, got %s", cfg.Tracing.Exporter)
+	}
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	if cfg, _ := Load(nil); cfg.Tracing.Exporter != "stdout" {
+		t.Errorf("Expected console to map to stdout, got %s", cfg.Tracing.Exporter)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.JWT.Secret = "super-secret-value"
+	cfg.SMTP.User = "mailer"
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "super-secret-value") || !strings.Contains(out, "[REDACTED]") {
+		t.Errorf("secret not redacted:\n%s", out)
+	}
+	if !strings.Contains(out, "user: mailer") || !strings.Contains(out, "expires_in: 15m0s") {
+		t.Errorf("missing values:\n%s", out)
+	}
+	if cfg.JWT.Secret != "super-secret-value" {
+		t.Error("Print() modified the config")
+	}
+
+	// The output is a valid config file
+	file := writeFile(t, "printed.yaml", out)
+	if _, err := Load([]string{"--config", file, "--jwt.secret=" + strings.Repeat("x", 32)}); err != nil {
+		t.Errorf("Load(printed config) error = %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the config file when --config is not given
+const FileEnv = "CONFIG_FILE"
+
+// field is a leaf of Config
+type field struct {
+	key    string // dotted yaml key path, also the flag name
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists the leaves of cfg in declaration order
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := prefix + strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			out = append(out, field{
+				key:    key,
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return out
+}
+
+// set parses raw into the field. Lists are comma-separated, maps are JSON.
+func (f field) set(raw string) error {
+	v := f.value
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice:
+		list := []string{}
+		for _, entry := range strings.Split(raw, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = append(list, entry)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	case v.Kind() == reflect.Map:
+		m := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(raw), m.Interface()); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		v.Set(m.Elem())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Load builds the configuration from the defaults, the config file
+// (--config or CONFIG_FILE), the environment and the flags in args,
+// then validates it. The error lists every problem found.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	leaves := fields(&cfg)
+
+	var problems []error
+
+	// Flags are applied last but parsed first: they may name the config file
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	file := fs.String("config", os.Getenv(FileEnv), "YAML or TOML config file")
+	flagValues := make(map[string]string)
+	for _, f := range leaves {
+		key := f.key
+		record := func(value string) error {
+			flagValues[key] = value
+			return nil
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(key, "", record)
+		} else {
+			fs.Func(key, "", record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if *file != "" {
+		if err := loadFile(&cfg, *file); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	for _, f := range leaves {
+		if f.env == "" {
+			continue
+		}
+		raw, name, err := lookupEnv(f.env)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		if err := f.set(raw); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	for _, f := range leaves {
+		if raw, ok := flagValues[f.key]; ok {
+			if err := f.set(raw); err != nil {
+				problems = append(problems, fmt.Errorf("--%s: %w", f.key, err))
+			}
+		}
+	}
+
+	cfg.resolve()
+
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// lookupEnv returns the value of NAME, or the content of the file named by
+// NAME_FILE (Docker and Kubernetes secrets). name is the variable the value
+// came from, empty if neither is set. Empty variables count as unset.
+func lookupEnv(env string) (value, name string, err error) {
+	if path := os.Getenv(env + "_FILE"); path != "" {
+		if os.Getenv(env) != "" {
+			return "", "", fmt.Errorf("%s and %s_FILE are both set", env, env)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("%s_FILE: %w", env, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), env + "_FILE", nil
+	}
+	if value := os.Getenv(env); value != "" {
+		return value, env, nil
+	}
+	return "", "", nil
+}
+
+// loadFile decodes a YAML (.yaml, .yml) or TOML (.toml) file over cfg.
+// Unknown keys are errors so that typos don't go unnoticed.
+func loadFile(cfg *Config, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(b), cfg)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config file %s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q (use .yaml, .yml or .toml)", path, ext)
+	}
+	return nil
+}
+
+// resolve fills values that depend on other settings
+func (c *Config) resolve() {
+	switch c.Tracing.Exporter {
+	case "":
+		c.Tracing.Exporter = "none"
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			c.Tracing.Exporter = "otlp"
+		}
+	case "console":
+		c.Tracing.Exporter = "stdout"
+	}
+}
+
+// Usage writes the flags and their environment variables
+func Usage(w io.Writer) {
+	cfg := Default()
+	fmt.Fprintf(w, "  --config string\n\tYAML or TOML config file (%s)\n", FileEnv)
+	for _, f := range fields(&cfg) {
+		fmt.Fprintf(w, "  --%s %s\n", f.key, f.value.Type())
+		if f.env != "" {
+			fmt.Fprintf(w, "\t%s or %s_FILE\n", f.env, f.env)
+		}
+	}
+}
+
+// Print writes the effective configuration as YAML, which can be used as a
+// config file. Secrets that are set are replaced with "[REDACTED]".
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	for _, f := range fields(&redacted) {
+		if f.secret && !f.value.IsZero() {
+			f.value.SetString("[REDACTED]")
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&redacted); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Component is a part of the server that takes new configuration at runtime
+type Component struct {
+	Name string
+
+	// Keys are the key prefixes the component applies ("smtp.", "log.level").
+	// It runs when one of them changed; an empty prefix matches every reload.
+	Keys []string
+
+	// Prepare builds the new state from cfg without side effects and returns
+	// the function that makes it live. An error rejects the whole reload.
+	Prepare func(cfg *Config) (apply func(), err error)
+}
+
+// ReloadResult lists the keys a reload changed
+type ReloadResult struct {
+	// Applied keys are live
+	Applied []string `json:"applied"`
+
+	// RestartRequired keys changed but are only read at startup
+	RestartRequired []string `json:"restartRequired"`
+}
+
+// Reloader re-reads the configuration (SIGHUP, admin endpoint) and swaps the
+// components whose keys changed. Invalid configuration or a component that
+// fails to prepare leaves everything on the old configuration.
+type Reloader struct {
+	args       []string
+	current    atomic.Pointer[Config]
+	mu         sync.Mutex
+	components []Component
+}
+
+// NewReloader starts from cfg; args are the flags cfg was loaded with
+func NewReloader(cfg *Config, args []string) *Reloader {
+	r := &Reloader{args: args}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current returns the live configuration
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Register adds a component. Register before the first reload.
+func (r *Reloader) Register(c Component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, c)
+}
+
+// Reload loads the configuration again and applies it
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.args)
+	if err != nil {
+		return nil, err
+	}
+	current := r.current.Load()
+	changed := Diff(current, next)
+
+	handled := make(map[string]bool)
+	for _, c := range r.components {
+		for _, key := range matching(changed, c.Keys) {
+			handled[key] = true
+		}
+	}
+	// Keys no component applies keep their startup values: Current() must
+	// describe what the server runs with, not what it will run with after a restart
+	keep(next, current, handled, changed)
+
+	// Prepare every affected component before touching any of them
+	var applies []func()
+	var problems []error
+	for _, c := range r.components {
+		if len(matching(changed, c.Keys)) == 0 && !contains(c.Keys, "") {
+			continue
+		}
+		apply, err := c.Prepare(next)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		applies = append(applies, apply)
+	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+	r.current.Store(next)
+
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	for _, key := range changed {
+		if handled[key] {
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+	return result, nil
+}
+
+// Diff returns the keys whose values differ between a and b
+func Diff(a, b *Config) []string {
+	var changed []string
+	bFields := fields(b)
+	for i, f := range fields(a) {
+		if !reflect.DeepEqual(f.value.Interface(), bFields[i].value.Interface()) {
+			changed = append(changed, f.key)
+		}
+	}
+	return changed
+}
+
+// keep copies the changed keys that are not handled from old into cfg
+func keep(cfg, old *Config, handled map[string]bool, changed []string) {
+	oldFields := fields(old)
+	for i, f := range fields(cfg) {
+		if !handled[f.key] && contains(changed, f.key) {
+			f.value.Set(oldFields[i].value)
+		}
+	}
+}
+
+// matching returns the keys starting with one of the prefixes
+func matching(keys, prefixes []string) []string {
+	var out []string
+	for _, key := range keys {
+		for _, prefix := range prefixes {
+			if prefix != "" && strings.HasPrefix(key, prefix) {
+				out = append(out, key)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
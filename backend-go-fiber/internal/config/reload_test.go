@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startupPort := cfg.Server.Port
+	r := NewReloader(cfg, nil)
+	var level string
+	var smtpPrepared, alwaysRan int
+	r.Register(Component{
+		Name: "log",
+		Keys: []string{"log.level"},
+		Prepare: func(cfg *Config) (func(), error) {
+			return func() { level = cfg.Log.Level }, nil
+		},
+	})
+	r.Register(Component{
+		Name: "smtp",
+		Keys: []string{"smtp."},
+		Prepare: func(cfg *Config) (func(), error) {
+			smtpPrepared++
+			if cfg.SMTP.Host == "broken.example.com" {
+				return nil, errors.New("cannot connect")
+			}
+			return func() {}, nil
+		},
+	})
+	r.Register(Component{
+		Name:    "always",
+		Keys:    []string{""},
+		Prepare: func(*Config) (func(), error) { return func() { alwaysRan++ }, nil },
+	})
+
+	// Changed keys are applied or reported as needing a restart
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("PORT", "4000")
+	result, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !reflect.DeepEqual(result.Applied, []string{"log.level"}) || !reflect.DeepEqual(result.RestartRequired, []string{"server.port"}) {
+		t.Errorf("Reload() = %+v", result)
+	}
+	if level != "debug" || smtpPrepared != 0 || alwaysRan != 1 {
+		t.Errorf("level = %q, smtp prepared %d times, always ran %d times", level, smtpPrepared, alwaysRan)
+	}
+	// Restart-required keys keep the value the server runs with
+	if r.Current().Log.Level != "debug" || r.Current().Server.Port != startupPort {
+		t.Errorf("Current() has log level %q and port %d, want debug and %d", r.Current().Log.Level, r.Current().Server.Port, startupPort)
+	}
+
+	// ...and are reported again until the server restarts
+	result, err = r.Reload()
+	if err != nil || len(result.Applied) != 0 || !reflect.DeepEqual(result.RestartRequired, []string{"server.port"}) {
+		t.Errorf("second Reload() = %+v, %v", result, err)
+	}
+
+	// Invalid configuration keeps the current one
+	t.Setenv("LOG_LEVEL", "verbose")
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Reload() accepted an invalid log level")
+	}
+	if level != "debug" || r.Current().Log.Level != "debug" {
+		t.Errorf("rejected reload changed the level to %q", level)
+	}
+
+	// A component failing to prepare rejects the reload for every component
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("SMTP_HOST", "broken.example.com")
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Reload() ignored a failing component")
+	}
+	if level != "debug" || r.Current().SMTP.Host != "" || alwaysRan != 2 {
+		t.Errorf("failed reload was partially applied: level %q, smtp host %q", level, r.Current().SMTP.Host)
+	}
+}
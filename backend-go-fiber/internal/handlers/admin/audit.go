@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	service *admin.AuditService
+}
+
+func NewAuditHandler(service *admin.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// List returns paginated list of audit log entries
+// GET /api/admin/audit
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	params, err := auditListParams(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.service.List(c.UserContext(), params)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list audit log")
+	}
+
+	return utils.SendSuccess(c, result, fiber.StatusOK)
+}
+
+// Export downloads the audit log entries matching the filters as CSV
+// GET /api/admin/audit/export
+func (h *AuditHandler) Export(c *fiber.Ctx) error {
+	params, err := auditListParams(c)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.Export(c.UserContext(), params, &buf); err != nil {
+		return apperror.OrInternal(err, "Failed to export audit log")
+	}
+
+	filename := "audit-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// auditListParams reads the filters shared by List and Export
+func auditListParams(c *fiber.Ctx) (admin.AuditListParams, error) {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+
+	params := admin.AuditListParams{
+		Page:       page,
+		PageSize:   pageSize,
+		Action:     c.Query("action", ""),
+		ActorID:    c.Query("actorId", ""),
+		TargetType: c.Query("targetType", ""),
+		TargetID:   c.Query("targetId", ""),
+		Search:     c.Query("search", ""),
+	}
+
+	var err error
+	if params.From, err = parseTimeQuery(c, "from"); err != nil {
+		return params, err
+	}
+	if params.To, err = parseTimeQuery(c, "to"); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// parseTimeQuery parses an RFC 3339 timestamp or a date (midnight UTC)
+func parseTimeQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name, "")
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return &t, nil
+	}
+	return nil, apperror.BadRequest(apperror.CodeValidation, "Invalid date filter, use RFC 3339 or YYYY-MM-DD")
+}
+
+// adminEntry is an audit entry for a mutation by the admin making the request
+// (set by middleware.AdminOnly)
+func adminEntry(c *fiber.Ctx, action, targetType, targetID string, before, after any) audit.Entry {
+	entry := audit.Entry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	}
+	if adminUser, ok := c.Locals("adminUser").(*models.User); ok {
+		entry.ActorID = adminUser.ID
+		entry.ActorEmail = adminUser.Email
+	}
+	return entry
+}
@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"strings"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/config"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidConfig is returned when a reload finds invalid configuration
+var ErrInvalidConfig = apperror.New(fiber.StatusUnprocessableEntity, "INVALID_CONFIG", "Configuration is invalid, the current one is kept")
+
+type ConfigHandler struct {
+	reloader *config.Reloader
+	audit    *audit.Log
+}
+
+func NewConfigHandler(reloader *config.Reloader, auditLog *audit.Log) *ConfigHandler {
+	return &ConfigHandler{reloader: reloader, audit: auditLog}
+}
+
+// Reload re-reads the configuration file and environment and applies it
+// POST /api/admin/config/reload
+func (h *ConfigHandler) Reload(c *fiber.Ctx) error {
+	result, err := h.reloader.Reload()
+	if err != nil {
+		e := ErrInvalidConfig.Wrap(err)
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			e.Details = append(e.Details, utils.FieldError{Field: "config", Message: problem})
+		}
+		return e
+	}
+
+	log.Ctx(c.UserContext()).Info().
+		Strs("applied", result.Applied).
+		Strs("restart_required", result.RestartRequired).
+		Msg("Configuration reloaded")
+
+	// Values are not recorded: changed keys may be secrets
+	entry := adminEntry(c, audit.ActionConfigReload, audit.TargetConfig, "", nil, nil)
+	entry.Metadata = map[string]string{
+		"applied":         strings.Join(result.Applied, ","),
+		"restartRequired": strings.Join(result.RestartRequired, ","),
+	}
+	h.audit.Record(c.UserContext(), entry)
+	return utils.SendSuccess(c, result, fiber.StatusOK)
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"strconv"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CSPReportsHandler struct {
+	service *admin.CSPReportsService
+	audit   *audit.Log
+}
+
+func NewCSPReportsHandler(service *admin.CSPReportsService, auditLog *audit.Log) *CSPReportsHandler {
+	return &CSPReportsHandler{service: service, audit: auditLog}
+}
+
+// List returns paginated list of CSP violation reports
+// GET /api/admin/csp-reports
+func (h *CSPReportsHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+
+	result, err := h.service.List(c.UserContext(), admin.CSPReportListParams{
+		Page:      page,
+		PageSize:  pageSize,
+		Directive: c.Query("directive", ""),
+		Search:    c.Query("search", ""),
+	})
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list CSP reports")
+	}
+
+	return utils.SendSuccess(c, result, fiber.StatusOK)
+}
+
+// Clear deletes all CSP violation reports
+// DELETE /api/admin/csp-reports
+func (h *CSPReportsHandler) Clear(c *fiber.Ctx) error {
+	deleted, err := h.service.Clear(c.UserContext())
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to clear CSP reports")
+	}
+
+	entry := adminEntry(c, audit.ActionCSPReportsClear, audit.TargetCSPReports, "", nil, nil)
+	entry.Metadata = map[string]string{"deleted": strconv.FormatInt(deleted, 10)}
+	h.audit.Record(c.UserContext(), entry)
+
+	return utils.SendSuccess(c, fiber.Map{"deleted": deleted}, fiber.StatusOK)
+}
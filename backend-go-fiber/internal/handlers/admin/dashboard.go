@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/services/admin"
 	"backend-go-fiber/internal/utils"
 
@@ -18,9 +19,9 @@ func NewDashboardHandler(service *admin.DashboardService) *DashboardHandler {
 // GetStats returns dashboard statistics
 // GET /api/admin/dashboard
 func (h *DashboardHandler) GetStats(c *fiber.Ctx) error {
-	stats, err := h.service.GetStats()
+	stats, err := h.service.GetStats(c.UserContext())
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to get dashboard stats", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to get dashboard stats")
 	}
 
 	return utils.SendSuccess(c, stats, fiber.StatusOK)
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"backend-go-fiber/internal/services/audit"
 	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,10 +14,11 @@ import (
 
 type FilesHandler struct {
 	uploadDir string
+	audit     *audit.Log
 }
 
-func NewFilesHandler(uploadDir string) *FilesHandler {
-	return &FilesHandler{uploadDir: uploadDir}
+func NewFilesHandler(uploadDir string, auditLog *audit.Log) *FilesHandler {
+	return &FilesHandler{uploadDir: uploadDir, audit: auditLog}
 }
 
 // FileInfo represents file information
@@ -146,6 +148,14 @@ func (h *FilesHandler) Delete(c *fiber.Ctx) error {
 		return utils.SendError(c, "INTERNAL_ERROR", "Failed to delete file", fiber.StatusInternalServerError)
 	}
 
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionFileDelete, audit.TargetFile, filePath, FileInfo{
+		Name:    info.Name(),
+		Path:    filePath,
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime(),
+	}, nil))
+
 	return utils.SendSuccess(c, fiber.Map{"message": "File deleted successfully"}, fiber.StatusOK)
 }
 
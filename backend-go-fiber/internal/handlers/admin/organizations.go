@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"strconv"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OrganizationsHandler struct {
+	service *admin.OrganizationsService
+	audit   *audit.Log
+}
+
+func NewOrganizationsHandler(service *admin.OrganizationsService, auditLog *audit.Log) *OrganizationsHandler {
+	return &OrganizationsHandler{service: service, audit: auditLog}
+}
+
+// memberAudit is a membership as recorded in the audit log
+type memberAudit struct {
+	UserID string         `json:"userId"`
+	Role   models.OrgRole `json:"role"`
+}
+
+// List returns paginated list of organizations
+// GET /api/admin/organizations
+func (h *OrganizationsHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("pageSize", "10"))
+
+	result, err := h.service.List(c.UserContext(), admin.OrganizationListParams{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   c.Query("search", ""),
+	})
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list organizations")
+	}
+
+	return utils.SendSuccess(c, result, fiber.StatusOK)
+}
+
+// Get returns a single organization by ID
+// GET /api/admin/organizations/:id
+func (h *OrganizationsHandler) Get(c *fiber.Ctx) error {
+	org, err := h.service.GetByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to get organization")
+	}
+
+	return utils.SendSuccess(c, org, fiber.StatusOK)
+}
+
+// Update updates an organization
+// PUT /api/admin/organizations/:id
+func (h *OrganizationsHandler) Update(c *fiber.Ctx) error {
+	var input admin.UpdateOrganizationInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	// Validate input
+	if errors := utils.ValidateStruct(input); errors != nil {
+		return utils.SendValidationError(c, errors)
+	}
+
+	id := c.Params("id")
+	before, err := h.service.GetByID(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update organization")
+	}
+
+	org, err := h.service.Update(c.UserContext(), id, input)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update organization")
+	}
+
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionOrganizationUpdate, audit.TargetOrganization, id, before, org))
+	return utils.SendSuccess(c, org, fiber.StatusOK)
+}
+
+// Delete soft deletes an organization
+// DELETE /api/admin/organizations/:id
+func (h *OrganizationsHandler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	before, err := h.service.GetByID(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to delete organization")
+	}
+
+	if err := h.service.Delete(c.UserContext(), id); err != nil {
+		return apperror.OrInternal(err, "Failed to delete organization")
+	}
+
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionOrganizationDelete, audit.TargetOrganization, id, before, nil))
+
+	return utils.SendSuccess(c, fiber.Map{"message": "Organization deleted successfully"}, fiber.StatusOK)
+}
+
+// ListMembers returns members of an organization
+// GET /api/admin/organizations/:id/members
+func (h *OrganizationsHandler) ListMembers(c *fiber.Ctx) error {
+	members, err := h.service.ListMembers(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list members")
+	}
+
+	return utils.SendSuccess(c, members, fiber.StatusOK)
+}
+
+// AddMember adds a user to an organization
+// POST /api/admin/organizations/:id/members
+func (h *OrganizationsHandler) AddMember(c *fiber.Ctx) error {
+	var input admin.AddMemberInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	// Validate input
+	if errors := utils.ValidateStruct(input); errors != nil {
+		return utils.SendValidationError(c, errors)
+	}
+
+	id := c.Params("id")
+	membership, err := h.service.AddMember(c.UserContext(), id, input)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to add member")
+	}
+
+	after := memberAudit{UserID: membership.UserID, Role: membership.Role}
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionOrganizationMemberAdd, audit.TargetOrganization, id, nil, after))
+
+	return utils.SendSuccess(c, fiber.Map{
+		"userId": membership.UserID,
+		"role":   membership.Role,
+	}, fiber.StatusCreated)
+}
+
+// UpdateMember changes a member's role
+// PUT /api/admin/organizations/:id/members/:userId
+func (h *OrganizationsHandler) UpdateMember(c *fiber.Ctx) error {
+	var input admin.SetMemberRoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	// Validate input
+	if errors := utils.ValidateStruct(input); errors != nil {
+		return utils.SendValidationError(c, errors)
+	}
+
+	id, userID := c.Params("id"), c.Params("userId")
+	before, err := h.service.GetMembership(c.UserContext(), id, userID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update member")
+	}
+
+	membership, err := h.service.SetMemberRole(c.UserContext(), id, userID, input.Role)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update member")
+	}
+
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionOrganizationMemberUpdate, audit.TargetOrganization, id,
+		memberAudit{UserID: userID, Role: before.Role}, memberAudit{UserID: userID, Role: membership.Role}))
+
+	return utils.SendSuccess(c, fiber.Map{
+		"userId": membership.UserID,
+		"role":   membership.Role,
+	}, fiber.StatusOK)
+}
+
+// RemoveMember removes a user from an organization
+// DELETE /api/admin/organizations/:id/members/:userId
+func (h *OrganizationsHandler) RemoveMember(c *fiber.Ctx) error {
+	id, userID := c.Params("id"), c.Params("userId")
+	before, err := h.service.GetMembership(c.UserContext(), id, userID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to remove member")
+	}
+
+	if err := h.service.RemoveMember(c.UserContext(), id, userID); err != nil {
+		return apperror.OrInternal(err, "Failed to remove member")
+	}
+
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionOrganizationMemberRemove, audit.TargetOrganization, id,
+		memberAudit{UserID: userID, Role: before.Role}, nil))
+
+	return utils.SendSuccess(c, fiber.Map{"message": "Member removed successfully"}, fiber.StatusOK)
+}
@@ -1,8 +1,10 @@
 package admin
 
 import (
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
 	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,10 +12,11 @@ import (
 
 type SettingsHandler struct {
 	service *admin.SettingsService
+	audit   *audit.Log
 }
 
-func NewSettingsHandler(service *admin.SettingsService) *SettingsHandler {
-	return &SettingsHandler{service: service}
+func NewSettingsHandler(service *admin.SettingsService, auditLog *audit.Log) *SettingsHandler {
+	return &SettingsHandler{service: service, audit: auditLog}
 }
 
 // GetAll returns all settings
@@ -26,13 +29,13 @@ func (h *SettingsHandler) GetAll(c *fiber.Ctx) error {
 	var err error
 
 	if group != "" {
-		settings, err = h.service.GetByGroup(group)
+		settings, err = h.service.GetByGroup(c.UserContext(), group)
 	} else {
-		settings, err = h.service.GetAll()
+		settings, err = h.service.GetAll(c.UserContext())
 	}
 
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to get settings", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to get settings")
 	}
 
 	return utils.SendSuccess(c, settings, fiber.StatusOK)
@@ -46,12 +49,9 @@ func (h *SettingsHandler) Get(c *fiber.Ctx) error {
 		return utils.SendError(c, "VALIDATION_ERROR", "Setting key is required", fiber.StatusBadRequest)
 	}
 
-	setting, err := h.service.GetByKey(key)
+	setting, err := h.service.GetByKey(c.UserContext(), key)
 	if err != nil {
-		if err.Error() == "setting not found" {
-			return utils.SendError(c, "NOT_FOUND", "Setting not found", fiber.StatusNotFound)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to get setting", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to get setting")
 	}
 
 	return utils.SendSuccess(c, setting.ToResponse(), fiber.StatusOK)
@@ -75,15 +75,20 @@ func (h *SettingsHandler) Update(c *fiber.Ctx) error {
 		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
 	}
 
-	setting, err := h.service.Update(key, input.Value)
+	before, err := h.service.GetByKey(c.UserContext(), key)
 	if err != nil {
-		if err.Error() == "setting not found" {
-			return utils.SendError(c, "NOT_FOUND", "Setting not found", fiber.StatusNotFound)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to update setting", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to update setting")
 	}
 
-	return utils.SendSuccess(c, setting.ToResponse(), fiber.StatusOK)
+	setting, err := h.service.Update(c.UserContext(), key, input.Value, c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update setting")
+	}
+
+	response := setting.ToResponse()
+	h.recordChange(c, before.ToResponse(), response)
+
+	return utils.SendSuccess(c, response, fiber.StatusOK)
 }
 
 // UpdateBatch updates multiple settings at once
@@ -95,14 +100,37 @@ func (h *SettingsHandler) UpdateBatch(c *fiber.Ctx) error {
 	}
 
 	// Validate input
-	if errors := utils.ValidateStruct(input); errors != nil {
-		return utils.SendValidationError(c, errors)
+	if validationErrors := utils.ValidateStruct(input); validationErrors != nil {
+		return utils.SendValidationError(c, validationErrors)
 	}
 
-	settings, err := h.service.UpdateBatch(input.Settings)
+	before, err := h.service.GetAll(c.UserContext())
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to update settings", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to update settings")
+	}
+
+	settings, err := h.service.UpdateBatch(c.UserContext(), input.Settings, c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update settings")
+	}
+
+	previous := make(map[string]models.AppSettingsResponse, len(before))
+	for _, setting := range before {
+		previous[setting.Key] = setting
+	}
+	for _, setting := range settings {
+		if old, ok := previous[setting.Key]; ok {
+			h.recordChange(c, old, setting)
+		}
 	}
 
 	return utils.SendSuccess(c, settings, fiber.StatusOK)
 }
+
+// recordChange adds a setting update to the audit log, unless the value is unchanged
+func (h *SettingsHandler) recordChange(c *fiber.Ctx, before, after models.AppSettingsResponse) {
+	if before.Value == after.Value {
+		return
+	}
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionSettingUpdate, audit.TargetSetting, after.Key, before, after))
+}
@@ -3,7 +3,10 @@ package admin
 import (
 	"strconv"
 
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/audit"
 	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,10 +14,11 @@ import (
 
 type UsersHandler struct {
 	service *admin.UsersService
+	audit   *audit.Log
 }
 
-func NewUsersHandler(service *admin.UsersService) *UsersHandler {
-	return &UsersHandler{service: service}
+func NewUsersHandler(service *admin.UsersService, auditLog *audit.Log) *UsersHandler {
+	return &UsersHandler{service: service, audit: auditLog}
 }
 
 // List returns paginated list of users
@@ -44,9 +48,9 @@ func (h *UsersHandler) List(c *fiber.Ctx) error {
 		IsActive: isActive,
 	}
 
-	result, err := h.service.List(params)
+	result, err := h.service.List(c.UserContext(), params)
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to list users", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to list users")
 	}
 
 	return utils.SendSuccess(c, result, fiber.StatusOK)
@@ -60,12 +64,9 @@ func (h *UsersHandler) Get(c *fiber.Ctx) error {
 		return utils.SendError(c, "VALIDATION_ERROR", "User ID is required", fiber.StatusBadRequest)
 	}
 
-	user, err := h.service.GetByID(id)
+	user, err := h.service.GetByID(c.UserContext(), id)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return utils.SendError(c, "NOT_FOUND", "User not found", fiber.StatusNotFound)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to get user", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to get user")
 	}
 
 	return utils.SendSuccess(c, user.ToAdminResponse(), fiber.StatusOK)
@@ -84,15 +85,15 @@ func (h *UsersHandler) Create(c *fiber.Ctx) error {
 		return utils.SendValidationError(c, errors)
 	}
 
-	user, err := h.service.Create(input)
+	user, err := h.service.Create(c.UserContext(), input)
 	if err != nil {
-		if err.Error() == "email already exists" {
-			return utils.SendError(c, "CONFLICT", "Email already exists", fiber.StatusConflict)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to create user", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to create user")
 	}
 
-	return utils.SendSuccess(c, user.ToAdminResponse(), fiber.StatusCreated)
+	response := user.ToAdminResponse()
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionUserCreate, audit.TargetUser, user.ID, nil, response))
+
+	return utils.SendSuccess(c, response, fiber.StatusCreated)
 }
 
 // Update updates an existing user
@@ -113,18 +114,20 @@ func (h *UsersHandler) Update(c *fiber.Ctx) error {
 		return utils.SendValidationError(c, errors)
 	}
 
-	user, err := h.service.Update(id, input)
+	before, err := h.service.GetByID(c.UserContext(), id)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return utils.SendError(c, "NOT_FOUND", "User not found", fiber.StatusNotFound)
-		}
-		if err.Error() == "email already exists" {
-			return utils.SendError(c, "CONFLICT", "Email already exists", fiber.StatusConflict)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to update user", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to update user")
 	}
 
-	return utils.SendSuccess(c, user.ToAdminResponse(), fiber.StatusOK)
+	user, err := h.service.Update(c.UserContext(), id, input, c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update user")
+	}
+
+	response := user.ToAdminResponse()
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionUserUpdate, audit.TargetUser, id, before.ToAdminResponse(), response))
+
+	return utils.SendSuccess(c, response, fiber.StatusOK)
 }
 
 // Delete soft deletes a user
@@ -135,12 +138,76 @@ func (h *UsersHandler) Delete(c *fiber.Ctx) error {
 		return utils.SendError(c, "VALIDATION_ERROR", "User ID is required", fiber.StatusBadRequest)
 	}
 
-	if err := h.service.Delete(id); err != nil {
-		if err.Error() == "user not found" {
-			return utils.SendError(c, "NOT_FOUND", "User not found", fiber.StatusNotFound)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to delete user", fiber.StatusInternalServerError)
+	before, err := h.service.GetByID(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to delete user")
+	}
+
+	if err := h.service.Delete(c.UserContext(), id); err != nil {
+		return apperror.OrInternal(err, "Failed to delete user")
 	}
 
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionUserDelete, audit.TargetUser, id, before.ToAdminResponse(), nil))
+
 	return utils.SendSuccess(c, fiber.Map{"message": "User deleted successfully"}, fiber.StatusOK)
 }
+
+// Suspend suspends a user and revokes all of their sessions
+// POST /api/admin/users/:id/suspend
+func (h *UsersHandler) Suspend(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.SendError(c, "VALIDATION_ERROR", "User ID is required", fiber.StatusBadRequest)
+	}
+
+	var input admin.SuspendUserInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	// Validate input
+	if errors := utils.ValidateStruct(input); errors != nil {
+		return utils.SendValidationError(c, errors)
+	}
+
+	adminUser := c.Locals("adminUser").(*models.User)
+
+	before, err := h.service.GetByID(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to suspend user")
+	}
+
+	user, err := h.service.Suspend(c.UserContext(), id, adminUser.ID, input)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to suspend user")
+	}
+
+	response := user.ToAdminResponse()
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionUserSuspend, audit.TargetUser, id, before.ToAdminResponse(), response))
+
+	return utils.SendSuccess(c, response, fiber.StatusOK)
+}
+
+// Unsuspend lifts a user's suspension
+// POST /api/admin/users/:id/unsuspend
+func (h *UsersHandler) Unsuspend(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.SendError(c, "VALIDATION_ERROR", "User ID is required", fiber.StatusBadRequest)
+	}
+
+	before, err := h.service.GetByID(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to unsuspend user")
+	}
+
+	user, err := h.service.Unsuspend(c.UserContext(), id)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to unsuspend user")
+	}
+
+	response := user.ToAdminResponse()
+	h.audit.Record(c.UserContext(), adminEntry(c, audit.ActionUserUnsuspend, audit.TargetUser, id, before.ToAdminResponse(), response))
+
+	return utils.SendSuccess(c, response, fiber.StatusOK)
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/metrics"
 	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/services/audit"
 	"backend-go-fiber/internal/utils"
-	"os"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,10 +14,21 @@ import (
 
 type AuthHandler struct {
 	authService *services.AuthService
+	audit       *audit.Log
+	cookie      CookieConfig
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+// CookieConfig configures the refresh token cookie
+type CookieConfig struct {
+	// Secure sends the cookie over HTTPS only (production)
+	Secure bool
+
+	// SameSite: Lax (default), None (Telegram WebApp, iframes), Strict
+	SameSite string
+}
+
+func NewAuthHandler(authService *services.AuthService, auditLog *audit.Log, cookie CookieConfig) *AuthHandler {
+	return &AuthHandler{authService: authService, audit: auditLog, cookie: cookie}
 }
 
 const refreshTokenCookie = "refresh_token"
@@ -28,28 +42,18 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	// Validate input using go-playground/validator
 	validationErrors := utils.ValidateStruct(input)
 	if utils.HasValidationErrors(validationErrors) {
-		fieldErrors := make([]utils.FieldError, len(validationErrors))
-		for i, ve := range validationErrors {
-			fieldErrors[i] = utils.FieldError{
-				Field:   ve.Field,
-				Message: ve.Message,
-			}
-		}
-		return utils.SendError(c, "VALIDATION_ERROR", "Validation failed", fiber.StatusBadRequest, fieldErrors)
+		return utils.SendValidationError(c, validationErrors)
 	}
 
-	result, err := h.authService.Register(input)
+	result, err := h.authService.Register(c.UserContext(), input)
 	if err != nil {
-		if err.Error() == "user already exists" {
-			return utils.SendError(c, "USER_EXISTS", err.Error(), fiber.StatusConflict)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Registration failed", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Registration failed")
 	}
 
 	// Create refresh token
-	refreshToken, err := h.authService.CreateRefreshToken(result.User.ID)
+	refreshToken, err := h.authService.CreateRefreshToken(c.UserContext(), result.User.ID)
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to create refresh token", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to create refresh token")
 	}
 
 	h.setRefreshTokenCookie(c, refreshToken)
@@ -65,30 +69,30 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	// Validate input using go-playground/validator
 	validationErrors := utils.ValidateStruct(input)
 	if utils.HasValidationErrors(validationErrors) {
-		fieldErrors := make([]utils.FieldError, len(validationErrors))
-		for i, ve := range validationErrors {
-			fieldErrors[i] = utils.FieldError{
-				Field:   ve.Field,
-				Message: ve.Message,
-			}
-		}
-		return utils.SendError(c, "VALIDATION_ERROR", "Validation failed", fiber.StatusBadRequest, fieldErrors)
+		return utils.SendValidationError(c, validationErrors)
 	}
 
-	result, err := h.authService.Login(input)
+	result, err := h.authService.Login(c.UserContext(), input)
 	if err != nil {
-		if err.Error() == "invalid credentials" {
-			return utils.SendError(c, "INVALID_CREDENTIALS", err.Error(), fiber.StatusUnauthorized)
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginFailure).Inc()
+		if reason := loginFailure(err); reason != "" {
+			h.audit.Record(c.UserContext(), audit.Entry{
+				Action:     audit.ActionLoginFailed,
+				ActorEmail: input.Email,
+				Metadata:   map[string]string{"reason": reason},
+			})
 		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Login failed", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Login failed")
 	}
 
 	// Create refresh token
-	refreshToken, err := h.authService.CreateRefreshToken(result.User.ID)
+	refreshToken, err := h.authService.CreateRefreshToken(c.UserContext(), result.User.ID)
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to create refresh token", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to create refresh token")
 	}
 
+	metrics.LoginAttempts.WithLabelValues(metrics.LoginSuccess).Inc()
+	h.audit.Record(c.UserContext(), userEntry(audit.ActionLogin, result.User.ID, result.User.Email))
 	h.setRefreshTokenCookie(c, refreshToken)
 	return utils.SendSuccess(c, result)
 }
@@ -99,10 +103,16 @@ func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 		return utils.SendError(c, "NO_REFRESH_TOKEN", "No refresh token provided", fiber.StatusUnauthorized)
 	}
 
-	result, err := h.authService.RefreshAccessToken(refreshToken)
+	result, err := h.authService.RefreshAccessToken(c.UserContext(), refreshToken)
 	if err != nil {
 		h.clearRefreshTokenCookie(c)
-		return utils.SendError(c, "INVALID_REFRESH_TOKEN", "Invalid or expired refresh token", fiber.StatusUnauthorized)
+		if reason := services.RefreshRejection(err); reason != "" {
+			h.audit.Record(c.UserContext(), audit.Entry{
+				Action:   audit.ActionRefreshRejected,
+				Metadata: map[string]string{"reason": reason},
+			})
+		}
+		return apperror.OrInternal(err, "Failed to refresh token")
 	}
 
 	return utils.SendSuccess(c, result)
@@ -111,7 +121,10 @@ func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	refreshToken := c.Cookies(refreshTokenCookie)
 	if refreshToken != "" {
-		h.authService.RevokeRefreshToken(refreshToken)
+		if user, err := h.authService.RefreshTokenUser(c.UserContext(), refreshToken); err == nil {
+			h.audit.Record(c.UserContext(), userEntry(audit.ActionLogout, user.ID, user.Email))
+		}
+		h.authService.RevokeRefreshToken(c.UserContext(), refreshToken)
 	}
 
 	h.clearRefreshTokenCookie(c)
@@ -121,9 +134,9 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	userPayload := c.Locals("user").(*utils.JWTPayload)
 
-	user, err := h.authService.GetUserByID(userPayload.UserID)
+	user, err := h.authService.GetUserByID(c.UserContext(), userPayload.UserID)
 	if err != nil {
-		return utils.SendError(c, "USER_NOT_FOUND", "User not found", fiber.StatusNotFound)
+		return apperror.OrInternal(err, "Failed to get user")
 	}
 
 	return utils.SendSuccess(c, user.ToResponse())
@@ -142,9 +155,9 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 		return utils.SendValidationError(c, validationErrors)
 	}
 
-	user, err := h.authService.UpdateProfile(userPayload.UserID, input)
+	user, err := h.authService.UpdateProfile(c.UserContext(), userPayload.UserID, input)
 	if err != nil {
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to update profile", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to update profile")
 	}
 
 	return utils.SendSuccess(c, user.ToResponse())
@@ -163,24 +176,43 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		return utils.SendValidationError(c, validationErrors)
 	}
 
-	err := h.authService.ChangePassword(userPayload.UserID, input)
+	err := h.authService.ChangePassword(c.UserContext(), userPayload.UserID, input)
 	if err != nil {
-		if err.Error() == "current password is incorrect" {
-			return utils.SendError(c, "INVALID_PASSWORD", err.Error(), fiber.StatusBadRequest)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to change password", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to change password")
 	}
 
+	h.audit.Record(c.UserContext(), userEntry(audit.ActionPasswordChange, userPayload.UserID, userPayload.Email))
+
 	return utils.SendSuccess(c, fiber.Map{"message": "Password changed successfully"})
 }
 
+// userEntry is an audit entry for a user acting on their own account
+func userEntry(action, userID, email string) audit.Entry {
+	return audit.Entry{
+		Action:     action,
+		ActorID:    userID,
+		ActorEmail: email,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+	}
+}
+
+// loginFailure names why a login was refused, "" for internal errors
+func loginFailure(err error) string {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return "invalid_credentials"
+	case errors.Is(err, services.ErrAccountSuspended):
+		return "suspended"
+	}
+	return ""
+}
+
 func (h *AuthHandler) setRefreshTokenCookie(c *fiber.Ctx, token string) {
-	secure := os.Getenv("NODE_ENV") == "production"
+	secure := h.cookie.Secure
 	maxAge := utils.GetRefreshTokenExpiresDays() * 24 * 60 * 60
 
-	// COOKIE_SAMESITE: Lax (default), None (Telegram WebApp), Strict
-	// Use "None" for embedded contexts (Telegram WebApp, iframes)
-	sameSite := os.Getenv("COOKIE_SAMESITE")
+	sameSite := h.cookie.SameSite
 	if sameSite != "None" && sameSite != "Strict" {
 		sameSite = "Lax"
 	}
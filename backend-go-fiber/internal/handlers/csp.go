@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/services/csp"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxCSPReportBody bounds report bodies; a report-to batch of a few reports is well below
+const maxCSPReportBody = 64 * 1024
+
+// CSPReportHandler collects Content-Security-Policy violation reports
+type CSPReportHandler struct {
+	reportService *csp.ReportService
+}
+
+// NewCSPReportHandler creates a new CSP report handler
+func NewCSPReportHandler(reportService *csp.ReportService) *CSPReportHandler {
+	return &CSPReportHandler{
+		reportService: reportService,
+	}
+}
+
+// Report stores the violation reports sent by browsers (report-uri and report-to)
+// POST /api/csp-report
+func (h *CSPReportHandler) Report(c *fiber.Ctx) error {
+	if len(c.Body()) > maxCSPReportBody {
+		return utils.SendError(c, "REQUEST_ENTITY_TOO_LARGE", "Body too large", fiber.StatusRequestEntityTooLarge)
+	}
+
+	reports, err := csp.ParseReports(c.Body())
+	if err != nil {
+		if errors.Is(err, csp.ErrInvalidReport) {
+			return utils.SendError(c, "VALIDATION_ERROR", "Invalid CSP report", fiber.StatusBadRequest)
+		}
+		return err
+	}
+
+	for _, report := range reports {
+		if err := h.reportService.Record(c.UserContext(), report, c.Get(fiber.HeaderUserAgent)); err != nil {
+			return apperror.OrInternal(err, "Failed to store CSP report")
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
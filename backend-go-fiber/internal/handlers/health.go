@@ -1,19 +1,20 @@
 package handlers
 
 import (
+	"backend-go-fiber/internal/health"
 	"backend-go-fiber/internal/utils"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 type HealthHandler struct {
-	db *gorm.DB
+	checks *health.Registry
 }
 
-func NewHealthHandler(db *gorm.DB) *HealthHandler {
-	return &HealthHandler{db: db}
+// NewHealthHandler reports the checks of registry on /ready
+func NewHealthHandler(checks *health.Registry) *HealthHandler {
+	return &HealthHandler{checks: checks}
 }
 
 func (h *HealthHandler) Health(c *fiber.Ctx) error {
@@ -23,22 +24,17 @@ func (h *HealthHandler) Health(c *fiber.Ctx) error {
 	})
 }
 
+// Ready runs the registered checks. A failed critical check answers 503 so
+// that load balancers stop routing here; failed non-critical checks only
+// turn the status to "warn". The endpoint is public, so the causes of
+// failures are only logged.
 func (h *HealthHandler) Ready(c *fiber.Ctx) error {
-	// Check database connection
-	sqlDB, err := h.db.DB()
-	if err != nil {
-		return utils.SendError(c, "NOT_READY", "Service is not ready", fiber.StatusServiceUnavailable)
-	}
+	report := h.checks.Run(c.UserContext())
 
-	if err := sqlDB.Ping(); err != nil {
-		return utils.SendError(c, "NOT_READY", "Service is not ready", fiber.StatusServiceUnavailable)
+	status := fiber.StatusOK
+	if report.Status == health.StatusFail {
+		status = fiber.StatusServiceUnavailable
 	}
-
-	return utils.SendSuccess(c, fiber.Map{
-		"status": "ready",
-		"checks": fiber.Map{
-			"database": "ok",
-		},
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	})
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(report.Public(), health.ContentType)
 }
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OrganizationHandler handles organization, membership and invitation requests
+type OrganizationHandler struct {
+	orgService  *services.OrganizationService
+	authService *services.AuthService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgService *services.OrganizationService, authService *services.AuthService) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgService:  orgService,
+		authService: authService,
+	}
+}
+
+// List handles GET /api/orgs
+// Returns organizations the current user belongs to
+func (h *OrganizationHandler) List(c *fiber.Ctx) error {
+	userPayload := c.Locals("user").(*utils.JWTPayload)
+
+	orgs, err := h.orgService.ListForUser(c.UserContext(), userPayload.UserID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list organizations")
+	}
+
+	return utils.SendSuccess(c, orgs)
+}
+
+// Create handles POST /api/orgs
+// Creates an organization owned by the current user
+func (h *OrganizationHandler) Create(c *fiber.Ctx) error {
+	userPayload := c.Locals("user").(*utils.JWTPayload)
+
+	var input services.CreateOrganizationInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	if validationErrors := utils.ValidateStruct(input); len(validationErrors) > 0 {
+		return utils.SendValidationError(c, validationErrors)
+	}
+
+	org, err := h.orgService.Create(c.UserContext(), userPayload.UserID, input)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to create organization")
+	}
+
+	return utils.SendSuccess(c, org.ToResponse(), fiber.StatusCreated)
+}
+
+// Switch handles POST /api/orgs/switch
+// Changes the active organization and returns a new access token with the org claim
+func (h *OrganizationHandler) Switch(c *fiber.Ctx) error {
+	userPayload := c.Locals("user").(*utils.JWTPayload)
+
+	var input services.SwitchOrganizationInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	if validationErrors := utils.ValidateStruct(input); len(validationErrors) > 0 {
+		return utils.SendValidationError(c, validationErrors)
+	}
+
+	result, err := h.authService.SwitchOrganization(c.UserContext(), userPayload.UserID, input.OrganizationID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to switch organization")
+	}
+
+	return utils.SendSuccess(c, result)
+}
+
+// AcceptInvitation handles POST /api/orgs/invitations/accept
+// Joins the organization the invitation was issued for
+func (h *OrganizationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	userPayload := c.Locals("user").(*utils.JWTPayload)
+
+	var input services.AcceptInvitationInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	if validationErrors := utils.ValidateStruct(input); len(validationErrors) > 0 {
+		return utils.SendValidationError(c, validationErrors)
+	}
+
+	membership, err := h.orgService.AcceptInvitation(c.UserContext(), userPayload.UserID, input.Token)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to accept invitation")
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"organizationId": membership.OrganizationID,
+		"role":           membership.Role,
+	})
+}
+
+// ListMembers handles GET /api/orgs/current/members
+func (h *OrganizationHandler) ListMembers(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	members, err := h.orgService.ListMembers(c.UserContext(), membership.OrganizationID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list members")
+	}
+
+	return utils.SendSuccess(c, members)
+}
+
+// UpdateMember handles PUT /api/orgs/current/members/:userId
+// Changes the role of a member
+func (h *OrganizationHandler) UpdateMember(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	var input services.UpdateMemberRoleInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	if validationErrors := utils.ValidateStruct(input); len(validationErrors) > 0 {
+		return utils.SendValidationError(c, validationErrors)
+	}
+
+	member, err := h.orgService.UpdateMemberRole(c.UserContext(), membership.OrganizationID, membership, c.Params("userId"), input.Role)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to update member")
+	}
+
+	return utils.SendSuccess(c, fiber.Map{
+		"userId": member.UserID,
+		"role":   member.Role,
+	})
+}
+
+// RemoveMember handles DELETE /api/orgs/current/members/:userId
+// Removes a member (or lets a member leave the organization)
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	if err := h.orgService.RemoveMember(c.UserContext(), membership.OrganizationID, membership, c.Params("userId")); err != nil {
+		return apperror.OrInternal(err, "Failed to remove member")
+	}
+
+	return utils.SendSuccess(c, fiber.Map{"message": "Member removed successfully"})
+}
+
+// ListInvitations handles GET /api/orgs/current/invitations
+func (h *OrganizationHandler) ListInvitations(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	invitations, err := h.orgService.ListInvitations(c.UserContext(), membership.OrganizationID)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to list invitations")
+	}
+
+	return utils.SendSuccess(c, invitations)
+}
+
+// Invite handles POST /api/orgs/current/invitations
+// Sends an invitation email to join the active organization
+func (h *OrganizationHandler) Invite(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	var input services.InviteMemberInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+	}
+
+	if validationErrors := utils.ValidateStruct(input); len(validationErrors) > 0 {
+		return utils.SendValidationError(c, validationErrors)
+	}
+
+	invitation, err := h.orgService.Invite(c.UserContext(), membership.OrganizationID, membership, input)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to create invitation")
+	}
+
+	return utils.SendSuccess(c, invitation.ToResponse(), fiber.StatusCreated)
+}
+
+// RevokeInvitation handles DELETE /api/orgs/current/invitations/:id
+func (h *OrganizationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	membership := c.Locals("membership").(*models.Membership)
+
+	if err := h.orgService.RevokeInvitation(c.UserContext(), membership.OrganizationID, membership, c.Params("id")); err != nil {
+		return apperror.OrInternal(err, "Failed to revoke invitation")
+	}
+
+	return utils.SendSuccess(c, fiber.Map{"message": "Invitation revoked successfully"})
+}
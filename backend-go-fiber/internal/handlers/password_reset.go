@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"errors"
-
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/services/audit"
 	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,12 +12,14 @@ import (
 // PasswordResetHandler handles password reset requests
 type PasswordResetHandler struct {
 	service *services.PasswordResetService
+	audit   *audit.Log
 }
 
 // NewPasswordResetHandler creates a new password reset handler
-func NewPasswordResetHandler(service *services.PasswordResetService) *PasswordResetHandler {
+func NewPasswordResetHandler(service *services.PasswordResetService, auditLog *audit.Log) *PasswordResetHandler {
 	return &PasswordResetHandler{
 		service: service,
+		audit:   auditLog,
 	}
 }
 
@@ -51,11 +53,17 @@ func (h *PasswordResetHandler) ForgotPassword(c *fiber.Ctx) error {
 	}
 
 	// Request reset (always returns success for security)
-	if err := h.service.RequestReset(c.Context(), req.Email); err != nil {
+	if err := h.service.RequestReset(c.UserContext(), req.Email); err != nil {
 		// Log error but don't expose to user
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to process request", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to process request")
 	}
 
+	// Recorded whether or not the account exists, like the response
+	h.audit.Record(c.UserContext(), audit.Entry{
+		Action:   audit.ActionPasswordResetRequest,
+		Metadata: map[string]string{"email": req.Email},
+	})
+
 	// Always return success (don't reveal if email exists)
 	return utils.SendSuccess(c, fiber.Map{
 		"message": "If an account with that email exists, a password reset link has been sent.",
@@ -76,12 +84,9 @@ func (h *PasswordResetHandler) ValidateToken(c *fiber.Ctx) error {
 	}
 
 	// Validate token
-	user, err := h.service.ValidateToken(c.Context(), req.Token)
+	user, err := h.service.ValidateToken(c.UserContext(), req.Token)
 	if err != nil {
-		if errors.Is(err, services.ErrInvalidResetToken) {
-			return utils.SendError(c, "INVALID_TOKEN", "Invalid or expired reset token", fiber.StatusBadRequest)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to validate token", fiber.StatusInternalServerError)
+		return apperror.OrInternal(err, "Failed to validate token")
 	}
 
 	return utils.SendSuccess(c, fiber.Map{
@@ -104,13 +109,13 @@ func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
 	}
 
 	// Reset password
-	if err := h.service.ResetPassword(c.Context(), req.Token, req.NewPassword); err != nil {
-		if errors.Is(err, services.ErrInvalidResetToken) {
-			return utils.SendError(c, "INVALID_TOKEN", "Invalid or expired reset token", fiber.StatusBadRequest)
-		}
-		return utils.SendError(c, "INTERNAL_ERROR", "Failed to reset password", fiber.StatusInternalServerError)
+	user, err := h.service.ResetPassword(c.UserContext(), req.Token, req.NewPassword)
+	if err != nil {
+		return apperror.OrInternal(err, "Failed to reset password")
 	}
 
+	h.audit.Record(c.UserContext(), userEntry(audit.ActionPasswordReset, user.ID, user.Email))
+
 	return utils.SendSuccess(c, fiber.Map{
 		"message": "Password has been reset successfully. Please login with your new password.",
 	})
@@ -29,7 +29,7 @@ func (h *UploadHandler) UploadSingle(c *fiber.Ctx) error {
 	}
 
 	// Upload file
-	info, err := h.uploadService.UploadFile(c.Context(), fileHeader)
+	info, err := h.uploadService.UploadFile(c.UserContext(), fileHeader)
 	if err != nil {
 		return utils.SendError(c, "UPLOAD_ERROR", err.Error(), fiber.StatusBadRequest)
 	}
@@ -64,7 +64,7 @@ func (h *UploadHandler) UploadMultiple(c *fiber.Ctx) error {
 	var errors []fiber.Map
 
 	for _, fileHeader := range files {
-		info, err := h.uploadService.UploadFile(c.Context(), fileHeader)
+		info, err := h.uploadService.UploadFile(c.UserContext(), fileHeader)
 		if err != nil {
 			errors = append(errors, fiber.Map{
 				"filename": fileHeader.Filename,
@@ -93,7 +93,7 @@ func (h *UploadHandler) Delete(c *fiber.Ctx) error {
 		return utils.SendError(c, "VALIDATION_ERROR", "File key is required", fiber.StatusBadRequest)
 	}
 
-	if err := h.uploadService.DeleteFile(c.Context(), key); err != nil {
+	if err := h.uploadService.DeleteFile(c.UserContext(), key); err != nil {
 		return utils.SendError(c, "DELETE_ERROR", err.Error(), fiber.StatusInternalServerError)
 	}
 
@@ -0,0 +1,20 @@
+package health
+
+import (
+	"context"
+	"fmt"
+)
+
+// DiskFree fails when the filesystem holding path has less than minFree bytes available
+func DiskFree(path string, minFree uint64) func(ctx context.Context) error {
+	return func(context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s: %d MB free, at least %d MB required", path, free>>20, minFree>>20)
+		}
+		return nil
+	}
+}
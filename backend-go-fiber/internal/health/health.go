@@ -0,0 +1,231 @@
+// Package health runs the readiness checks that subsystems register and
+// reports them in the Health Check Response Format for HTTP APIs
+// (draft-inadarei-api-health-check, application/health+json).
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ContentType is the media type of Report
+const ContentType = "application/health+json"
+
+// Status of a check or of the whole report
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Component types reported as componentType
+const (
+	TypeDatastore = "datastore"
+	TypeComponent = "component"
+	TypeSystem    = "system"
+)
+
+// Check is a named readiness check
+type Check struct {
+	// Name is the component ("database", "storage", "disk:uploads")
+	Name string
+
+	// ComponentType is TypeDatastore, TypeComponent or TypeSystem
+	ComponentType string
+
+	// Critical checks make the instance not ready when they fail; others
+	// only turn the report to "warn"
+	Critical bool
+
+	// Timeout bounds one run (0 = DefaultTimeout)
+	Timeout time.Duration
+
+	// CacheTTL reuses the last result for this long, so that frequent
+	// probes don't hammer slow dependencies (0 = run on every request)
+	CacheTTL time.Duration
+
+	// Run returns nil when the component is healthy
+	Run func(ctx context.Context) error
+}
+
+// DefaultTimeout is used for checks without a Timeout
+const DefaultTimeout = 2 * time.Second
+
+// Result is one check in the report
+type Result struct {
+	ComponentType string    `json:"componentType,omitempty"`
+	ObservedValue float64   `json:"observedValue"`
+	ObservedUnit  string    `json:"observedUnit"`
+	Status        Status    `json:"status"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+}
+
+// Report is the readiness response. Checks are keyed "<name>:responseTime"
+// and observe the latency of the check in milliseconds.
+type Report struct {
+	Status Status              `json:"status"`
+	Checks map[string][]Result `json:"checks"`
+}
+
+// publicOutput replaces the output of failed checks in Public reports
+const publicOutput = "check failed"
+
+// Public returns a copy of the report for unauthenticated clients. Outputs
+// carry driver errors, hostnames and paths; they are logged when a check
+// fails and replaced with a generic message here.
+func (r Report) Public() Report {
+	public := Report{Status: r.Status, Checks: make(map[string][]Result, len(r.Checks))}
+	for key, results := range r.Checks {
+		redacted := make([]Result, len(results))
+		for i, result := range results {
+			if result.Output != "" {
+				result.Output = publicOutput
+			}
+			redacted[i] = result
+		}
+		public.Checks[key] = redacted
+	}
+	return public
+}
+
+type entry struct {
+	Check
+
+	mu     sync.Mutex
+	last   Result
+	cached time.Time
+}
+
+// Registry holds the checks
+type Registry struct {
+	mu      sync.RWMutex
+	entries []*entry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check. Names must be unique.
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultTimeout
+	}
+	if check.ComponentType == "" {
+		check.ComponentType = TypeComponent
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.Name == check.Name {
+			panic(fmt.Sprintf("health: check %q registered twice", check.Name))
+		}
+	}
+	r.entries = append(r.entries, &entry{Check: check})
+}
+
+// Run runs every check concurrently (or reuses its cached result) and
+// combines them: fail if a critical check failed, warn if another one did.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := r.entries
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.result(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusPass, Checks: make(map[string][]Result, len(entries))}
+	for i, e := range entries {
+		result := results[i]
+		report.Checks[e.Name+":responseTime"] = []Result{result}
+
+		switch {
+		case result.Status == StatusFail:
+			report.Status = StatusFail
+		case result.Status == StatusWarn && report.Status == StatusPass:
+			report.Status = StatusWarn
+		}
+	}
+	return report
+}
+
+// result returns the cached result or runs the check. Concurrent requests
+// wait for the running check instead of starting another one.
+func (e *entry) result(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.CacheTTL > 0 && !e.cached.IsZero() && time.Since(e.cached) < e.CacheTTL {
+		return e.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := run(ctx, e.Run)
+	latency := time.Since(start)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", e.Timeout)
+	}
+
+	result := Result{
+		ComponentType: e.ComponentType,
+		ObservedValue: float64(latency.Microseconds()) / 1000,
+		ObservedUnit:  "ms",
+		Status:        StatusPass,
+		Time:          start.UTC(),
+	}
+	if err != nil {
+		result.Status = StatusWarn
+		if e.Critical {
+			result.Status = StatusFail
+		}
+		result.Output = err.Error()
+		log.Ctx(ctx).Warn().Err(err).
+			Str("check", e.Name).
+			Bool("critical", e.Critical).
+			Msg("Health check failed")
+	}
+
+	e.last, e.cached = result, time.Now()
+	return result
+}
+
+// run calls fn but returns when ctx expires, for checks that ignore it
+func run(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func pass(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("down") }
+
+func TestRegistryStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		status Status
+	}{
+		{"all pass", []Check{
+			{Name: "a", Critical: true, Run: pass},
+			{Name: "b", Run: pass},
+		}, StatusPass},
+		{"non-critical failure", []Check{
+			{Name: "a", Critical: true, Run: pass},
+			{Name: "b", Run: failing},
+		}, StatusWarn},
+		{"critical failure", []Check{
+			{Name: "a", Critical: true, Run: failing},
+			{Name: "b", Run: failing},
+		}, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for _, c := range tt.checks {
+				r.Register(c)
+			}
+			report := r.Run(context.Background())
+			if report.Status != tt.status {
+				t.Errorf("status = %s, expected %s", report.Status, tt.status)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("checks = %v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestRegistryResult(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "database", ComponentType: TypeDatastore, Critical: true, Run: failing})
+
+	results := r.Run(context.Background()).Checks["database:responseTime"]
+	if len(results) != 1 {
+		t.Fatalf("checks = %v", results)
+	}
+	result := results[0]
+	if result.Status != StatusFail || result.Output != "down" || result.ComponentType != TypeDatastore {
+		t.Errorf("result = %+v", result)
+	}
+	if result.ObservedUnit != "ms" || result.Time.IsZero() {
+		t.Errorf("latency not reported: %+v", result)
+	}
+
+	// Causes are not shown to the public
+	public := r.Run(context.Background()).Public().Checks["database:responseTime"][0]
+	if public.Output != publicOutput || public.Status != StatusFail {
+		t.Errorf("public result = %+v", public)
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{
+		Name:     "stuck",
+		Critical: true,
+		Timeout:  20 * time.Millisecond,
+		Run: func(context.Context) error {
+			time.Sleep(time.Second) // ignores the context
+			return nil
+		},
+	})
+
+	start := time.Now()
+	report := r.Run(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Run() waited for a check past its timeout")
+	}
+	result := report.Checks["stuck:responseTime"][0]
+	if result.Status != StatusFail || result.Output != "timed out after 20ms" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	var runs atomic.Int32
+	r := NewRegistry()
+	r.Register(Check{
+		Name:     "smtp",
+		CacheTTL: time.Minute,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	})
+
+	// Concurrent probes share one run
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Run(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("check ran %d times, expected the cached result", n)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() accepted a duplicate name")
+		}
+	}()
+	r := NewRegistry()
+	r.Register(Check{Name: "database", Run: pass})
+	r.Register(Check{Name: "database", Run: pass})
+}
+
+func TestDiskFree(t *testing.T) {
+	dir := t.TempDir()
+	if err := DiskFree(dir, 1)(context.Background()); err != nil {
+		t.Errorf("DiskFree(1 byte) error = %v", err)
+	}
+	if err := DiskFree(dir, 1<<62)(context.Background()); err == nil {
+		t.Error("DiskFree(4 EiB) passed")
+	}
+	if err := DiskFree(dir+"/missing", 1)(context.Background()); err == nil {
+		t.Error("DiskFree(missing directory) passed")
+	}
+}
@@ -0,0 +1,23 @@
+package i18n
+
+import "context"
+
+type contextKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, normalize(locale))
+}
+
+// FromContext returns the locale stored in ctx or the default bundle's fallback
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return Default().Fallback()
+}
+
+// T translates an English message into the locale of ctx
+func T(ctx context.Context, message string) string {
+	return Default().T(FromContext(ctx), message)
+}
@@ -0,0 +1,294 @@
+// Package i18n translates user-facing messages: error messages, validation
+// messages and email templates.
+//
+// English is the source language: messages are written in English in the code
+// and catalogs map them to other languages. A catalog is a JSON file named after
+// its locale (ru.json); the built-in catalogs are embedded and a directory can be
+// loaded on top of them to add locales or override single entries.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// SourceLocale is the language messages are written in
+const SourceLocale = "en"
+
+//go:embed locales/*.json
+var builtin embed.FS
+
+// Catalog holds the translations of one locale
+type Catalog struct {
+	// Messages translates English message texts
+	Messages map[string]string `json:"messages"`
+
+	// Errors translates error codes; used for messages without their own entry
+	Errors map[string]string `json:"errors"`
+
+	// Validation holds one message per validator tag ("required", "min", ...)
+	// plus "default". {field} and {param} are replaced with the field label and
+	// the tag parameter.
+	Validation map[string]string `json:"validation"`
+
+	// Fields translates field names used in validation messages
+	Fields map[string]string `json:"fields"`
+
+	// Emails replaces the built-in email templates of the same name
+	Emails map[string]EmailTemplate `json:"emails"`
+}
+
+// EmailTemplate is a translated email template, same format as email.Template
+type EmailTemplate struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HTML    string `json:"html"`
+}
+
+// Bundle is a set of catalogs with a fallback locale
+type Bundle struct {
+	fallback string
+	catalogs map[string]*Catalog
+}
+
+// NewBundle creates a bundle containing only the source locale.
+// fallback is used when a request does not ask for a supported locale.
+func NewBundle(fallback string) *Bundle {
+	if fallback == "" {
+		fallback = SourceLocale
+	}
+	return &Bundle{
+		fallback: normalize(fallback),
+		catalogs: map[string]*Catalog{SourceLocale: {}},
+	}
+}
+
+// Load builds a bundle from the built-in catalogs and, if dir is not empty,
+// the *.json catalogs in dir
+func Load(dir, fallback string) (*Bundle, error) {
+	b := NewBundle(fallback)
+	if err := b.LoadFS(builtin, "locales"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := b.LoadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	if !b.Supports(b.fallback) {
+		return nil, fmt.Errorf("i18n: no catalog for default locale %q", b.fallback)
+	}
+	return b, nil
+}
+
+// LoadFS merges every <locale>.json file of dir into the bundle.
+// Entries of a later catalog override the ones already loaded.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+
+		b.merge(normalize(strings.TrimSuffix(path.Base(file), ".json")), &catalog)
+	}
+	return nil
+}
+
+func (b *Bundle) merge(locale string, src *Catalog) {
+	dst, ok := b.catalogs[locale]
+	if !ok {
+		dst = &Catalog{}
+		b.catalogs[locale] = dst
+	}
+
+	dst.Messages = mergeMap(dst.Messages, src.Messages)
+	dst.Errors = mergeMap(dst.Errors, src.Errors)
+	dst.Validation = mergeMap(dst.Validation, src.Validation)
+	dst.Fields = mergeMap(dst.Fields, src.Fields)
+	dst.Emails = mergeMap(dst.Emails, src.Emails)
+}
+
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// Fallback returns the locale used when negotiation finds no match
+func (b *Bundle) Fallback() string {
+	return b.fallback
+}
+
+// Locales returns the supported locales, sorted
+func (b *Bundle) Locales() []string {
+	locales := make([]string, 0, len(b.catalogs))
+	for locale := range b.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supports reports whether locale has a catalog
+func (b *Bundle) Supports(locale string) bool {
+	_, ok := b.catalogs[normalize(locale)]
+	return ok
+}
+
+// Match returns the supported locale for a language tag: an exact match
+// ("pt-br") or its primary language ("ru" for "ru-RU")
+func (b *Bundle) Match(tag string) (string, bool) {
+	tag = normalize(tag)
+	if _, ok := b.catalogs[tag]; ok {
+		return tag, true
+	}
+	if i := strings.IndexByte(tag, '-'); i > 0 {
+		if _, ok := b.catalogs[tag[:i]]; ok {
+			return tag[:i], true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the locale for an Accept-Language header value, honouring
+// q-values. Returns the fallback locale if nothing matches.
+func (b *Bundle) Negotiate(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if _, err := fmt.Sscanf(value, "%g", &q); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if locale, ok := b.Match(t.tag); ok {
+			return locale
+		}
+	}
+	return b.fallback
+}
+
+// T translates an English message, returning it unchanged if there is no translation
+func (b *Bundle) T(locale, message string) string {
+	if catalog := b.catalogs[normalize(locale)]; catalog != nil {
+		if translated, ok := catalog.Messages[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
+
+// Error translates an error message. The message itself is looked up first and
+// the generic translation of its code is used when the message has none.
+func (b *Bundle) Error(locale, code, message string) string {
+	catalog := b.catalogs[normalize(locale)]
+	if catalog == nil {
+		return message
+	}
+	if translated, ok := catalog.Messages[message]; ok {
+		return translated
+	}
+	if translated, ok := catalog.Errors[code]; ok {
+		return translated
+	}
+	return message
+}
+
+// Validation translates the message of a failed validator tag.
+// message is the English text, returned when the locale has no entry for tag.
+func (b *Bundle) Validation(locale, tag, field, param, message string) string {
+	catalog := b.catalogs[normalize(locale)]
+	if catalog == nil || tag == "" {
+		return message
+	}
+
+	format, ok := catalog.Validation[tag]
+	if !ok {
+		if format, ok = catalog.Validation["default"]; !ok {
+			return message
+		}
+	}
+
+	label := field
+	if translated, ok := catalog.Fields[field]; ok {
+		label = translated
+	}
+	return strings.NewReplacer("{field}", label, "{param}", param).Replace(format)
+}
+
+// Email returns the translated email template, if the locale has one
+func (b *Bundle) Email(locale, name string) (EmailTemplate, bool) {
+	if catalog := b.catalogs[normalize(locale)]; catalog != nil {
+		tmpl, ok := catalog.Emails[name]
+		return tmpl, ok
+	}
+	return EmailTemplate{}, false
+}
+
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+var defaultBundle atomic.Pointer[Bundle]
+
+func init() {
+	b, err := Load("", SourceLocale)
+	if err != nil {
+		panic(err)
+	}
+	defaultBundle.Store(b)
+}
+
+// Default returns the bundle used by the application
+func Default() *Bundle {
+	return defaultBundle.Load()
+}
+
+// SetDefault replaces the bundle used by the application
+func SetDefault(b *Bundle) {
+	defaultBundle.Store(b)
+}
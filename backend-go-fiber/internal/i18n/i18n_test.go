@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNegotiate(t *testing.T) {
+	b, err := Load("", "en")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"ru", "ru"},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", "ru"},
+		{"en-US,en;q=0.9,ru;q=0.8", "en"},
+		{"de-DE,de;q=0.9", "en"},
+		{"de;q=0.9,ru;q=0.5", "ru"},
+		{"en;q=0.1,ru", "ru"},
+		{"ru;q=0,en", "en"},
+		{"*", "en"},
+	}
+	for _, tt := range tests {
+		if got := b.Negotiate(tt.header); got != tt.expected {
+			t.Errorf("Negotiate(%q) = %q, expected %q", tt.header, got, tt.expected)
+		}
+	}
+
+	b, _ = Load("", "ru")
+	if got := b.Negotiate("de"); got != "ru" {
+		t.Errorf("Expected fallback ru, got %q", got)
+	}
+
+	if _, err := Load("", "fr"); err == nil {
+		t.Error("Expected error for default locale without catalog")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	b, _ := Load("", "en")
+
+	if got := b.Error("en", "NOT_FOUND", "User not found"); got != "User not found" {
+		t.Errorf("Source locale must not be translated, got %q", got)
+	}
+	if got := b.Error("ru", "NOT_FOUND", "User not found"); got != "Пользователь не найден" {
+		t.Errorf("Expected message translation, got %q", got)
+	}
+	if got := b.Error("ru", "NOT_FOUND", "Widget not found"); got != "Ресурс не найден" {
+		t.Errorf("Expected error code translation, got %q", got)
+	}
+	if got := b.Error("ru", "MAINTENANCE", "Back at 10:00"); got != "Back at 10:00" {
+		t.Errorf("Expected untranslated message, got %q", got)
+	}
+
+	if got := b.Validation("ru", "min", "password", "8", "password must be at least 8 characters"); got != "Поле «Пароль» должно содержать не менее 8 символов" {
+		t.Errorf("Unexpected validation message %q", got)
+	}
+	if got := b.Validation("ru", "hexcolor", "color", "", "color is invalid"); got != "Поле «color» заполнено некорректно" {
+		t.Errorf("Expected default validation message, got %q", got)
+	}
+	if got := b.Validation("en", "min", "password", "8", "password must be at least 8 characters"); got != "password must be at least 8 characters" {
+		t.Errorf("Unexpected validation message %q", got)
+	}
+
+	if _, ok := b.Email("ru", "password_reset"); !ok {
+		t.Error("Expected ru password_reset email")
+	}
+	if _, ok := b.Email("en", "password_reset"); ok {
+		t.Error("English emails come from the sender's templates")
+	}
+}
+
+func TestLoadFSOverrides(t *testing.T) {
+	b, _ := Load("", "en")
+	err := b.LoadFS(fstest.MapFS{
+		"ru.json":    {Data: []byte(`{"messages": {"User not found": "Нет такого пользователя"}}`)},
+		"uk_UA.json": {Data: []byte(`{"messages": {"User not found": "Користувача не знайдено"}}`)},
+	}, ".")
+	if err != nil {
+		t.Fatalf("LoadFS failed: %v", err)
+	}
+
+	if got := b.T("ru", "User not found"); got != "Нет такого пользователя" {
+		t.Errorf("Expected overridden message, got %q", got)
+	}
+	if got := b.T("ru", "Organization not found"); got != "Организация не найдена" {
+		t.Errorf("Expected built-in message to survive the merge, got %q", got)
+	}
+	if got := b.Negotiate("uk-UA"); got != "uk-ua" {
+		t.Errorf("Expected added locale, got %q", got)
+	}
+
+	err = b.LoadFS(fstest.MapFS{"ru.json": {Data: []byte(`{"messages": [}`)}}, ".")
+	if err == nil {
+		t.Error("Expected error for invalid catalog")
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != Default().Fallback() {
+		t.Errorf("Expected fallback locale, got %q", got)
+	}
+
+	ctx = WithLocale(ctx, "ru")
+	if got := T(ctx, "7 days"); got != "7 дней" {
+		t.Errorf("Expected translation from context locale, got %q", got)
+	}
+}
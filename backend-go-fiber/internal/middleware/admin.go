@@ -19,10 +19,15 @@ func AdminOnly(db *gorm.DB) fiber.Handler {
 
 		// Fetch user from database to check role
 		var user models.User
-		if err := db.First(&user, "id = ?", payload.UserID).Error; err != nil {
+		if err := db.WithContext(c.UserContext()).First(&user, "id = ?", payload.UserID).Error; err != nil {
 			return utils.SendError(c, "UNAUTHORIZED", "User not found", fiber.StatusUnauthorized)
 		}
 
+		// Suspended admins lose access immediately
+		if user.IsSuspended() {
+			return utils.SendError(c, "ACCOUNT_SUSPENDED", "Your account has been suspended", fiber.StatusForbidden)
+		}
+
 		// Check if user is admin
 		if !user.IsAdmin() {
 			return utils.SendError(c, "FORBIDDEN", "Admin access required", fiber.StatusForbidden)
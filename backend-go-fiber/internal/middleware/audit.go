@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditMiddleware stores the client IP, request ID and user agent in the
+// request context for audit.Log.Record. trusted are the TRUSTED_PROXIES ranges.
+// Must run after RequestIDMiddleware.
+func AuditMiddleware(trusted utils.IPList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID, _ := c.Locals("requestId").(string)
+		c.SetUserContext(audit.WithRequest(c.UserContext(), audit.Request{
+			IP:        ClientIP(c, trusted),
+			RequestID: requestID,
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}))
+		return c.Next()
+	}
+}
@@ -3,9 +3,12 @@ package middleware
 import (
 	"strings"
 
+	"backend-go-fiber/internal/i18n"
+	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 func AuthMiddleware() fiber.Handler {
@@ -24,6 +27,35 @@ func AuthMiddleware() fiber.Handler {
 		}
 
 		c.Locals("user", payload)
+
+		// The user's language preference wins over Accept-Language
+		if locale, ok := i18n.Default().Match(payload.Locale); ok {
+			utils.SetLocale(c, locale)
+		}
+
+		return c.Next()
+	}
+}
+
+// ActiveAccount middleware rejects requests from deleted or suspended users.
+// Access tokens are stateless, so without this check a suspended user keeps
+// working until the token expires. Must be used after AuthMiddleware.
+func ActiveAccount(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		payload, ok := c.Locals("user").(*utils.JWTPayload)
+		if !ok || payload == nil {
+			return utils.SendError(c, "UNAUTHORIZED", "Authentication required", fiber.StatusUnauthorized)
+		}
+
+		var user models.User
+		if err := db.WithContext(c.UserContext()).Select("id", "is_active", "suspended_until").First(&user, "id = ?", payload.UserID).Error; err != nil {
+			return utils.SendError(c, "UNAUTHORIZED", "User not found", fiber.StatusUnauthorized)
+		}
+
+		if user.IsSuspended() {
+			return utils.SendError(c, "ACCOUNT_SUSPENDED", "Your account has been suspended", fiber.StatusForbidden)
+		}
+
 		return c.Next()
 	}
 }
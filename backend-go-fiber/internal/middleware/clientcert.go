@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/tlsconfig"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// ClientCertMiddleware requires a verified client certificate (mTLS) on the
+// tls.client_auth_routes. The certificate is an additional requirement, not a
+// login: the route's own authentication still applies. The identity it maps
+// to is recorded with audit entries of the request; other routes record it
+// when the client sent a known certificate anyway.
+// Must run after AuditMiddleware.
+func ClientCertMiddleware(store *tlsconfig.Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		auth := store.ClientAuth()
+		required := auth.Requires(apiversion.CanonicalPath(c.Path()))
+
+		cert, err := tlsconfig.VerifiedClient(c.Context().TLSConnectionState())
+		if err != nil {
+			if required {
+				return utils.SendError(c, "CLIENT_CERTIFICATE_REQUIRED", "A valid client certificate is required", fiber.StatusUnauthorized)
+			}
+			return c.Next()
+		}
+
+		identity, ok := auth.Identity(cert)
+		if !ok {
+			if required {
+				log.Ctx(c.UserContext()).Warn().
+					Str("subject", cert.Subject.String()).
+					Str("path", c.Path()).
+					Msg("Client certificate subject is not mapped to an identity")
+				return utils.SendError(c, "FORBIDDEN", "Client certificate is not allowed", fiber.StatusForbidden)
+			}
+			return c.Next()
+		}
+
+		req := audit.RequestFrom(c.UserContext())
+		req.ClientIdentity = identity
+		c.SetUserContext(audit.WithRequest(c.UserContext(), req))
+		return c.Next()
+	}
+}
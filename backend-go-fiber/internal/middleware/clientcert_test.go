@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/services/audit"
+	"backend-go-fiber/internal/tlsconfig"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testCert issues a certificate for cn signed by ca (self-signed CA if nil)
+func testCert(t *testing.T, cn string, ca *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parent, parentKey := template, any(key)
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		parent, parentKey = ca.Leaf, ca.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	ca := testCert(t, "Test CA", nil)
+	server := testCert(t, "server", &ca)
+	billing := testCert(t, "billing", &ca)
+	unknown := testCert(t, "unknown", &ca)
+	rogueCA := testCert(t, "Rogue CA", nil)
+	rogue := testCert(t, "billing", &rogueCA)
+
+	dir := t.TempDir()
+	files := tlsconfig.Files{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(server.PrivateKey.(*ecdsa.PrivateKey))
+	writePEM(t, files.CertFile, "CERTIFICATE", server.Certificate[0])
+	writePEM(t, files.KeyFile, "EC PRIVATE KEY", keyDER)
+	writePEM(t, files.ClientCAFile, "CERTIFICATE", ca.Certificate[0])
+
+	certs, err := tlsconfig.Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := tlsconfig.NewStore(certs, tlsconfig.ClientAuth{
+		Routes:     []string{"/api/admin/*"},
+		Identities: map[string]string{"billing": "service:billing"},
+	})
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(AuditMiddleware(nil))
+	app.Use(ClientCertMiddleware(store))
+	app.Get("/*", func(c *fiber.Ctx) error {
+		return c.SendString(audit.RequestFrom(c.UserContext()).ClientIdentity)
+	})
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(tls.NewListener(ln, store.ServerConfig()))
+	defer app.Shutdown()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	tests := []struct {
+		name     string
+		path     string
+		cert     *tls.Certificate
+		status   int
+		identity string
+	}{
+		{"public route without certificate", "/api/auth/me", nil, 200, ""},
+		{"public route with certificate", "/api/auth/me", &billing, 200, "service:billing"},
+		{"admin without certificate", "/api/admin/users", nil, 401, ""},
+		{"admin with mapped certificate", "/api/v1/admin/users", &billing, 200, "service:billing"},
+		{"admin with unmapped certificate", "/api/admin/users", &unknown, 403, ""},
+		{"mixed case admin without certificate", "/API/Admin/users", nil, 401, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &tls.Config{RootCAs: roots}
+			if tt.cert != nil {
+				config.Certificates = []tls.Certificate{*tt.cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+			resp, err := client.Get("https://" + ln.Addr().String() + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, expected %d: %s", resp.StatusCode, tt.status, body)
+			}
+			if tt.status == 200 && string(body) != tt.identity {
+				t.Errorf("identity = %q, expected %q", body, tt.identity)
+			}
+		})
+	}
+
+	// Certificates from other CAs are never verified: the handshake fails or
+	// the client leaves them out
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{rogue},
+	}}}
+	if resp, err := client.Get("https://" + ln.Addr().String() + "/api/admin/users"); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != 401 {
+			t.Errorf("certificate from another CA: status = %d, expected 401", resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"backend-go-fiber/internal/services/cors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	policies := cors.NewPolicies(map[string]cors.Policy{
+		cors.GroupPublic: {Origins: []string{"https://app.example.com"}, AllowCredentials: true},
+		cors.GroupAPIKey: {Origins: []string{"*"}, MaxAge: 3600},
+		cors.GroupAdmin:  {Origins: []string{"https://*.admin.example.com"}, AllowCredentials: true, MaxAge: -1},
+	})
+
+	app := fiber.New()
+	app.Use(CORSMiddleware(policies))
+	app.All("/api/*", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		headers     map[string]string
+		allowOrigin string
+		credentials string
+		maxAge      string
+		status      int
+	}{
+		{"public simple", "GET", "/api/auth/me", "https://app.example.com", nil, "https://app.example.com", "true", "", 200},
+		{"public other origin", "GET", "/api/auth/me", "https://evil.com", nil, "", "", "", 200},
+		{"public preflight", "OPTIONS", "/api/auth/login", "https://app.example.com",
+			map[string]string{"Access-Control-Request-Method": "POST"}, "https://app.example.com", "true", "600", 204},
+		{"preflight rejected", "OPTIONS", "/api/auth/login", "https://evil.com",
+			map[string]string{"Access-Control-Request-Method": "POST"}, "", "", "", 204},
+		{"api key", "GET", "/api/orgs", "https://evil.com",
+			map[string]string{"X-API-Key": "k"}, "https://evil.com", "", "", 200},
+		{"api key preflight", "OPTIONS", "/api/orgs", "https://tool.dev",
+			map[string]string{"Access-Control-Request-Method": "GET", "Access-Control-Request-Headers": "content-type, x-api-key"}, "https://tool.dev", "", "3600", 204},
+		{"admin", "OPTIONS", "/api/v1/admin/users", "https://ops.admin.example.com",
+			map[string]string{"Access-Control-Request-Method": "DELETE"}, "https://ops.admin.example.com", "true", "0", 204},
+		{"admin rejects public origin", "GET", "/api/admin/users", "https://app.example.com", nil, "", "", "", 200},
+		{"plain OPTIONS is not a preflight", "OPTIONS", "/api/auth/me", "https://app.example.com", nil, "https://app.example.com", "true", "", 200},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Origin", tt.origin)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: expected Allow-Origin %q, got %q", tt.name, tt.allowOrigin, got)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+			t.Errorf("%s: expected Allow-Credentials %q, got %q", tt.name, tt.credentials, got)
+		}
+		if got := resp.Header.Get("Access-Control-Max-Age"); got != tt.maxAge {
+			t.Errorf("%s: expected Max-Age %q, got %q", tt.name, tt.maxAge, got)
+		}
+	}
+
+	// Policy changes apply to the next request
+	policies.Set(map[string]cors.Policy{cors.GroupPublic: {Origins: []string{"https://*.partner.io"}}})
+	req := httptest.NewRequest("GET", "/api/admin/users", nil)
+	req.Header.Set("Origin", "https://shop.partner.io")
+	resp, _ := app.Test(req)
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://shop.partner.io" {
+		t.Errorf("Expected reloaded policy to apply, got %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorMiddleware writes errors returned by handlers as responses with the
+// app's ErrorHandler. It is the only place the error handler runs for a
+// request, so it must be registered inside tracing, access log and metrics:
+// they see the final status, and the access log reads the cause from
+// Locals("error").
+func ErrorMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err == nil {
+			return nil
+		}
+
+		c.Locals("error", err)
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return nil
+	}
+}
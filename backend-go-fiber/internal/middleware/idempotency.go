@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/services/idempotency"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IdempotencyKeyHeader lets clients retry mutating requests safely
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	// maxIdempotencyKeyLength bounds client keys (UUIDs are 36 characters)
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyLockTTL is how long a key stays claimed when the request
+	// never completes (crashed process); it must exceed the write timeout
+	idempotencyLockTTL = time.Minute
+)
+
+// Idempotency makes a route safe to retry with an Idempotency-Key header.
+// The first request runs the handler and its response is stored for ttl;
+// a retry with the same key and payload replays it (Idempotent-Replayed: true),
+// while the first request is still running duplicates get 409 and a key
+// reused with a different payload gets 422.
+//
+// Keys are scoped to the authenticated user (anonymous requests share one
+// scope) and the route, so register it after AuthMiddleware where used.
+// Server errors and errors returned by the handler are not stored, the request
+// can be retried with the same key.
+// Set-Cookie is not replayed - cookies carry tokens that are not persisted.
+// Stored responses are not encrypted, so don't use it on routes that respond
+// with credentials.
+//
+// Request bodies are fingerprinted with an HMAC keyed by secret, so a leaked
+// store doesn't allow guessing bodies (passwords) from their hashes.
+func Idempotency(store idempotency.Store, ttl time.Duration, secret []byte) fiber.Handler {
+	// Derived, so the server secret isn't used directly for a second purpose
+	fingerprintKey := hmacSum(secret, "idempotency-fingerprint")
+
+	return func(c *fiber.Ctx) error {
+		clientKey := c.Get(IdempotencyKeyHeader)
+		if clientKey == "" {
+			return c.Next()
+		}
+		if len(clientKey) > maxIdempotencyKeyLength {
+			return utils.SendError(c, "VALIDATION_ERROR", "Idempotency-Key must be at most 255 characters", fiber.StatusBadRequest)
+		}
+
+		scope := "anonymous"
+		if payload, ok := c.Locals("user").(*utils.JWTPayload); ok && payload != nil {
+			scope = "user:" + payload.UserID
+		}
+		key := digest(scope, c.Method(), apiversion.CanonicalPath(c.Path()), clientKey)
+
+		fingerprint, err := requestFingerprint(c, fingerprintKey)
+		if err != nil {
+			return utils.SendError(c, "VALIDATION_ERROR", "Invalid request body", fiber.StatusBadRequest)
+		}
+
+		ctx := c.UserContext()
+		existing, err := store.Reserve(ctx, key, fingerprint, idempotencyLockTTL)
+		if err != nil {
+			utils.Logger(c).Error().Err(err).Msg("Idempotency store error")
+			return utils.SendError(c, "INTERNAL_ERROR", "Internal server error", fiber.StatusInternalServerError)
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				return utils.SendError(c, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request", fiber.StatusUnprocessableEntity)
+			case !existing.Completed:
+				return utils.SendError(c, "IDEMPOTENCY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed", fiber.StatusConflict)
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.Status).Send(existing.Body)
+		}
+
+		// Returned errors are written later by ErrorMiddleware, there is no response to store
+		err = c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			if releaseErr := store.Release(ctx, key); releaseErr != nil {
+				utils.Logger(c).Error().Err(releaseErr).Msg("Failed to release idempotency key")
+			}
+			return err
+		}
+
+		resp := idempotency.Response{
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		if completeErr := store.Complete(ctx, key, resp, ttl); completeErr != nil {
+			utils.Logger(c).Error().Err(completeErr).Msg("Failed to store idempotent response")
+		}
+
+		return nil
+	}
+}
+
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hmacSum(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, message)
+	return mac.Sum(nil)
+}
+
+// requestFingerprint hashes what makes a request "the same request".
+// Multipart bodies are hashed by their fields and file contents, because
+// clients generate a new boundary on every retry.
+func requestFingerprint(c *fiber.Ctx, key []byte) (string, error) {
+	h := hmac.New(sha256.New, key)
+	// Same path as the store key, so a retry under another API version replays
+	path := apiversion.CanonicalPath(c.Path())
+	io.WriteString(h, c.Method()+" "+path+"?"+string(c.Request().URI().QueryString())+"\x00")
+
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		h.Write(c.Body())
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range sortedKeys(form.Value) {
+		for _, value := range form.Value[name] {
+			io.WriteString(h, "field\x00"+name+"\x00"+value+"\x00")
+		}
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, file := range form.File[name] {
+			io.WriteString(h, "file\x00"+name+"\x00"+file.Filename+"\x00")
+			if err := hashFile(h, file); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h hash.Hash, file *multipart.FileHeader) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/services/idempotency"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const testIdempotencySecret = "test-secret-that-is-at-least-32-characters-long"
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	app := fiber.New()
+	app.Use(Idempotency(idempotency.NewMemoryStore(), time.Hour, []byte(testIdempotencySecret)))
+	items := func(c *fiber.Ctx) error {
+		calls++
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls})
+	}
+	app.Post("/items", items)
+	app.Post("/api/items", items)
+	app.Post("/api/v1/items", items)
+	app.Post("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Post("/fail", func(c *fiber.Ctx) error {
+		calls++
+		return fiber.NewError(fiber.StatusServiceUnavailable, "try later")
+	})
+
+	post := func(path, key, body string) (int, string, string) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(respBody), resp.Header.Get("Idempotent-Replayed")
+	}
+
+	status, first, _ := post("/items", "key-1", `{"name":"a"}`)
+	if status != fiber.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+
+	status, replay, replayed := post("/items", "key-1", `{"name":"a"}`)
+	if status != fiber.StatusCreated || replay != first || replayed != "true" {
+		t.Errorf("Expected replay of %s, got %d %s (replayed=%q)", first, status, replay, replayed)
+	}
+	if calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls)
+	}
+
+	if status, _, _ := post("/items", "key-1", `{"name":"b"}`); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for reused key with different body, got %d", status)
+	}
+
+	post("/items", "", `{"name":"a"}`)
+	post("/items", "", `{"name":"a"}`)
+	if calls != 3 {
+		t.Errorf("Expected requests without key to run every time, ran %d times", calls)
+	}
+
+	// A retry under another API version is the same request, another query is not
+	calls = 0
+	post("/api/items", "key-4", `{"name":"a"}`)
+	if status, _, replayed := post("/api/v1/items", "key-4", `{"name":"a"}`); status != fiber.StatusCreated || replayed != "true" {
+		t.Errorf("Expected versioned retry to replay, got %d (replayed=%q)", status, replayed)
+	}
+	if status, _, _ := post("/api/items?dryRun=true", "key-4", `{"name":"a"}`); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for reused key with different query, got %d", status)
+	}
+
+	// Server errors are not stored
+	calls = 0
+	post("/fail", "key-2", "")
+	post("/fail", "key-2", "")
+	if calls != 2 {
+		t.Errorf("Expected failed request to be retried, ran %d times", calls)
+	}
+
+	// Concurrent duplicate while the first request is running
+	done := make(chan int)
+	go func() {
+		status, _, _ := post("/slow", "key-3", "")
+		done <- status
+	}()
+	<-started
+	if status, _, _ := post("/slow", "key-3", ""); status != fiber.StatusConflict {
+		t.Errorf("Expected 409 for concurrent duplicate, got %d", status)
+	}
+	close(release)
+	if status := <-done; status != fiber.StatusCreated {
+		t.Errorf("Expected first request to succeed, got %d", status)
+	}
+}
+
+func TestIdempotencyMultipartFingerprint(t *testing.T) {
+	calls := 0
+	app := fiber.New()
+	app.Use(Idempotency(idempotency.NewMemoryStore(), time.Hour, []byte(testIdempotencySecret)))
+	app.Post("/upload", func(c *fiber.Ctx) error {
+		calls++
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	upload := func(boundary, content string) int {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		w.SetBoundary(boundary)
+		part, _ := w.CreateFormFile("file", "photo.jpg")
+		part.Write([]byte(content))
+		w.Close()
+
+		req := httptest.NewRequest("POST", "/upload", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		req.Header.Set(IdempotencyKeyHeader, "upload-1")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	upload("boundary-one", "image bytes")
+	if status := upload("boundary-two", "image bytes"); status != fiber.StatusCreated || calls != 1 {
+		t.Errorf("Expected retry with new boundary to replay, got %d after %d calls", status, calls)
+	}
+	if status := upload("boundary-three", "other bytes"); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for different file, got %d", status)
+	}
+}
+
+func TestIdempotencyFingerprintKeyed(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	post := func(secret string) int {
+		app := fiber.New()
+		app.Use(Idempotency(store, time.Hour, []byte(secret)))
+		app.Post("/items", func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusCreated)
+		})
+
+		req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	post(testIdempotencySecret)
+	if status := post(testIdempotencySecret); status != fiber.StatusCreated {
+		t.Errorf("Expected replay with the same secret, got %d", status)
+	}
+	// The stored fingerprint depends on the secret, not only on the body
+	if status := post("another-secret"); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("Expected fingerprint mismatch with another secret, got %d", status)
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strings"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/services/ipaccess"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// IPAccessMiddleware rejects clients that the ip_access_rules setting denies
+// or leaves out of an allow list. trusted are the TRUSTED_PROXIES ranges.
+func IPAccessMiddleware(rules *ipaccess.Rules, trusted utils.IPList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if rules.Len() == 0 {
+			return c.Next()
+		}
+
+		ip := ClientIP(c, trusted)
+		rule, ok := rules.Check(apiversion.CanonicalPath(c.Path()), ip)
+		if ok {
+			return c.Next()
+		}
+
+		log.Ctx(c.UserContext()).Warn().
+			Str("ip", ip).
+			Str("rule", rule.Name).
+			Str("path", c.Path()).
+			Msg("Request blocked by IP access rule")
+		return utils.SendError(c, "FORBIDDEN", "Access denied from your network", fiber.StatusForbidden)
+	}
+}
+
+// ClientIP returns the address of the client for access decisions.
+//
+// c.IP() returns the first X-Forwarded-For entry, which the client controls:
+// proxies append to the header. Behind trusted proxies the header is walked
+// from the right instead, skipping trusted addresses, so the result is the
+// last hop no trusted proxy vouches for.
+func ClientIP(c *fiber.Ctx, trusted utils.IPList) string {
+	remote := c.Context().RemoteIP().String()
+	header := c.App().Config().ProxyHeader
+	if header == "" || !trusted.Contains(remote) {
+		return remote
+	}
+
+	hops := strings.Split(c.Get(header), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !trusted.Contains(hop) {
+			return hop
+		}
+		remote = hop
+	}
+	// Every hop is a trusted proxy: the request originates from inside
+	return remote
+}
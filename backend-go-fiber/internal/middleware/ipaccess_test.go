@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"backend-go-fiber/internal/services/ipaccess"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIPAccessMiddleware(t *testing.T) {
+	// app.Test connects from 0.0.0.0
+	trusted, _ := utils.ParseIPList("0.0.0.0, 10.0.0.0/8")
+	rules := ipaccess.NewRules()
+	err := rules.ApplySetting(`[
+		{"name": "abuse", "route": "*", "deny": ["203.0.113.0/24"]},
+		{"name": "office", "route": "/api/admin/*", "allow": ["198.51.100.0/24"]}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New(fiber.Config{
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          []string{"0.0.0.0", "10.0.0.0/8"},
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+	})
+	app.Use(IPAccessMiddleware(rules, trusted))
+	app.Get("/*", func(c *fiber.Ctx) error {
+		return c.SendString(ClientIP(c, trusted))
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		forwarded string
+		status    int
+	}{
+		{"no proxy header", "/api/auth/me", "", 200},
+		{"denied network", "/api/auth/me", "203.0.113.7", 403},
+		{"denied behind two proxies", "/api/auth/me", "203.0.113.7, 10.1.2.3", 403},
+		{"spoofed first hop", "/api/auth/me", "198.51.100.1, 203.0.113.7", 403},
+		{"office", "/api/v1/admin/users", "198.51.100.20", 200},
+		{"spoofed office", "/api/admin/users", "198.51.100.20, 192.0.2.1", 403},
+		{"outside office", "/api/admin/users", "192.0.2.1", 403},
+		{"mixed case outside office", "/API/Admin/users", "192.0.2.1", 403},
+		{"mixed case versioned outside office", "/Api/V1/ADMIN/users", "192.0.2.1", 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, expected %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestClientIPUntrustedPeer(t *testing.T) {
+	trusted, _ := utils.ParseIPList("10.0.0.0/8")
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(ClientIP(c, trusted))
+	})
+
+	// The peer (0.0.0.0) is not a trusted proxy, so its header is ignored
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, 32)
+	n, _ := resp.Body.Read(body)
+	if got := string(body[:n]); got != "0.0.0.0" {
+		t.Errorf("ClientIP() = %q, expected the peer address", got)
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"backend-go-fiber/internal/i18n"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocaleMiddleware negotiates the response language from Accept-Language.
+// AuthMiddleware replaces it with the user's preferred locale, if they set one.
+func LocaleMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Vary(fiber.HeaderAcceptLanguage)
+		utils.SetLocale(c, i18n.Default().Negotiate(c.Get(fiber.HeaderAcceptLanguage)))
+		return c.Next()
+	}
+}
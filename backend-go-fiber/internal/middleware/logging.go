@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sensitiveHeaders are never written to the access log
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+	"proxy-authorization": true,
+}
+
+// AccessLogConfig configures AccessLogMiddleware
+type AccessLogConfig struct {
+	// Logger is the base logger (default: global zerolog logger)
+	Logger *zerolog.Logger
+
+	// Sample2xx logs only every Nth successful response (0 or 1 = log all).
+	// Redirects, client and server errors are always logged.
+	Sample2xx uint32
+
+	// LogHeaders adds request headers to each entry (sensitive ones redacted)
+	LogHeaders bool
+
+	// TrustedProxies may set X-Forwarded-For; the logged ip is taken from it
+	// only behind them
+	TrustedProxies utils.IPList
+}
+
+// AccessLogMiddleware writes one structured log entry per request and provides
+// the request-scoped logger (utils.Logger(c), log.Ctx(c.UserContext())).
+// Must run after RequestIDMiddleware and TracingMiddleware, and outside
+// ErrorMiddleware, which hands it the cause of error responses.
+func AccessLogMiddleware(cfg AccessLogConfig) fiber.Handler {
+	base := cfg.Logger
+	if base == nil {
+		base = &log.Logger
+	}
+
+	var sampler zerolog.Sampler
+	if cfg.Sample2xx > 1 {
+		sampler = &zerolog.BasicSampler{N: cfg.Sample2xx}
+	}
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		// Request-scoped logger for handlers and services
+		logCtx := base.With()
+		if requestID, ok := c.Locals("requestId").(string); ok {
+			logCtx = logCtx.Str("request_id", requestID)
+		}
+		if spanCtx := trace.SpanContextFromContext(c.UserContext()); spanCtx.IsValid() {
+			logCtx = logCtx.Str("trace_id", spanCtx.TraceID().String())
+		}
+		logger := logCtx.Logger()
+		c.Locals("logger", &logger)
+		c.SetUserContext(logger.WithContext(c.UserContext()))
+
+		err := c.Next()
+		cause := err
+		if cause == nil {
+			// Handled by ErrorMiddleware, which already wrote the response
+			cause, _ = c.Locals("error").(error)
+		}
+
+		status := c.Response().StatusCode()
+
+		entryLogger := logger
+		var event *zerolog.Event
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			event = entryLogger.Error()
+		case status >= fiber.StatusBadRequest:
+			event = entryLogger.Warn()
+		default:
+			if sampler != nil && status < fiber.StatusMultipleChoices {
+				entryLogger = entryLogger.Sample(sampler)
+			}
+			event = entryLogger.Info()
+		}
+
+		event = event.
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Str("route", c.Route().Path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Int("bytes_in", len(c.Request().Body())).
+			Int("bytes_out", len(c.Response().Body())).
+			Str("ip", ClientIP(c, cfg.TrustedProxies)).
+			Str("user_agent", c.Get(fiber.HeaderUserAgent))
+
+		if payload, ok := c.Locals("user").(*utils.JWTPayload); ok && payload != nil {
+			event = event.Str("user_id", payload.UserID)
+		}
+		if code, ok := c.Locals("errorCode").(string); ok {
+			event = event.Str("error_code", code)
+		}
+		if cause != nil {
+			event = event.Err(cause)
+		}
+		if cfg.LogHeaders {
+			event = event.Dict("headers", redactedHeaders(c))
+		}
+
+		event.Msg("request")
+
+		return err
+	}
+}
+
+// redactedHeaders returns the request headers with credentials replaced
+func redactedHeaders(c *fiber.Ctx) *zerolog.Event {
+	dict := zerolog.Dict()
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if sensitiveHeaders[strings.ToLower(name)] {
+			dict.Str(name, "[REDACTED]")
+			return
+		}
+		dict.Str(name, string(value))
+	})
+	return dict
+}
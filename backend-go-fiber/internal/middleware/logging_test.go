@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+// setupAccessLogApp returns an app that logs to the returned buffer
+func setupAccessLogApp(cfg AccessLogConfig) (*fiber.App, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	cfg.Logger = &logger
+
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(RequestIDMiddleware())
+	app.Use(AccessLogMiddleware(cfg))
+	app.Use(ErrorMiddleware())
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		utils.Logger(c).Info().Msg("handler")
+		return c.SendString("ok")
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return utils.SendError(c, "NOT_FOUND", "Not found", fiber.StatusNotFound)
+	})
+	app.Get("/broken", func(c *fiber.Ctx) error {
+		return errors.New("database is down")
+	})
+
+	return app, &buf
+}
+
+// logEntries parses JSON log lines
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Log line is not JSON: %s", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	app, buf := setupAccessLogApp(AccessLogConfig{LogHeaders: true})
+
+	req := httptest.NewRequest("GET", "/items/7", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("Authorization", "Bearer secret")
+	app.Test(req)
+
+	entries := logEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("Expected handler and access log entries, got %d", len(entries))
+	}
+
+	if entries[0]["request_id"] != "req-42" {
+		t.Error("Expected request-scoped logger to carry the request ID")
+	}
+
+	access := entries[1]
+	if access["route"] != "/items/:id" || access["path"] != "/items/7" {
+		t.Errorf("Expected route template and path, got %v / %v", access["route"], access["path"])
+	}
+	if access["status"] != float64(200) || access["request_id"] != "req-42" {
+		t.Errorf("Unexpected access log entry: %v", access)
+	}
+
+	headers := access["headers"].(map[string]interface{})
+	if headers["Authorization"] != "[REDACTED]" {
+		t.Errorf("Expected Authorization to be redacted, got %v", headers["Authorization"])
+	}
+}
+
+func TestAccessLogClientIP(t *testing.T) {
+	// app.Test connects from 0.0.0.0
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+	app, buf := setupAccessLogApp(AccessLogConfig{TrustedProxies: trusted})
+
+	req := httptest.NewRequest("GET", "/items/7", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 192.0.2.1")
+	app.Test(req)
+
+	// The leftmost X-Forwarded-For entry is set by the client
+	entries := logEntries(t, buf)
+	if ip := entries[len(entries)-1]["ip"]; ip != "192.0.2.1" {
+		t.Errorf("Expected the address seen by the trusted proxy, got %v", ip)
+	}
+}
+
+func TestAccessLogErrorCode(t *testing.T) {
+	app, buf := setupAccessLogApp(AccessLogConfig{})
+
+	app.Test(httptest.NewRequest("GET", "/missing", nil))
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if entries[0]["error_code"] != "NOT_FOUND" || entries[0]["level"] != "warn" {
+		t.Errorf("Expected warn entry with error code, got %v", entries[0])
+	}
+}
+
+func TestAccessLogErrorCause(t *testing.T) {
+	app, buf := setupAccessLogApp(AccessLogConfig{})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/broken", nil))
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Errorf("Expected the error handler to write 500, got %d", resp.StatusCode)
+	}
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry["status"] != float64(500) || entry["level"] != "error" || entry["error"] != "database is down" {
+		t.Errorf("Expected error entry with the cause, got %v", entry)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	app, buf := setupAccessLogApp(AccessLogConfig{Sample2xx: 3})
+
+	for i := 0; i < 6; i++ {
+		app.Test(httptest.NewRequest("GET", "/items/1", nil))
+	}
+	app.Test(httptest.NewRequest("GET", "/missing", nil))
+
+	var access, errors int
+	for _, entry := range logEntries(t, buf) {
+		if entry["message"] != "request" {
+			continue
+		}
+		if entry["status"] == float64(200) {
+			access++
+		} else {
+			errors++
+		}
+	}
+	if access != 2 {
+		t.Errorf("Expected 2 of 6 successful requests to be logged, got %d", access)
+	}
+	if errors != 1 {
+		t.Errorf("Expected errors to always be logged, got %d", errors)
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Maintenance settings (see models.DefaultSettings)
+const (
+	SettingMaintenanceMode          = "maintenance_mode"
+	SettingMaintenanceMessage       = "maintenance_message"
+	SettingMaintenanceRetryAfter    = "maintenance_retry_after"
+	SettingMaintenanceAllowedIPs    = "maintenance_allowed_ips"
+	SettingMaintenanceAllowedRoutes = "maintenance_allowed_routes"
+)
+
+// alwaysAvailable routes are never blocked - load balancers and monitoring must keep seeing the instance
+var alwaysAvailable = []string{"/health", "/ready", "/metrics"}
+
+// MaintenanceMiddleware returns 503 while the maintenance_mode setting is on.
+// Settings are read from the cache on every request, so toggling them in the
+// admin UI takes effect without a restart.
+// Health checks, allowlisted IPs and routes, and admins still get through.
+// The client IP is taken from X-Forwarded-For only behind trusted proxies.
+func MaintenanceMiddleware(settings *services.SettingsCache, db *gorm.DB, trusted utils.IPList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !settings.GetBool(SettingMaintenanceMode, false) {
+			return c.Next()
+		}
+
+		// Allowlisted routes apply to every API version
+		path := apiversion.CanonicalPath(c.Path())
+		for _, route := range alwaysAvailable {
+			if path == route {
+				return c.Next()
+			}
+		}
+
+		if routes, _ := settings.Get(SettingMaintenanceAllowedRoutes); routeAllowed(routes, path) {
+			return c.Next()
+		}
+
+		if ips, _ := settings.Get(SettingMaintenanceAllowedIPs); ips != "" {
+			allowed, err := utils.ParseIPList(ips)
+			if err != nil {
+				log.Error().Err(err).Msg("Invalid maintenance_allowed_ips setting")
+			} else if allowed.Contains(ClientIP(c, trusted)) {
+				return c.Next()
+			}
+		}
+
+		if isAdminRequest(c, db) {
+			return c.Next()
+		}
+
+		message, _ := settings.Get(SettingMaintenanceMessage)
+		if message == "" {
+			message = "The service is under maintenance, please try again later"
+		}
+
+		retryAfter, _ := settings.Get(SettingMaintenanceRetryAfter)
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		}
+
+		return utils.SendError(c, "MAINTENANCE", message, fiber.StatusServiceUnavailable)
+	}
+}
+
+// routeAllowed matches the canonical path against a comma-separated list of
+// exact paths and prefixes ending in "*", ignoring case
+func routeAllowed(routes, path string) bool {
+	for _, route := range strings.Split(strings.ToLower(routes), ",") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(route, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == route {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminRequest checks for a valid access token of an active admin.
+// The role claim only avoids a database lookup for regular users -
+// the role is confirmed against the database.
+func isAdminRequest(c *fiber.Ctx, db *gorm.DB) bool {
+	authHeader := c.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return false
+	}
+
+	payload, err := utils.VerifyAccessToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil || payload.Role != string(models.RoleAdmin) {
+		return false
+	}
+
+	var user models.User
+	if err := db.WithContext(c.UserContext()).Select("id", "role", "is_active", "suspended_until").First(&user, "id = ?", payload.UserID).Error; err != nil {
+		return false
+	}
+
+	return user.IsAdmin() && !user.IsSuspended()
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupMaintenanceApp turns maintenance mode on with the given settings and
+// serves every path with 200 behind MaintenanceMiddleware
+func setupMaintenanceApp(t *testing.T, settings map[string]string) (*fiber.App, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.AppSettings{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	db.Create(&models.AppSettings{Key: SettingMaintenanceMode, Value: "true"})
+	for key, value := range settings {
+		db.Create(&models.AppSettings{Key: key, Value: value})
+	}
+
+	cache, err := services.NewSettingsCache(db)
+	if err != nil {
+		t.Fatalf("NewSettingsCache failed: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+
+	// app.Test connects from 0.0.0.0
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(MaintenanceMiddleware(cache, db, trusted))
+	app.All("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	return app, db
+}
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	app, db := setupMaintenanceApp(t, map[string]string{
+		SettingMaintenanceRetryAfter:    "120",
+		SettingMaintenanceAllowedIPs:    "198.51.100.0/24",
+		SettingMaintenanceAllowedRoutes: "/api/status, /api/public/*",
+	})
+
+	admin := models.User{ID: "admin-1", Email: "admin@example.com", PasswordHash: "x", Role: models.RoleAdmin, IsActive: true}
+	user := models.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Role: models.RoleUser, IsActive: true}
+	db.Create(&admin)
+	db.Create(&user)
+	adminToken, _ := utils.GenerateAccessToken(utils.JWTPayload{UserID: admin.ID, Email: admin.Email, Role: string(admin.Role)})
+	userToken, _ := utils.GenerateAccessToken(utils.JWTPayload{UserID: user.ID, Email: user.Email, Role: string(user.Role)})
+
+	tests := []struct {
+		name      string
+		path      string
+		forwarded string
+		token     string
+		status    int
+	}{
+		{"blocked", "/api/auth/me", "192.0.2.1", "", 503},
+		{"health", "/health", "192.0.2.1", "", 200},
+		{"ready", "/ready", "192.0.2.1", "", 200},
+		{"allowed route", "/api/status", "192.0.2.1", "", 200},
+		{"allowed route v1", "/api/v1/status", "192.0.2.1", "", 200},
+		{"allowed prefix v1", "/api/v1/public/pages/1", "192.0.2.1", "", 200},
+		{"allowlisted IP", "/api/auth/me", "198.51.100.7", "", 200},
+		{"spoofed allowlisted IP", "/api/auth/me", "198.51.100.7, 192.0.2.1", "", 503},
+		{"admin", "/api/auth/me", "192.0.2.1", adminToken, 200},
+		{"user", "/api/auth/me", "192.0.2.1", userToken, 503},
+		{"invalid token", "/api/auth/me", "192.0.2.1", "invalid", 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, resp.StatusCode)
+			}
+			if tt.status == 503 && resp.Header.Get("Retry-After") != "120" {
+				t.Errorf("Expected Retry-After 120, got %q", resp.Header.Get("Retry-After"))
+			}
+		})
+	}
+
+	// A suspended admin is blocked like everyone else
+	until := time.Now().Add(time.Hour)
+	db.Model(&admin).Updates(map[string]any{"is_active": false, "suspended_until": until})
+	req := httptest.NewRequest("GET", "/api/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	if resp, _ := app.Test(req); resp.StatusCode != 503 {
+		t.Errorf("Expected suspended admin to get 503, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/metrics"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MetricsMiddleware records request count and latency by route template and status.
+// Must run outside ErrorMiddleware, so the status of errors is final.
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		// Route template keeps label cardinality bounded (/api/admin/users/:id, not every id)
+		labels := []string{c.Method(), c.Route().Path, strconv.Itoa(c.Response().StatusCode())}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// MetricsAccess protects /metrics: a request passes with "Authorization: Bearer <token>"
+// (when token is set) or from an allowed IP. The client IP is taken from
+// X-Forwarded-For only behind trusted proxies.
+func MetricsAccess(token string, allowed, trusted utils.IPList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token != "" {
+			provided := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+				return c.Next()
+			}
+		}
+
+		if allowed.Contains(ClientIP(c, trusted)) {
+			return c.Next()
+		}
+
+		return utils.SendError(c, "FORBIDDEN", "Metrics access denied", fiber.StatusForbidden)
+	}
+}
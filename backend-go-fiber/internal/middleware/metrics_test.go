@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend-go-fiber/internal/metrics"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(MetricsMiddleware())
+	app.Use(ErrorMiddleware())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "upstream failed")
+	})
+
+	app.Test(httptest.NewRequest("GET", "/users/1", nil))
+	app.Test(httptest.NewRequest("GET", "/users/2", nil))
+	app.Test(httptest.NewRequest("GET", "/fail", nil))
+
+	if got := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("GET", "/users/:id", "200")); got != 2 {
+		t.Errorf("Expected 2 requests for the route template, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues("GET", "/fail", "502")); got != 1 {
+		t.Errorf("Expected error status from the error handler to be recorded, got %v", got)
+	}
+}
+
+func TestMetricsAccess(t *testing.T) {
+	allowed, _ := utils.ParseIPList("10.0.0.0/8")
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Get("/metrics", MetricsAccess("secret-token", allowed, trusted), metrics.Handler())
+
+	// app.Test requests come from 0.0.0.0
+	resp, _ := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("Expected 403 without token, got %d", resp.StatusCode)
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("Expected 403 with wrong token, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected 200 with token, got %d", resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "go_goroutines") {
+		t.Error("Expected Prometheus exposition format")
+	}
+
+	// Behind the trusted proxy the allowlist applies to the client, not the leftmost hop
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected 200 from allowed IP, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("X-Forwarded-For", "10.1.2.3, 192.0.2.1")
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Errorf("Expected 403 with spoofed X-Forwarded-For, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OrgMember middleware ensures the user has an active organization and is still a member of it.
+// The membership is re-read from the database because roles can change while the token is valid.
+func OrgMember(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Get user payload from context (set by AuthMiddleware)
+		payload, ok := c.Locals("user").(*utils.JWTPayload)
+		if !ok || payload == nil {
+			return utils.SendError(c, "UNAUTHORIZED", "Authentication required", fiber.StatusUnauthorized)
+		}
+
+		if payload.OrgID == "" {
+			return utils.SendError(c, "NO_ACTIVE_ORGANIZATION", "No active organization selected", fiber.StatusForbidden)
+		}
+
+		var membership models.Membership
+		if err := db.WithContext(c.UserContext()).Joins("Organization").
+			Where("memberships.organization_id = ? AND memberships.user_id = ?", payload.OrgID, payload.UserID).
+			First(&membership).Error; err != nil || membership.Organization.ID == "" {
+			return utils.SendError(c, "NOT_ORG_MEMBER", "You are not a member of this organization", fiber.StatusForbidden)
+		}
+
+		// Store membership in context for handlers
+		c.Locals("membership", &membership)
+
+		return c.Next()
+	}
+}
+
+// RequireOrgRole middleware restricts the route to members with at least minRole.
+// Must be used after OrgMember.
+func RequireOrgRole(minRole models.OrgRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		membership, ok := c.Locals("membership").(*models.Membership)
+		if !ok || membership == nil {
+			return utils.SendError(c, "NOT_ORG_MEMBER", "You are not a member of this organization", fiber.StatusForbidden)
+		}
+
+		if !membership.Role.AtLeast(minRole) {
+			return utils.SendError(c, "FORBIDDEN", "Insufficient organization role", fiber.StatusForbidden)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/metrics"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/ratelimit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// APIKeyHeader carries API keys of machine clients
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyResolver verifies an API key and returns a stable id for it.
+// Unverified keys must not be used as limit keys - a client could rotate
+// random keys to get a fresh limit on every request.
+type APIKeyResolver func(key string) (id string, ok bool)
+
+// rateLimitClient identifies who a request is counted against
+type rateLimitClient struct {
+	ip       string
+	userID   string
+	apiKeyID string
+	isAdmin  bool
+}
+
+func (rc rateLimitClient) key(by ratelimit.KeyBy) string {
+	switch {
+	case by == ratelimit.KeyByUser && rc.userID != "":
+		return "user:" + rc.userID
+	case by == ratelimit.KeyByAPIKey && rc.apiKeyID != "":
+		return "key:" + rc.apiKeyID
+	default:
+		return "ip:" + rc.ip
+	}
+}
+
+// RateLimitMiddleware applies the limiter's policies to every request.
+// Every matching policy counts the request; the response carries RateLimit-*
+// headers for the most restrictive one and Retry-After when rejected. A
+// rejected request isn't counted by any policy.
+// resolveAPIKey may be nil, then api_key policies fall back to the client IP.
+// The client IP is taken from X-Forwarded-For only behind trusted proxies.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, resolveAPIKey APIKeyResolver, trusted utils.IPList) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Policies are written for /api/... and cover every API version
+		policies := limiter.Match(c.Method(), apiversion.CanonicalPath(c.Path()))
+		if len(policies) == 0 {
+			return c.Next()
+		}
+
+		client := identifyClient(c, resolveAPIKey, trusted)
+
+		var tightest *ratelimit.Result
+		var tightestPolicy ratelimit.Policy
+		var taken []ratelimit.Result
+		for _, policy := range policies {
+			limit := policy.LimitFor(client.isAdmin)
+			if limit == ratelimit.Unlimited {
+				continue
+			}
+
+			result, err := limiter.Take(policy, client.key(policy.KeyBy), limit)
+			if err != nil {
+				// Fail open: an unavailable limiter store must not take the API down
+				log.Error().Err(err).Str("policy", policy.Name).Msg("Rate limiter storage error")
+				continue
+			}
+
+			if !result.Allowed {
+				// Take back what the earlier policies counted
+				for _, earlier := range taken {
+					if err := limiter.Release(earlier); err != nil {
+						log.Error().Err(err).Msg("Rate limiter storage error")
+					}
+				}
+
+				metrics.RateLimitRejections.WithLabelValues(policy.Name).Inc()
+				setRateLimitHeaders(c, policy, result)
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+
+				message := policy.Message
+				if message == "" {
+					message = "Too many requests, please try again later"
+				}
+				return utils.SendError(c, "RATE_LIMIT_EXCEEDED", message, fiber.StatusTooManyRequests)
+			}
+
+			taken = append(taken, result)
+			if tightest == nil || result.Remaining < tightest.Remaining {
+				tightest = &result
+				tightestPolicy = policy
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(c, tightestPolicy, *tightest)
+		}
+
+		return c.Next()
+	}
+}
+
+// identifyClient reads the client IP, the user from a valid access token
+// (rate limiting runs before AuthMiddleware) and a verified API key
+func identifyClient(c *fiber.Ctx, resolveAPIKey APIKeyResolver, trusted utils.IPList) rateLimitClient {
+	client := rateLimitClient{ip: ClientIP(c, trusted)}
+
+	if authHeader := c.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		if payload, err := utils.VerifyAccessToken(strings.TrimPrefix(authHeader, "Bearer ")); err == nil {
+			client.userID = payload.UserID
+			client.isAdmin = payload.Role == string(models.RoleAdmin)
+		}
+	}
+
+	if apiKey := c.Get(APIKeyHeader); apiKey != "" && resolveAPIKey != nil {
+		if id, ok := resolveAPIKey(apiKey); ok {
+			client.apiKeyID = id
+		}
+	}
+
+	return client
+}
+
+// setRateLimitHeaders sets the IETF RateLimit header fields
+// (draft-ietf-httpapi-ratelimit-headers)
+func setRateLimitHeaders(c *fiber.Ctx, policy ratelimit.Policy, result ratelimit.Result) {
+	window := time.Duration(policy.Window)
+	c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Set("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+strconv.Itoa(ceilSeconds(window)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/services/ratelimit"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	// app.Test connects from 0.0.0.0
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+	limiter := ratelimit.NewLimiter(nil, []ratelimit.Policy{
+		{Name: "login", Method: "POST", Route: "/api/auth/login", Max: 1, Window: ratelimit.Duration(time.Minute)},
+	})
+
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(RateLimitMiddleware(limiter, nil, trusted))
+	app.Post("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		forwarded string
+		status    int
+	}{
+		{"first attempt", "/api/auth/login", "192.0.2.1", 200},
+		{"second attempt", "/api/auth/login", "192.0.2.1", 429},
+		{"mixed case path", "/API/v1/Auth/LOGIN", "192.0.2.1", 429},
+		{"spoofed first hop", "/api/auth/login", "198.51.100.1, 192.0.2.1", 429},
+		{"other client", "/api/auth/login", "192.0.2.2", 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, nil)
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareRejectedNotCounted(t *testing.T) {
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+	limiter := ratelimit.NewLimiter(nil, []ratelimit.Policy{
+		{Name: "global", Route: "*", Max: 3, Window: ratelimit.Duration(time.Minute)},
+		{Name: "login", Method: "POST", Route: "/api/auth/login", Max: 1, Window: ratelimit.Duration(time.Minute)},
+	})
+
+	app := fiber.New()
+	app.Use(RateLimitMiddleware(limiter, nil, trusted))
+	app.Post("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	// The login rejection must not use up the global limit
+	for i, tt := range []struct {
+		path   string
+		status int
+	}{
+		{"/api/auth/login", 200},
+		{"/api/auth/login", 429},
+		{"/api/other", 200},
+		{"/api/other", 200},
+		{"/api/other", 429},
+	} {
+		resp, err := app.Test(httptest.NewRequest("POST", tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("Request %d to %s: expected %d, got %d", i+1, tt.path, tt.status, resp.StatusCode)
+		}
+	}
+}
@@ -1,67 +1,88 @@
 package middleware
 
 import (
-	"os"
+	"strconv"
 	"strings"
-	"time"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/services/cors"
+	"backend-go-fiber/internal/services/csp"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/google/uuid"
 )
 
-func CORSMiddleware() fiber.Handler {
-	originsStr := os.Getenv("CORS_ORIGINS")
-	if originsStr == "" {
-		originsStr = "http://localhost:3000"
+// CORS headers shared by all policies
+const (
+	corsAllowMethods  = "GET,POST,PUT,DELETE,PATCH,OPTIONS"
+	corsAllowHeaders  = "Origin,Content-Type,Accept,Accept-Language,Authorization,X-API-Key,X-Request-ID,traceparent,tracestate,Idempotency-Key,If-Match,If-None-Match,API-Version"
+	corsExposeHeaders = "X-Request-ID,Content-Language,Idempotent-Replayed,ETag,API-Version,Deprecation,Sunset,Link"
+)
+
+// CORSMiddleware applies the CORS policy of the request's group (see CORSGroup).
+// Policies are read on every request, so changes to the cors_policies setting
+// take effect without a restart.
+func CORSMiddleware(policies *cors.Policies) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Vary(fiber.HeaderOrigin)
+
+		origin := c.Get(fiber.HeaderOrigin)
+		policy := policies.For(CORSGroup(c))
+		allowed := policy.AllowsOrigin(origin)
+
+		preflight := c.Method() == fiber.MethodOptions && c.Get(fiber.HeaderAccessControlRequestMethod) != ""
+		if !preflight {
+			if allowed {
+				setAllowOrigin(c, policy, origin)
+				c.Set(fiber.HeaderAccessControlExposeHeaders, corsExposeHeaders)
+			}
+			return c.Next()
+		}
+
+		c.Vary(fiber.HeaderAccessControlRequestMethod)
+		c.Vary(fiber.HeaderAccessControlRequestHeaders)
+		if allowed {
+			setAllowOrigin(c, policy, origin)
+			c.Set(fiber.HeaderAccessControlAllowMethods, corsAllowMethods)
+			c.Set(fiber.HeaderAccessControlAllowHeaders, corsAllowHeaders)
+			c.Set(fiber.HeaderAccessControlMaxAge, strconv.Itoa(policy.PreflightMaxAge()))
+		}
+		return c.SendStatus(fiber.StatusNoContent)
 	}
+}
 
-	return cors.New(cors.Config{
-		AllowOrigins:     originsStr,
-		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
-		AllowCredentials: true,
-	})
+// CORSGroup returns the CORS policy group of a request: admin routes, then
+// API-key clients (preflights announce the header in Access-Control-Request-Headers),
+// then public
+func CORSGroup(c *fiber.Ctx) string {
+	if strings.HasPrefix(apiversion.CanonicalPath(c.Path()), apiversion.Prefix+"/admin") {
+		return cors.GroupAdmin
+	}
+	if c.Get(APIKeyHeader) != "" {
+		return cors.GroupAPIKey
+	}
+	for _, header := range strings.Split(c.Get(fiber.HeaderAccessControlRequestHeaders), ",") {
+		if strings.EqualFold(strings.TrimSpace(header), APIKeyHeader) {
+			return cors.GroupAPIKey
+		}
+	}
+	return cors.GroupPublic
 }
 
-func HelmetMiddleware() fiber.Handler {
-	// Build CSP based on environment
-	// Production: More relaxed to allow CDN, fonts, analytics
-	// Development: Stricter for testing
-	var csp string
-	if os.Getenv("NODE_ENV") == "production" {
-		// Production CSP: Allow common CDNs, Google Fonts, analytics
-		csp = strings.Join([]string{
-			"default-src 'self'",
-			"script-src 'self' 'unsafe-inline' https://www.googletagmanager.com https://www.google-analytics.com",
-			"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com",
-			"img-src 'self' data: https: blob:",
-			"connect-src 'self' https://www.google-analytics.com https://analytics.google.com",
-			"font-src 'self' https://fonts.gstatic.com",
-			"object-src 'none'",
-			"media-src 'self'",
-			"frame-src 'none'",
-			"base-uri 'self'",
-			"form-action 'self'",
-		}, "; ")
-	} else {
-		// Development CSP: Stricter
-		csp = strings.Join([]string{
-			"default-src 'self'",
-			"script-src 'self' 'unsafe-inline'", // unsafe-inline for Vite HMR
-			"style-src 'self' 'unsafe-inline'",
-			"img-src 'self' data: https:",
-			"connect-src 'self' ws: wss:", // WebSocket for Vite HMR
-			"font-src 'self'",
-			"object-src 'none'",
-			"media-src 'self'",
-			"frame-src 'none'",
-		}, "; ")
+func setAllowOrigin(c *fiber.Ctx, policy cors.Policy, origin string) {
+	// The request origin is echoed (never "*") so credentials work with any pattern
+	c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+	if policy.AllowCredentials {
+		c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
 	}
+}
 
-	return helmet.New(helmet.Config{
+// HelmetMiddleware sets the security headers and the Content-Security-Policy.
+// Every request gets a fresh nonce, added to cfg.NonceDirectives and exposed
+// as Locals("cspNonce") for server-rendered pages (see CSPNonce).
+func HelmetMiddleware(cfg csp.Config) fiber.Handler {
+	headers := helmet.New(helmet.Config{
 		XSSProtection:             "1; mode=block",
 		ContentTypeNosniff:        "nosniff",
 		XFrameOptions:             "DENY",
@@ -69,74 +90,61 @@ func HelmetMiddleware() fiber.Handler {
 		CrossOriginEmbedderPolicy: "require-corp",
 		CrossOriginOpenerPolicy:   "same-origin",
 		CrossOriginResourcePolicy: "same-origin",
-		ContentSecurityPolicy:     csp,
 	})
-}
+	headerName := cfg.HeaderName()
 
-func RateLimiterMiddleware() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        100,
-		Expiration: 1 * time.Minute,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"code":    "RATE_LIMIT_EXCEEDED",
-					"message": "Too many requests, please try again later",
-				},
-			})
-		},
-	})
-}
+	return func(c *fiber.Ctx) error {
+		nonce, err := csp.NewNonce()
+		if err != nil {
+			return err
+		}
+		c.Locals("cspNonce", nonce)
 
-// LoginRateLimiter limits login attempts: 5 per 5 minutes per IP
-func LoginRateLimiter() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        5,
-		Expiration: 5 * time.Minute,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return "login:" + c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"code":    "RATE_LIMIT_EXCEEDED",
-					"message": "Too many login attempts. Try again in 5 minutes.",
-				},
-			})
-		},
-	})
+		c.Set(headerName, cfg.Header(nonce))
+		if cfg.ReportURI != "" {
+			c.Set("Reporting-Endpoints", cfg.ReportingEndpoints())
+		}
+		return headers(c)
+	}
 }
 
-// RegisterRateLimiter limits registration attempts: 3 per hour per IP
-func RegisterRateLimiter() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        3,
-		Expiration: 1 * time.Hour,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return "register:" + c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"code":    "RATE_LIMIT_EXCEEDED",
-					"message": "Too many registration attempts. Try again later.",
-				},
-			})
-		},
-	})
+// CSPNonce returns the nonce of the request's Content-Security-Policy; inline
+// scripts rendered into the response need it as their nonce attribute
+func CSPNonce(c *fiber.Ctx) string {
+	nonce, _ := c.Locals("cspNonce").(string)
+	return nonce
 }
 
+// maxRequestIDLength bounds client supplied request IDs (they end up in logs and headers)
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns each request an ID, exposed as Locals("requestId") and X-Request-ID.
+// An incoming X-Request-ID (e.g. from SvelteKit or nginx) is kept so one ID follows
+// the request across services; invalid or missing IDs are replaced with a new UUID.
 func RequestIDMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		requestID := uuid.New().String()
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
 		c.Locals("requestId", requestID)
-		c.Set("X-Request-ID", requestID)
+		c.Set(fiber.HeaderXRequestID, requestID)
 		return c.Next()
 	}
 }
+
+// validRequestID accepts IDs made of letters, digits and -_.:
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend-go-fiber/internal/services/csp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHelmetMiddlewareNonce(t *testing.T) {
+	app := fiber.New()
+	app.Use(HelmetMiddleware(csp.DefaultConfig(true)))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(CSPNonce(c))
+	})
+
+	var nonces []string
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		nonce := string(body[:n])
+
+		header := resp.Header.Get("Content-Security-Policy")
+		if nonce == "" || !strings.Contains(header, "'nonce-"+nonce+"'") {
+			t.Errorf("nonce %q is not in the policy %q", nonce, header)
+		}
+		if resp.Header.Get("Reporting-Endpoints") == "" {
+			t.Error("Reporting-Endpoints header missing")
+		}
+		if resp.Header.Get("X-Frame-Options") != "DENY" {
+			t.Error("helmet headers missing")
+		}
+		nonces = append(nonces, nonce)
+	}
+
+	if nonces[0] == nonces[1] {
+		t.Error("nonce reused across requests")
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"strings"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for each request.
+// An incoming W3C traceparent makes the span part of the caller's trace.
+// The span context is stored in c.UserContext() - pass that context to services
+// so their spans (GORM, storage, email) become children of the request span.
+// The client address is taken from X-Forwarded-For only behind trusted proxies.
+// Must run after RequestIDMiddleware.
+func TracingMiddleware(trusted utils.IPList) fiber.Handler {
+	tracer := otel.Tracer("backend-go-fiber/http")
+
+	return func(c *fiber.Ctx) error {
+		carrier := propagation.MapCarrier{}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			// MapCarrier keys are lowercase, fasthttp canonicalizes header names
+			carrier[strings.ToLower(string(key))] = string(value)
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), carrier)
+
+		ctx, span := tracer.Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("client.address", ClientIP(c, trusted)),
+				attribute.String("user_agent.original", c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		if requestID, ok := c.Locals("requestId").(string); ok {
+			span.SetAttributes(attribute.String("request.id", requestID))
+		}
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Name the span after the route template, not the concrete path
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+
+		status := c.Response().StatusCode()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		if payload, ok := c.Locals("user").(*utils.JWTPayload); ok && payload != nil {
+			span.SetAttributes(attribute.String("enduser.id", payload.UserID))
+		}
+
+		return err
+	}
+}
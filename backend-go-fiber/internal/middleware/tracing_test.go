@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("requestId").(string))
+	})
+
+	// Incoming ID is kept
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "sveltekit-123")
+	resp, _ := app.Test(req)
+	if got := resp.Header.Get("X-Request-ID"); got != "sveltekit-123" {
+		t.Errorf("Expected incoming request ID to be kept, got %q", got)
+	}
+
+	// Invalid IDs are replaced
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "bad id\r\n"+strings.Repeat("x", 10))
+	resp, _ = app.Test(req)
+	if got := resp.Header.Get("X-Request-ID"); got == "" || strings.Contains(got, "bad") {
+		t.Errorf("Expected a generated request ID, got %q", got)
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	// app.Test connects from 0.0.0.0
+	trusted, _ := utils.ParseIPList("0.0.0.0")
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(RequestIDMiddleware())
+	app.Use(TracingMiddleware(trusted))
+	app.Get("/items/:id", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/items/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 192.0.2.1")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Error("Expected span to continue the incoming trace")
+	}
+	if span.Name() != "GET /items/:id" {
+		t.Errorf("Expected span named after the route template, got %q", span.Name())
+	}
+
+	attrs := map[attribute.Key]string{}
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	if attrs["request.id"] != "req-1" {
+		t.Error("Expected request.id attribute")
+	}
+	// The leftmost X-Forwarded-For entry is set by the client
+	if attrs["client.address"] != "192.0.2.1" {
+		t.Errorf("Expected client.address from the trusted proxy, got %q", attrs["client.address"])
+	}
+}
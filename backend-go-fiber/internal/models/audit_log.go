@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog records an admin mutation or a security event: who did what to
+// which record, what changed, and where the request came from.
+type AuditLog struct {
+	ID        string    `gorm:"primaryKey;type:text" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+
+	// Action is "<target>.<verb>" ("user.update", "auth.login_failed")
+	Action string `gorm:"index;not null" json:"action"`
+
+	// Actor is empty for anonymous requests (failed logins, password resets)
+	ActorID    *string `gorm:"type:text;index" json:"actorId"`
+	ActorEmail string  `json:"actorEmail"`
+
+	TargetType string `gorm:"index:idx_audit_logs_target" json:"targetType"`
+	TargetID   string `gorm:"index:idx_audit_logs_target" json:"targetId"`
+
+	// Changes holds the fields that differ between before and after
+	Changes AuditChanges `gorm:"type:text" json:"changes"`
+
+	// Metadata holds event details that aren't record fields ("reason": "expired")
+	Metadata AuditMetadata `gorm:"type:text" json:"metadata"`
+
+	IP        string `json:"ip"`
+	RequestID string `gorm:"index" json:"requestId"`
+	UserAgent string `json:"userAgent"`
+
+	// ClientIdentity is mapped from the client certificate (tls.client_identities)
+	ClientIdentity string `json:"clientIdentity,omitempty"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AuditChange is the value of a field before and after a mutation.
+// From is nil for created records, To for deleted ones.
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// AuditChanges maps field names to their change, stored as JSON
+type AuditChanges map[string]AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *AuditChanges) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+// AuditMetadata holds event details, stored as JSON
+type AuditMetadata map[string]string
+
+func (m AuditMetadata) Value() (driver.Value, error) {
+	return jsonValue(m)
+}
+
+func (m *AuditMetadata) Scan(src any) error {
+	return scanJSON(src, m)
+}
+
+func jsonValue[T ~map[string]V, V any](v T) (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(src any, dst any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), dst)
+	case []byte:
+		return json.Unmarshal(src, dst)
+	default:
+		return fmt.Errorf("unsupported audit JSON column type %T", src)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CSPReport is a Content-Security-Policy violation reported by browsers.
+// Identical violations share one row; Count and LastSeenAt track repeats.
+type CSPReport struct {
+	ID string `gorm:"primaryKey;type:text" json:"id"`
+
+	// Fingerprint is a digest of directive, blocked URI, page and source location
+	Fingerprint string `gorm:"uniqueIndex;not null" json:"-"`
+
+	DocumentURI        string `json:"documentUri"`
+	EffectiveDirective string `gorm:"index" json:"effectiveDirective"`
+	BlockedURI         string `json:"blockedUri"`
+	SourceFile         string `json:"sourceFile"`
+	LineNumber         int    `json:"lineNumber"`
+	ColumnNumber       int    `json:"columnNumber"`
+	Disposition        string `json:"disposition"` // enforce or report
+	Sample             string `json:"sample"`
+	UserAgent          string `json:"userAgent"` // of the first report
+
+	Count       int64     `gorm:"not null;default:1" json:"count"`
+	FirstSeenAt time.Time `json:"firstSeenAt"`
+	LastSeenAt  time.Time `gorm:"index" json:"lastSeenAt"`
+}
+
+func (r *CSPReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
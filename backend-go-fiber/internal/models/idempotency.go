@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key header and
+// the response it produced, so a retried request replays the response instead
+// of running the handler again.
+type IdempotencyRecord struct {
+	// Key is a digest of the client key scoped to the caller and route
+	Key         string `gorm:"primaryKey;type:text"`
+	Fingerprint string `gorm:"not null"`
+	Status      int    `gorm:"not null;default:0"`
+	ContentType string
+	Body        []byte
+	CompletedAt *time.Time // nil while the first request is still running
+	ExpiresAt   time.Time  `gorm:"index;not null"`
+	CreatedAt   time.Time
+}
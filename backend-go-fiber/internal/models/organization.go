@@ -0,0 +1,186 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrgRole represents a user's role inside an organization
+type OrgRole string
+
+const (
+	OrgRoleOwner  OrgRole = "owner"
+	OrgRoleAdmin  OrgRole = "admin"
+	OrgRoleMember OrgRole = "member"
+)
+
+// rank orders roles so that higher values carry more privileges
+func (r OrgRole) rank() int {
+	switch r {
+	case OrgRoleOwner:
+		return 3
+	case OrgRoleAdmin:
+		return 2
+	case OrgRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid checks if the role is one of the known organization roles
+func (r OrgRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// AtLeast checks if the role grants at least the privileges of other
+func (r OrgRole) AtLeast(other OrgRole) bool {
+	return r.rank() >= other.rank()
+}
+
+// Organization is a tenant that owns data and groups users via memberships
+type Organization struct {
+	ID          string `gorm:"primaryKey;type:text"`
+	Name        string `gorm:"not null"`
+	Slug        string `gorm:"uniqueIndex;not null"`
+	CreatedByID string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"` // Soft delete support
+
+	Memberships []Membership `gorm:"foreignKey:OrganizationID"`
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Membership links a user to an organization with a role
+type Membership struct {
+	ID             string       `gorm:"primaryKey;type:text"`
+	OrganizationID string       `gorm:"type:text;not null;uniqueIndex:idx_membership_org_user"`
+	Organization   Organization `gorm:"constraint:OnDelete:CASCADE"`
+	UserID         string       `gorm:"type:text;not null;uniqueIndex:idx_membership_org_user;index"`
+	User           User         `gorm:"constraint:OnDelete:CASCADE"`
+	Role           OrgRole      `gorm:"type:text;default:member;not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (m *Membership) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	if m.Role == "" {
+		m.Role = OrgRoleMember
+	}
+	return nil
+}
+
+// OrganizationInvitation invites an email address to join an organization
+type OrganizationInvitation struct {
+	ID             string       `gorm:"primaryKey;type:text"`
+	OrganizationID string       `gorm:"type:text;not null;index"`
+	Organization   Organization `gorm:"constraint:OnDelete:CASCADE"`
+	Email          string       `gorm:"not null;index"`
+	Role           OrgRole      `gorm:"type:text;default:member;not null"`
+	TokenDigest
+	InvitedByID string `gorm:"type:text"`
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time // Null if not accepted yet
+	CreatedAt   time.Time
+
+	// Plaintext token, only set on the instance returned when the invitation is created
+	Token string `gorm:"-" json:"-"`
+}
+
+func (i *OrganizationInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsPending checks if the invitation can still be accepted
+func (i *OrganizationInvitation) IsPending() bool {
+	return i.AcceptedAt == nil && time.Now().Before(i.ExpiresAt)
+}
+
+// TenantModel can be embedded in models that belong to a single organization.
+// Query such models through utils.TenantScope to keep tenants isolated.
+type TenantModel struct {
+	OrganizationID string `gorm:"type:text;not null;index" json:"organizationId"`
+}
+
+// OrganizationResponse is the response format for organization data
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (o *Organization) ToResponse() OrganizationResponse {
+	return OrganizationResponse{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// UserOrganizationResponse describes an organization from a member's point of view
+type UserOrganizationResponse struct {
+	OrganizationResponse
+	Role     OrgRole `json:"role"`
+	IsActive bool    `json:"isActive"`
+}
+
+// MemberResponse is the response format for organization members
+type MemberResponse struct {
+	UserID   string    `json:"userId"`
+	Email    string    `json:"email"`
+	Name     *string   `json:"name"`
+	Role     OrgRole   `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+func (m *Membership) ToMemberResponse() MemberResponse {
+	return MemberResponse{
+		UserID:   m.UserID,
+		Email:    m.User.Email,
+		Name:     m.User.Name,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+}
+
+// InvitationResponse is the response format for organization invitations
+type InvitationResponse struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organizationId"`
+	Email          string     `json:"email"`
+	Role           OrgRole    `json:"role"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	AcceptedAt     *time.Time `json:"acceptedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func (i *OrganizationInvitation) ToResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:             i.ID,
+		OrganizationID: i.OrganizationID,
+		Email:          i.Email,
+		Role:           i.Role,
+		ExpiresAt:      i.ExpiresAt,
+		AcceptedAt:     i.AcceptedAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RateLimitEntry stores rate limiter state when limiters use the SQL backend
+// (RATE_LIMIT_STORAGE=sql), so all processes and instances share the same counters.
+type RateLimitEntry struct {
+	Key       string     `gorm:"primaryKey;type:text"`
+	Value     []byte     `gorm:"not null"`
+	Count     int        `gorm:"not null;default:0"` // counter of Increment, Value is empty
+	ExpiresAt *time.Time `gorm:"index"`              // nil = never expires
+}
@@ -68,6 +68,10 @@ func DefaultSettings() []AppSettings {
 		{Key: "app_name", Value: "My App", Type: SettingTypeString, Label: "Application Name", SettingGroup: "general"},
 		{Key: "app_description", Value: "A Go Fiber + SvelteKit application", Type: SettingTypeString, Label: "Description", SettingGroup: "general"},
 		{Key: "maintenance_mode", Value: "false", Type: SettingTypeBoolean, Label: "Maintenance Mode", SettingGroup: "general"},
+		{Key: "maintenance_message", Value: "The service is under maintenance, please try again later", Type: SettingTypeString, Label: "Maintenance Message", SettingGroup: "general"},
+		{Key: "maintenance_retry_after", Value: "300", Type: SettingTypeNumber, Label: "Maintenance Retry-After (seconds)", SettingGroup: "general"},
+		{Key: "maintenance_allowed_ips", Value: "", Type: SettingTypeString, Label: "Maintenance Allowed IPs (comma-separated, CIDR allowed)", SettingGroup: "general"},
+		{Key: "maintenance_allowed_routes", Value: "/api/auth/login,/api/auth/refresh", Type: SettingTypeString, Label: "Maintenance Allowed Routes (comma-separated, * suffix)", SettingGroup: "general"},
 		{Key: "allow_registration", Value: "true", Type: SettingTypeBoolean, Label: "Allow Registration", SettingGroup: "auth"},
 		{Key: "max_login_attempts", Value: "5", Type: SettingTypeNumber, Label: "Max Login Attempts", SettingGroup: "auth"},
 	}
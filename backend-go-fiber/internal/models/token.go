@@ -0,0 +1,83 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenDigest is embedded by every model that stores an opaque bearer token
+// (refresh tokens, password reset tokens, invitations, ...).
+// Only the SHA-256 digest is persisted, so a leaked database or backup
+// doesn't expose live sessions or links. Look tokens up with HashToken.
+type TokenDigest struct {
+	TokenHash string `gorm:"uniqueIndex;not null" json:"-"`
+}
+
+// HashToken returns the digest under which an opaque token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenModels lists all models that embed TokenDigest.
+// Add new token types here so MigratePlaintextTokens covers them.
+func TokenModels() []interface{} {
+	return []interface{}{
+		&RefreshToken{},
+		&PasswordResetToken{},
+		&OrganizationInvitation{},
+	}
+}
+
+// MigratePlaintextTokens converts tables created before tokens were hashed.
+// For each table that still has a plaintext "token" column it fills token_hash
+// with the digest of the existing token and drops the plaintext column.
+// Must run before AutoMigrate; it is a no-op on fresh or already migrated databases.
+func MigratePlaintextTokens(db *gorm.DB, tokenModels ...interface{}) error {
+	for _, model := range tokenModels {
+		migrator := db.Migrator()
+		if !migrator.HasTable(model) || !migrator.HasColumn(model, "token") {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(model, "token_hash") {
+				// Added as nullable first - existing rows have no digest yet
+				if err := tx.Exec("ALTER TABLE ? ADD COLUMN token_hash TEXT", clause.Table{Name: table}).Error; err != nil {
+					return err
+				}
+			}
+
+			var rows []struct {
+				ID    string
+				Token string
+			}
+			if err := tx.Table(table).Select("id", "token").Where("token_hash IS NULL OR token_hash = ''").Scan(&rows).Error; err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				if err := tx.Table(table).Where("id = ?", row.ID).Update("token_hash", HashToken(row.Token)).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Migrator().DropColumn(model, "token")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to hash tokens in %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// legacyRefreshToken is the refresh token schema before tokens were hashed
+type legacyRefreshToken struct {
+	ID        string `gorm:"primaryKey;type:text"`
+	Token     string `gorm:"uniqueIndex;not null"`
+	UserID    string `gorm:"not null"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func (legacyRefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("secret-token")
+
+	if len(hash) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(hash))
+	}
+
+	if hash != HashToken("secret-token") {
+		t.Error("HashToken should be deterministic")
+	}
+
+	if hash == HashToken("other-token") {
+		t.Error("Different tokens should have different digests")
+	}
+}
+
+func TestMigratePlaintextTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Each connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	// Schema and data as written by versions that stored plaintext tokens
+	if err := db.AutoMigrate(&User{}, &legacyRefreshToken{}); err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+	db.Create(&legacyRefreshToken{
+		ID:        "rt-1",
+		Token:     "legacy-token",
+		UserID:    "user-1",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	if err := MigratePlaintextTokens(db, TokenModels()...); err != nil {
+		t.Fatalf("MigratePlaintextTokens failed: %v", err)
+	}
+	if err := db.AutoMigrate(&RefreshToken{}); err != nil {
+		t.Fatalf("AutoMigrate after token migration failed: %v", err)
+	}
+
+	if db.Migrator().HasColumn(&RefreshToken{}, "token") {
+		t.Error("Plaintext token column should be dropped")
+	}
+
+	var stored RefreshToken
+	if err := db.Where("token_hash = ?", HashToken("legacy-token")).First(&stored).Error; err != nil {
+		t.Fatalf("Legacy token should be found by digest: %v", err)
+	}
+	if stored.ID != "rt-1" {
+		t.Errorf("ID mismatch: got %s, want rt-1", stored.ID)
+	}
+
+	// Running again is a no-op
+	if err := MigratePlaintextTokens(db, TokenModels()...); err != nil {
+		t.Errorf("Second run should be a no-op, got: %v", err)
+	}
+}
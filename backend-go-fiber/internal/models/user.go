@@ -24,6 +24,14 @@ type User struct {
 	Role         Role           `gorm:"type:text;default:user;not null"`
 	IsActive     bool           `gorm:"default:true;not null"`  // Account active status
 	LastLoginAt  *time.Time     `json:"lastLoginAt"`            // Last login timestamp
+	ActiveOrganizationID *string `gorm:"type:text"` // Organization used for the org claim in access tokens
+	Locale               *string `gorm:"size:16"`   // Preferred language, null to use Accept-Language
+
+	// Suspension details (set together with IsActive=false by an admin)
+	SuspendedAt      *time.Time
+	SuspendedUntil   *time.Time // Null means suspended until lifted manually
+	SuspensionReason *string
+	SuspendedByID    *string `gorm:"type:text"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"` // Soft delete support
@@ -36,6 +44,15 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// IsSuspended checks if the account is currently blocked from logging in.
+// A deactivated account whose SuspendedUntil has passed is no longer suspended.
+func (u *User) IsSuspended() bool {
+	if u.IsActive {
+		return false
+	}
+	return u.SuspendedUntil == nil || time.Now().Before(*u.SuspendedUntil)
+}
+
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == "" {
 		u.ID = uuid.New().String()
@@ -44,8 +61,8 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 }
 
 type RefreshToken struct {
-	ID        string `gorm:"primaryKey;type:text"`
-	Token     string `gorm:"uniqueIndex;not null"`
+	ID string `gorm:"primaryKey;type:text"`
+	TokenDigest
 	UserID    string `gorm:"not null"`
 	User      User   `gorm:"constraint:OnDelete:CASCADE"`
 	ExpiresAt time.Time
@@ -69,6 +86,9 @@ type UserResponse struct {
 	LastLoginAt *time.Time `json:"lastLoginAt"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	ActiveOrganizationID *string `json:"activeOrganizationId"`
+	Locale               *string `json:"locale"`
 }
 
 func (u *User) ToResponse() UserResponse {
@@ -81,6 +101,9 @@ func (u *User) ToResponse() UserResponse {
 		LastLoginAt: u.LastLoginAt,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
+
+		ActiveOrganizationID: u.ActiveOrganizationID,
+		Locale:               u.Locale,
 	}
 }
 
@@ -94,6 +117,12 @@ type AdminUserResponse struct {
 	LastLoginAt *time.Time `json:"lastLoginAt"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	IsSuspended      bool       `json:"isSuspended"`
+	SuspendedAt      *time.Time `json:"suspendedAt"`
+	SuspendedUntil   *time.Time `json:"suspendedUntil"`
+	SuspensionReason *string    `json:"suspensionReason"`
+	SuspendedByID    *string    `json:"suspendedById"`
 }
 
 func (u *User) ToAdminResponse() AdminUserResponse {
@@ -106,13 +135,19 @@ func (u *User) ToAdminResponse() AdminUserResponse {
 		LastLoginAt: u.LastLoginAt,
 		CreatedAt:   u.CreatedAt,
 		UpdatedAt:   u.UpdatedAt,
+
+		IsSuspended:      u.IsSuspended(),
+		SuspendedAt:      u.SuspendedAt,
+		SuspendedUntil:   u.SuspendedUntil,
+		SuspensionReason: u.SuspensionReason,
+		SuspendedByID:    u.SuspendedByID,
 	}
 }
 
 // PasswordResetToken stores password reset tokens
 type PasswordResetToken struct {
-	ID        string `gorm:"primaryKey;type:text"`
-	Token     string `gorm:"uniqueIndex;not null"`
+	ID string `gorm:"primaryKey;type:text"`
+	TokenDigest
 	UserID    string `gorm:"not null"`
 	User      User   `gorm:"constraint:OnDelete:CASCADE"`
 	ExpiresAt time.Time
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"embed"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// SpecHandler serves the document as JSON
+func SpecHandler(doc *Document) (fiber.Handler, error) {
+	data, err := doc.JSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(data)
+	}, nil
+}
+
+// UIHandler serves the interactive docs mounted at basePath (route basePath+"/*"),
+// which load the document from specURL. The UI is self-contained, so it works
+// offline and with the default Content-Security-Policy.
+func UIHandler(basePath, specURL string) fiber.Handler {
+	index, _ := uiFiles.ReadFile("ui/index.html")
+	page := strings.NewReplacer("{{BASE}}", basePath, "{{SPEC_URL}}", specURL).Replace(string(index))
+
+	return func(c *fiber.Ctx) error {
+		name := c.Params("*")
+		if name == "" || name == "index.html" {
+			c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+			return c.SendString(page)
+		}
+
+		data, err := uiFiles.ReadFile("ui/" + path.Clean("/" + name)[1:])
+		if err != nil {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		switch path.Ext(name) {
+		case ".js":
+			c.Set(fiber.HeaderContentType, "text/javascript; charset=utf-8")
+		case ".css":
+			c.Set(fiber.HeaderContentType, "text/css; charset=utf-8")
+		}
+		return c.Send(data)
+	}
+}
@@ -0,0 +1,447 @@
+// Package openapi generates an OpenAPI 3.1 document from the registered fiber
+// routes and the Go types their handlers read and write.
+//
+// Fiber routes don't know their types, so each route is described by an
+// Operation (see routes.Docs). Build reports routes without an Operation and
+// Operations without a route, so the document can't silently drift.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend-go-fiber/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.1.0"
+
+// Operation describes a route for the document
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+
+	// Auth requires a Bearer access token
+	Auth bool
+
+	// Query is a struct whose fields (json names) are query parameters,
+	// described by an optional doc tag
+	Query interface{}
+
+	// Body is the request body type, sent as JSON or, with Multipart,
+	// as multipart/form-data (form tags name the fields, use File for files)
+	Body      interface{}
+	Multipart bool
+
+	// BodyTypes replaces application/json as the media types of a non-multipart Body
+	BodyTypes []string
+
+	// Response is the type of "data" in the success envelope (nil = no data)
+	Response interface{}
+
+	// ResponseType is the media type of a success response sent as is,
+	// without the envelope (text/csv downloads); Response is ignored
+	ResponseType string
+
+	// Status is the success status (default 200); 204 has no body
+	Status int
+
+	// Errors lists documented error statuses besides the automatic ones
+	// (400 with Body or Query, 401 with Auth, 409/422 with Idempotent, 412 with IfMatch)
+	Errors []int
+
+	// Idempotent accepts an Idempotency-Key header
+	Idempotent bool
+
+	// IfMatch accepts If-Match for optimistic concurrency
+	IfMatch bool
+
+	Deprecated bool
+}
+
+// Operations maps "METHOD /path" (relative to Config.Prefix, fiber syntax) to operations
+type Operations map[string]Operation
+
+// Config describes the document
+type Config struct {
+	Title       string
+	Description string
+	Version     string
+
+	// Prefix selects the documented routes and is the server URL, e.g. "/api/v1"
+	Prefix string
+}
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem struct {
+	Get    *OperationObject `json:"get,omitempty"`
+	Post   *OperationObject `json:"post,omitempty"`
+	Put    *OperationObject `json:"put,omitempty"`
+	Patch  *OperationObject `json:"patch,omitempty"`
+	Delete *OperationObject `json:"delete,omitempty"`
+}
+
+type OperationObject struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+const bearerAuth = "bearerAuth"
+
+// Build documents the routes under cfg.Prefix with ops. The returned problems
+// list undocumented routes and operations that match no route.
+func Build(cfg Config, routes []fiber.Route, ops Operations) (*Document, []string) {
+	registry := newSchemaRegistry()
+	envelope := registry.schemaFor(utils.APIResponse{}, modeResponse)
+	registry.components["ErrorResponse"] = &Schema{AllOf: []*Schema{envelope, {Required: []string{"error"}}}}
+	errorContent := map[string]MediaType{
+		fiber.MIMEApplicationJSON: {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}},
+		utils.MIMEProblemJSON:     {Schema: registry.schemaFor(utils.ProblemDetails{}, modeResponse)},
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: cfg.Title, Description: cfg.Description, Version: cfg.Version},
+		Servers: []Server{{URL: cfg.Prefix}},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: registry.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	var problems []string
+	found := make(map[string]bool)
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodOptions {
+			continue
+		}
+		if route.Path != cfg.Prefix && !strings.HasPrefix(route.Path, cfg.Prefix+"/") {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, cfg.Prefix)
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+		if path == "" {
+			path = "/"
+		}
+		found[route.Method+" "+path] = true
+	}
+
+	// Sorted, so component names are assigned in a stable order
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		op, ok := ops[key]
+		if !ok {
+			problems = append(problems, "undocumented route: "+key)
+			continue
+		}
+		method, path, _ := strings.Cut(key, " ")
+		openAPIPath, pathParams := convertPath(path)
+		item := doc.Paths[openAPIPath]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[openAPIPath] = item
+		}
+
+		obj := buildOperation(registry, method, openAPIPath, op, pathParams, envelope, errorContent)
+		switch method {
+		case fiber.MethodGet:
+			item.Get = obj
+		case fiber.MethodPost:
+			item.Post = obj
+		case fiber.MethodPut:
+			item.Put = obj
+		case fiber.MethodPatch:
+			item.Patch = obj
+		case fiber.MethodDelete:
+			item.Delete = obj
+		default:
+			problems = append(problems, "unsupported method: "+key)
+		}
+	}
+
+	for key := range ops {
+		if !found[key] {
+			problems = append(problems, "documented route is not registered: "+key)
+		}
+	}
+	sort.Strings(problems)
+
+	return doc, problems
+}
+
+// JSON returns the document as indented JSON with a trailing newline
+func (d *Document) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func buildOperation(registry *schemaRegistry, method, path string, op Operation, pathParams []Parameter, envelope *Schema, errorContent map[string]MediaType) *OperationObject {
+	obj := &OperationObject{
+		OperationID: operationID(method, path),
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Parameters:  pathParams,
+		Responses:   make(map[string]Response),
+		Deprecated:  op.Deprecated,
+	}
+
+	errorStatuses := append([]int(nil), op.Errors...)
+
+	if op.Auth {
+		obj.Security = []map[string][]string{{bearerAuth: {}}}
+		errorStatuses = append(errorStatuses, fiber.StatusUnauthorized)
+	}
+
+	if op.Query != nil {
+		obj.Parameters = append(obj.Parameters, queryParameters(registry, op.Query)...)
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+
+	if op.Idempotent {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name:        "Idempotency-Key",
+			In:          "header",
+			Description: "Makes retries safe: a repeated key replays the first response",
+			Schema:      &Schema{Type: "string", MaxLength: intPtr(255)},
+		})
+		errorStatuses = append(errorStatuses, fiber.StatusConflict, fiber.StatusUnprocessableEntity)
+	}
+
+	if op.IfMatch {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "ETag from a previous response; the update fails with 412 if the resource changed",
+			Schema:      &Schema{Type: "string"},
+		})
+		errorStatuses = append(errorStatuses, fiber.StatusPreconditionFailed)
+	}
+
+	if op.Body != nil {
+		contentType := fiber.MIMEApplicationJSON
+		schema := registry.schemaFor(op.Body, modeRequest)
+		if op.Multipart {
+			contentType = fiber.MIMEMultipartForm
+			schema = formSchema(registry, op.Body)
+		}
+		content := map[string]MediaType{contentType: {Schema: schema}}
+		if !op.Multipart && len(op.BodyTypes) > 0 {
+			content = make(map[string]MediaType, len(op.BodyTypes))
+			for _, bodyType := range op.BodyTypes {
+				content[bodyType] = MediaType{Schema: schema}
+			}
+		}
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  content,
+		}
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+	success := envelope
+	if op.Response != nil {
+		success = &Schema{AllOf: []*Schema{envelope, {
+			Type:       "object",
+			Properties: map[string]*Schema{"data": registry.schemaFor(op.Response, modeResponse)},
+			Required:   []string{"data"},
+		}}}
+	}
+	obj.Responses[strconv.Itoa(status)] = Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: success}},
+	}
+	if op.ResponseType != "" {
+		obj.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{op.ResponseType: {Schema: &Schema{Type: "string"}}},
+		}
+	}
+	if status == fiber.StatusNoContent {
+		obj.Responses[strconv.Itoa(status)] = Response{Description: http.StatusText(status)}
+	}
+
+	for _, code := range errorStatuses {
+		obj.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content:     errorContent,
+		}
+	}
+
+	return obj
+}
+
+// convertPath turns fiber params (:id, *) into OpenAPI templates ({id}, {path})
+func convertPath(path string) (string, []Parameter) {
+	var parameters []Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name := ""
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = strings.TrimSuffix(strings.TrimPrefix(segment, ":"), "?")
+		case segment == "*" || segment == "+":
+			name = "path"
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// operationID derives an id like getAdminUsersById from method and path
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// queryParameters turns the fields of a struct into query parameters
+func queryParameters(registry *schemaRegistry, query interface{}) []Parameter {
+	t := reflect.TypeOf(query)
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, skip := jsonName(field)
+		if skip || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := registry.typeSchema(field.Type, modeRequest)
+		required := applyValidateTag(schema, field.Type, field.Tag.Get("validate"))
+		params = append(params, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: field.Tag.Get("doc"),
+			Required:    required,
+			Schema:      schema,
+		})
+	}
+	return params
+}
+
+// formSchema describes a multipart body; fields are named by their form tags
+func formSchema(registry *schemaRegistry, body interface{}) *Schema {
+	t := reflect.TypeOf(body)
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("form")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fieldSchema := registry.typeSchema(field.Type, modeRequest)
+		if applyValidateTag(fieldSchema, field.Type, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+	return schema
+}
+
+func intPtr(n int) *int {
+	return &n
+}
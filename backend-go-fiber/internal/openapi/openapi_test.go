@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type widgetInput struct {
+	Name  string  `json:"name" validate:"required,min=2,max=50"`
+	Email string  `json:"email" validate:"omitempty,email"`
+	Kind  string  `json:"kind" validate:"required,oneof=small large"`
+	Note  *string `json:"note,omitempty"`
+}
+
+type widget struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+type widgetQuery struct {
+	Page int `json:"page" validate:"omitempty,min=1" doc:"Page number"`
+}
+
+type uploadForm struct {
+	File File `form:"file" validate:"required"`
+}
+
+func buildTestDoc(t *testing.T, ops Operations) (*Document, []string) {
+	t.Helper()
+	app := fiber.New()
+	api := app.Group("/api/v1")
+	noop := func(c *fiber.Ctx) error { return nil }
+	api.Get("/widgets", noop)
+	api.Post("/widgets/", noop)
+	api.Put("/widgets/:id", noop)
+	api.Delete("/files/*", noop)
+	api.Post("/upload", noop)
+	app.Get("/health", noop)
+	return Build(Config{Title: "Test", Version: "v1", Prefix: "/api/v1"}, app.GetRoutes(true), ops)
+}
+
+func testOperations() Operations {
+	return Operations{
+		"GET /widgets":     {Query: widgetQuery{}, Response: []widget{}},
+		"POST /widgets":    {Body: widgetInput{}, Response: widget{}, Status: fiber.StatusCreated, Idempotent: true},
+		"PUT /widgets/:id": {Auth: true, Body: widgetInput{}, Response: widget{}, IfMatch: true},
+		"DELETE /files/*":  {Auth: true},
+		"POST /upload":     {Body: uploadForm{}, Multipart: true},
+	}
+}
+
+func TestBuildPaths(t *testing.T) {
+	doc, problems := buildTestDoc(t, testOperations())
+	if len(problems) != 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	if len(paths) != 4 {
+		t.Errorf("Expected 4 paths (routes outside the prefix skipped), got %v", paths)
+	}
+
+	put := doc.Paths["/widgets/{id}"].Put
+	if put == nil {
+		t.Fatal("Expected PUT /widgets/{id}")
+	}
+	if put.OperationID != "putWidgetsById" {
+		t.Errorf("Expected operationId putWidgetsById, got %s", put.OperationID)
+	}
+	if len(put.Parameters) != 2 || put.Parameters[0].Name != "id" || put.Parameters[0].In != "path" || put.Parameters[1].Name != "If-Match" {
+		t.Errorf("Unexpected parameters: %+v", put.Parameters)
+	}
+	for _, status := range []string{"200", "400", "401", "412"} {
+		if _, ok := put.Responses[status]; !ok {
+			t.Errorf("Expected %s response, got %v", status, keys(put.Responses))
+		}
+	}
+	if len(put.Security) != 1 {
+		t.Error("Expected bearer security on authenticated route")
+	}
+
+	if del := doc.Paths["/files/{path}"].Delete; del == nil || del.Parameters[0].Name != "path" {
+		t.Error("Expected wildcard to become {path}")
+	}
+
+	post := doc.Paths["/widgets"].Post
+	for _, status := range []string{"201", "400", "409", "422"} {
+		if _, ok := post.Responses[status]; !ok {
+			t.Errorf("Expected %s response on idempotent POST, got %v", status, keys(post.Responses))
+		}
+	}
+
+	get := doc.Paths["/widgets"].Get
+	if len(get.Parameters) != 1 || get.Parameters[0].In != "query" || get.Parameters[0].Description != "Page number" {
+		t.Errorf("Unexpected query parameters: %+v", get.Parameters)
+	}
+}
+
+func TestBuildSchemasFromValidateTags(t *testing.T) {
+	doc, _ := buildTestDoc(t, testOperations())
+
+	input := doc.Components.Schemas["widgetInput"]
+	if input == nil {
+		t.Fatalf("Expected widgetInput component, got %v", keys(doc.Components.Schemas))
+	}
+	if !reflect.DeepEqual(input.Required, []string{"name", "kind"}) {
+		t.Errorf("Expected required [name kind], got %v", input.Required)
+	}
+	name := input.Properties["name"]
+	if *name.MinLength != 2 || *name.MaxLength != 50 {
+		t.Errorf("Expected length 2..50, got %+v", name)
+	}
+	if input.Properties["email"].Format != "email" {
+		t.Error("Expected email format")
+	}
+	if !reflect.DeepEqual(input.Properties["kind"].Enum, []interface{}{"small", "large"}) {
+		t.Errorf("Expected enum from oneof, got %v", input.Properties["kind"].Enum)
+	}
+	if !reflect.DeepEqual(input.Properties["note"].Type, []string{"string", "null"}) {
+		t.Errorf("Expected nullable pointer, got %v", input.Properties["note"].Type)
+	}
+
+	// Responses: fields without omitempty are always present
+	output := doc.Components.Schemas["widget"]
+	if !reflect.DeepEqual(output.Required, []string{"id", "name"}) {
+		t.Errorf("Expected required [id name], got %v", output.Required)
+	}
+
+	upload := doc.Paths["/upload"].Post.RequestBody.Content[fiber.MIMEMultipartForm].Schema
+	if upload.Properties["file"].ContentMediaType == "" || !reflect.DeepEqual(upload.Required, []string{"file"}) {
+		t.Errorf("Unexpected multipart schema: %+v", upload)
+	}
+
+	if _, err := doc.JSON(); err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+}
+
+func TestBuildRawResponse(t *testing.T) {
+	ops := testOperations()
+	ops["GET /widgets"] = Operation{Query: widgetQuery{}, ResponseType: "text/csv"}
+
+	doc, _ := buildTestDoc(t, ops)
+	content := doc.Paths["/widgets"].Get.Responses["200"].Content
+	if _, ok := content["text/csv"]; !ok || len(content) != 1 {
+		t.Errorf("Expected a text/csv response only, got %v", keys(content))
+	}
+}
+
+func TestBuildReportsDrift(t *testing.T) {
+	ops := testOperations()
+	delete(ops, "DELETE /files/*")
+	ops["GET /gone"] = Operation{}
+
+	doc, problems := buildTestDoc(t, ops)
+	expected := []string{
+		"documented route is not registered: GET /gone",
+		"undocumented route: DELETE /files/*",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected %v, got %v", expected, problems)
+	}
+	if _, ok := doc.Paths["/files/{path}"]; ok {
+		t.Error("Undocumented route must not be in the document")
+	}
+}
+
+func TestSpecHandler(t *testing.T) {
+	doc, _ := buildTestDoc(t, testOperations())
+	handler, err := SpecHandler(doc)
+	if err != nil {
+		t.Fatalf("SpecHandler failed: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/openapi.json", handler)
+	resp, err := app.Test(httptest.NewRequest("GET", "/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if body["openapi"] != Version {
+		t.Errorf("Expected openapi %s, got %v", Version, body["openapi"])
+	}
+}
+
+func keys[V any](m map[string]V) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
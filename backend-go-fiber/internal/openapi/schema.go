@@ -0,0 +1,331 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // string, or []string when nullable
+	Format               string             `json:"format,omitempty"`
+	ContentMediaType     string             `json:"contentMediaType,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// File marks a multipart form field as an uploaded file
+type File []byte
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	fileType    = reflect.TypeOf(File{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaMode decides which fields are required: request bodies require what
+// the validator requires, responses always contain fields without omitempty
+type schemaMode int
+
+const (
+	modeRequest schemaMode = iota
+	modeResponse
+)
+
+type componentKey struct {
+	t    reflect.Type
+	mode schemaMode
+}
+
+// schemaRegistry turns Go types into schemas and collects named structs
+// as components
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[componentKey]string
+	taken      map[string]componentKey
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		components: make(map[string]*Schema),
+		names:      make(map[componentKey]string),
+		taken:      make(map[string]componentKey),
+	}
+}
+
+// schemaFor returns the schema of v's type; named structs become $refs
+func (r *schemaRegistry) schemaFor(v interface{}, mode schemaMode) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	return r.typeSchema(reflect.TypeOf(v), mode)
+}
+
+func (r *schemaRegistry) typeSchema(t reflect.Type, mode schemaMode) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return nullable(r.typeSchema(t.Elem(), mode))
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case fileType:
+		return &Schema{Type: "string", ContentMediaType: "application/octet-stream"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: r.typeSchema(t.Elem(), mode)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.typeSchema(t.Elem(), mode)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t, mode)
+		}
+		return &Schema{Ref: "#/components/schemas/" + r.component(t, mode)}
+	default:
+		// interface{} and anything else: any value
+		return &Schema{}
+	}
+}
+
+// component registers a named struct and returns its component name
+func (r *schemaRegistry) component(t reflect.Type, mode schemaMode) string {
+	key := componentKey{t, mode}
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, ok := r.taken[name]; ok {
+		// Same name in another package, e.g. storage.FileInfo and admin.FileInfo
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+	}
+	if _, ok := r.taken[name]; ok && mode == modeRequest {
+		name += "Input"
+	}
+
+	r.names[key] = name
+	r.taken[name] = key
+	r.components[name] = &Schema{} // placeholder for recursive types
+	*r.components[name] = *r.structSchema(t, mode)
+	return name
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type, mode schemaMode) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t, mode)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type, mode schemaMode) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, like encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(s, embedded, mode)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := r.typeSchema(field.Type, mode)
+		required := applyValidateTag(fieldSchema, field.Type, field.Tag.Get("validate"))
+		if mode == modeResponse && !omitempty {
+			required = true
+		}
+
+		s.Properties[name] = fieldSchema
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// jsonName returns the field's JSON name ("" = Go name) and omitempty
+func jsonName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+// nullable allows null in addition to the schema
+func nullable(s *Schema) *Schema {
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+		return s
+	}
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	return s
+}
+
+// applyValidateTag translates go-playground/validator rules into schema
+// constraints and reports whether the field is required. Rules after "dive"
+// apply to elements and are not translated.
+func applyValidateTag(s *Schema, t reflect.Type, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]*$"
+		case "numeric":
+			s.Pattern = "^[-+]?[0-9]+(\\.[0-9]+)?$"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(t, value))
+			}
+		case "len":
+			setBound(s, t, "min", param)
+			setBound(s, t, "max", param)
+		case "min", "gte":
+			setBound(s, t, "min", param)
+		case "max", "lte":
+			setBound(s, t, "max", param)
+		case "gt":
+			setBound(s, t, "exclusiveMin", param)
+		case "lt":
+			setBound(s, t, "exclusiveMax", param)
+		}
+	}
+
+	// A nullable enum must list null, or null would fail validation
+	if types, ok := s.Type.([]string); ok && len(s.Enum) > 0 && len(types) == 2 {
+		s.Enum = append(s.Enum, nil)
+	}
+	return required
+}
+
+// setBound sets a length, item count or value bound depending on the kind
+func setBound(s *Schema, t reflect.Type, bound, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	count := int(n)
+
+	switch t.Kind() {
+	case reflect.String:
+		switch bound {
+		case "min":
+			s.MinLength = &count
+		case "max":
+			s.MaxLength = &count
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		switch bound {
+		case "min":
+			s.MinItems = &count
+		case "max":
+			s.MaxItems = &count
+		}
+	default:
+		switch bound {
+		case "min":
+			s.Minimum = &n
+		case "max":
+			s.Maximum = &n
+		case "exclusiveMin":
+			s.ExclusiveMinimum = &n
+		case "exclusiveMax":
+			s.ExclusiveMaximum = &n
+		}
+	}
+}
+
+func enumValue(t reflect.Type, value string) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
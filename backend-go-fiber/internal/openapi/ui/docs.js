@@ -0,0 +1,259 @@
+// Self-contained OpenAPI viewer: renders the document and lets you send requests.
+(function () {
+  'use strict';
+
+  var spec;
+  var tokenInput = document.getElementById('token');
+  tokenInput.value = sessionStorage.getItem('apiDocsToken') || '';
+  tokenInput.addEventListener('input', function () {
+    sessionStorage.setItem('apiDocsToken', tokenInput.value);
+  });
+
+  fetch(document.body.dataset.specUrl)
+    .then(function (res) { return res.json(); })
+    .then(function (doc) { spec = doc; render(); })
+    .catch(function (err) {
+      document.getElementById('operations').textContent = 'Failed to load the API document: ' + err;
+    });
+
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key === 'text') node.textContent = attrs[key];
+      else node.setAttribute(key, attrs[key]);
+    });
+    (children || []).forEach(function (child) { if (child) node.appendChild(child); });
+    return node;
+  }
+
+  function resolve(schema) {
+    var seen = 0;
+    while (schema && schema.$ref && seen++ < 32) {
+      schema = spec.components.schemas[schema.$ref.split('/').pop()];
+    }
+    return schema || {};
+  }
+
+  // merge flattens allOf and picks the non-null branch of anyOf
+  function merge(schema) {
+    schema = resolve(schema);
+    if (schema.anyOf) {
+      var branch = schema.anyOf.filter(function (s) { return s.type !== 'null'; })[0];
+      return Object.assign({ nullable: true }, merge(branch));
+    }
+    if (!schema.allOf) return schema;
+    var out = { type: 'object', properties: {}, required: [] };
+    schema.allOf.forEach(function (part) {
+      part = merge(part);
+      Object.assign(out.properties, part.properties || {});
+      out.required = out.required.concat(part.required || []);
+    });
+    return out;
+  }
+
+  function typeName(schema) {
+    var type = Array.isArray(schema.type) ? schema.type.join(' | ') : schema.type;
+    if (!type) type = 'any';
+    if (schema.nullable) type += ' | null';
+    return type;
+  }
+
+  function constraints(schema) {
+    var parts = [];
+    if (schema.format) parts.push(schema.format);
+    if (schema.enum) parts.push('one of ' + schema.enum.map(JSON.stringify).join(', '));
+    if (schema.minLength != null) parts.push('min length ' + schema.minLength);
+    if (schema.maxLength != null) parts.push('max length ' + schema.maxLength);
+    if (schema.minimum != null) parts.push('>= ' + schema.minimum);
+    if (schema.maximum != null) parts.push('<= ' + schema.maximum);
+    if (schema.minItems != null) parts.push('min items ' + schema.minItems);
+    if (schema.maxItems != null) parts.push('max items ' + schema.maxItems);
+    if (schema.pattern) parts.push('pattern ' + schema.pattern);
+    if (schema.contentMediaType) parts.push('file');
+    return parts.length ? '  // ' + parts.join(', ') : '';
+  }
+
+  // outline renders a schema as an indented type listing
+  function outline(schema, indent, depth) {
+    schema = merge(schema);
+    indent = indent || '';
+    depth = depth || 0;
+    if (depth > 6) return '…';
+    var type = Array.isArray(schema.type) ? schema.type[0] : schema.type;
+    if (type === 'array') {
+      return 'array of ' + outline(schema.items || {}, indent, depth + 1);
+    }
+    if (schema.properties) {
+      var required = schema.required || [];
+      var lines = Object.keys(schema.properties).map(function (name) {
+        var prop = merge(schema.properties[name]);
+        var propType = Array.isArray(prop.type) ? prop.type[0] : prop.type;
+        var value = (prop.properties || propType === 'array')
+          ? outline(prop, indent + '  ', depth + 1)
+          : typeName(prop) + constraints(prop);
+        return indent + '  ' + name + (required.indexOf(name) >= 0 ? '' : '?') + ': ' + value;
+      });
+      return '{\n' + lines.join('\n') + '\n' + indent + '}';
+    }
+    return typeName(schema) + constraints(schema);
+  }
+
+  // example builds a sample value for request bodies
+  function example(schema, depth) {
+    schema = merge(schema);
+    depth = depth || 0;
+    if (depth > 6) return null;
+    if (schema.enum) return schema.enum[0];
+    var type = Array.isArray(schema.type) ? schema.type[0] : schema.type;
+    if (schema.properties) {
+      var out = {};
+      Object.keys(schema.properties).forEach(function (name) {
+        out[name] = example(schema.properties[name], depth + 1);
+      });
+      return out;
+    }
+    switch (type) {
+      case 'array': return [example(schema.items || {}, depth + 1)];
+      case 'integer': case 'number': return schema.minimum || 0;
+      case 'boolean': return false;
+      case 'string':
+        if (schema.format === 'email') return 'user@example.com';
+        if (schema.format === 'date-time') return new Date().toISOString();
+        return 'a'.repeat(schema.minLength || 0) || 'string';
+      default: return null;
+    }
+  }
+
+  function render() {
+    document.title = spec.info.title;
+    document.getElementById('title').textContent = spec.info.title;
+    document.getElementById('version').textContent = spec.info.version + ' · ' + spec.servers[0].url;
+
+    var byTag = {};
+    Object.keys(spec.paths).sort().forEach(function (path) {
+      ['get', 'post', 'put', 'patch', 'delete'].forEach(function (method) {
+        var op = spec.paths[path][method];
+        if (!op) return;
+        var tag = (op.tags && op.tags[0]) || 'default';
+        (byTag[tag] = byTag[tag] || []).push({ path: path, method: method, op: op });
+      });
+    });
+
+    var nav = document.getElementById('nav');
+    var main = document.getElementById('operations');
+    main.textContent = '';
+    Object.keys(byTag).sort().forEach(function (tag) {
+      nav.appendChild(el('h3', { text: tag }));
+      main.appendChild(el('h2', { text: tag }));
+      byTag[tag].forEach(function (entry) {
+        nav.appendChild(el('a', { href: '#' + entry.op.operationId, text: entry.method.toUpperCase() + ' ' + entry.path }));
+        main.appendChild(renderOperation(entry.path, entry.method, entry.op));
+      });
+    });
+  }
+
+  function renderOperation(path, method, op) {
+    var body = el('div', { class: 'body' });
+    if (op.description) body.appendChild(el('p', { text: op.description }));
+
+    var inputs = {};
+    if (op.parameters && op.parameters.length) {
+      body.appendChild(el('h4', { text: 'Parameters' }));
+      var rows = op.parameters.map(function (param) {
+        var input = el('input', { placeholder: param.name });
+        inputs[param.in + ':' + param.name] = input;
+        return el('tr', {}, [
+          el('td', { text: param.name + (param.required ? ' *' : '') }),
+          el('td', { class: 'muted', text: param.in }),
+          el('td', { text: typeName(merge(param.schema)) + constraints(merge(param.schema)) }),
+          el('td', {}, [input])
+        ]);
+      });
+      body.appendChild(el('table', {}, rows));
+    }
+
+    var bodyInput, fileInputs = {};
+    if (op.requestBody) {
+      var contentType = Object.keys(op.requestBody.content)[0];
+      var schema = op.requestBody.content[contentType].schema;
+      body.appendChild(el('h4', { text: 'Request body (' + contentType + ')' }));
+      body.appendChild(el('pre', { text: outline(schema) }));
+      if (contentType === 'multipart/form-data') {
+        Object.keys(merge(schema).properties || {}).forEach(function (name) {
+          var input = el('input', { type: 'file', multiple: 'multiple' });
+          fileInputs[name] = input;
+          body.appendChild(el('div', {}, [el('span', { text: name + ' ' }), input]));
+        });
+      } else {
+        bodyInput = el('textarea');
+        bodyInput.value = JSON.stringify(example(schema), null, 2);
+        body.appendChild(bodyInput);
+      }
+    }
+
+    body.appendChild(el('h4', { text: 'Responses' }));
+    Object.keys(op.responses).sort().forEach(function (status) {
+      var response = op.responses[status];
+      var content = response.content && response.content['application/json'];
+      var details = el('details', {}, [
+        el('summary', { text: status + ' ' + response.description }),
+        content ? el('pre', { text: outline(content.schema) }) : null
+      ]);
+      body.appendChild(details);
+    });
+
+    var result = el('pre', { class: 'result', text: '' });
+    var send = el('button', { text: 'Send request' });
+    send.addEventListener('click', function () {
+      sendRequest(path, method, op, inputs, bodyInput, fileInputs, result);
+    });
+    body.appendChild(el('h4', { text: 'Try it' }));
+    body.appendChild(send);
+    body.appendChild(result);
+
+    var summary = el('summary', {}, [
+      el('span', { class: 'method ' + method, text: method.toUpperCase() }),
+      el('span', { class: 'path', text: path }),
+      el('span', { class: 'muted', text: op.summary || '' }),
+      op.security ? el('span', { class: 'lock', text: 'auth' }) : null
+    ]);
+    return el('details', { class: 'op' + (op.deprecated ? ' deprecated' : ''), id: op.operationId }, [summary, body]);
+  }
+
+  function sendRequest(path, method, op, inputs, bodyInput, fileInputs, result) {
+    var headers = {};
+    var query = [];
+    var url = path;
+    (op.parameters || []).forEach(function (param) {
+      var value = inputs[param.in + ':' + param.name].value;
+      if (value === '') return;
+      if (param.in === 'path') url = url.replace('{' + param.name + '}', encodeURIComponent(value));
+      else if (param.in === 'query') query.push(encodeURIComponent(param.name) + '=' + encodeURIComponent(value));
+      else if (param.in === 'header') headers[param.name] = value;
+    });
+    url = spec.servers[0].url + url + (query.length ? '?' + query.join('&') : '');
+
+    if (tokenInput.value) headers.Authorization = 'Bearer ' + tokenInput.value.replace(/^Bearer\s+/i, '');
+
+    var body;
+    if (bodyInput) {
+      headers['Content-Type'] = 'application/json';
+      body = bodyInput.value;
+    } else if (Object.keys(fileInputs).length) {
+      body = new FormData();
+      Object.keys(fileInputs).forEach(function (name) {
+        Array.prototype.forEach.call(fileInputs[name].files, function (file) { body.append(name, file); });
+      });
+    }
+
+    result.textContent = 'Sending…';
+    fetch(url, { method: method.toUpperCase(), headers: headers, body: body, credentials: 'same-origin' })
+      .then(function (res) {
+        return res.text().then(function (text) {
+          try { text = JSON.stringify(JSON.parse(text), null, 2); } catch (e) { /* not JSON */ }
+          result.textContent = res.status + ' ' + res.statusText + '\n\n' + text;
+        });
+      })
+      .catch(function (err) { result.textContent = 'Request failed: ' + err; });
+  }
+})();
@@ -0,0 +1,421 @@
+package routes
+
+import (
+	"fmt"
+
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/config"
+	"backend-go-fiber/internal/handlers"
+	adminHandlers "backend-go-fiber/internal/handlers/admin"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/openapi"
+	"backend-go-fiber/internal/services"
+	"backend-go-fiber/internal/services/admin"
+	"backend-go-fiber/internal/services/csp"
+	"backend-go-fiber/internal/services/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Types below only describe payloads that handlers build with fiber.Map
+
+// MessageResponse is returned by actions without a resource to return
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// RefreshResponse is returned by POST /auth/refresh
+type RefreshResponse struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresIn   int    `json:"expiresIn"`
+}
+
+// ValidateTokenResponse is returned by POST /auth/validate-reset-token
+type ValidateTokenResponse struct {
+	Valid bool   `json:"valid"`
+	Email string `json:"email"`
+}
+
+// UploadForm is the body of POST /upload
+type UploadForm struct {
+	File openapi.File `form:"file" validate:"required"`
+}
+
+// UploadMultipleForm is the body of POST /upload/multiple
+type UploadMultipleForm struct {
+	Files []openapi.File `form:"files" validate:"required,min=1,max=10"`
+}
+
+// UploadResponse is returned by POST /upload
+type UploadResponse struct {
+	File storage.FileInfo `json:"file"`
+}
+
+// UploadedFile is a stored file of a multiple upload
+type UploadedFile struct {
+	Filename string           `json:"filename"`
+	File     storage.FileInfo `json:"file"`
+}
+
+// UploadError is a rejected file of a multiple upload
+type UploadError struct {
+	Filename string `json:"filename"`
+	Error    string `json:"error"`
+}
+
+// UploadMultipleResponse is returned by POST /upload/multiple.
+// Both lists are null when empty.
+type UploadMultipleResponse struct {
+	Uploaded []UploadedFile `json:"uploaded"`
+	Errors   []UploadError  `json:"errors"`
+}
+
+// AcceptInvitationResponse is returned by POST /orgs/invitations/accept
+type AcceptInvitationResponse struct {
+	OrganizationID string         `json:"organizationId"`
+	Role           models.OrgRole `json:"role"`
+}
+
+// MemberRoleResponse is returned when a membership is created or changed
+type MemberRoleResponse struct {
+	UserID string         `json:"userId"`
+	Role   models.OrgRole `json:"role"`
+}
+
+// UsersQuery are the query parameters of GET /admin/users
+type UsersQuery struct {
+	Page     int    `json:"page" doc:"Page number (default 1)"`
+	PageSize int    `json:"pageSize" doc:"Items per page (default 10, max 100)"`
+	Search   string `json:"search" doc:"Matches email and name"`
+	SortBy   string `json:"sortBy" doc:"Sort column (default created_at)"`
+	SortDir  string `json:"sortDir" validate:"omitempty,oneof=asc desc" doc:"Sort direction (default desc)"`
+	Role     string `json:"role" validate:"omitempty,oneof=user admin"`
+	IsActive bool   `json:"isActive"`
+}
+
+// OrganizationsQuery are the query parameters of GET /admin/organizations
+type OrganizationsQuery struct {
+	Page     int    `json:"page" doc:"Page number (default 1)"`
+	PageSize int    `json:"pageSize" doc:"Items per page (default 10, max 100)"`
+	Search   string `json:"search" doc:"Matches name and slug"`
+}
+
+// FilesQuery are the query parameters of GET /admin/files
+type FilesQuery struct {
+	Dir string `json:"dir" doc:"Subdirectory of the upload directory"`
+}
+
+// SettingsQuery are the query parameters of GET /admin/settings
+type SettingsQuery struct {
+	Group string `json:"group" doc:"Only settings of this group"`
+}
+
+// CSPReportsQuery are the query parameters of GET /admin/csp-reports
+type CSPReportsQuery struct {
+	Page      int    `json:"page" doc:"Page number (default 1)"`
+	PageSize  int    `json:"pageSize" doc:"Items per page (default 20, max 100)"`
+	Directive string `json:"directive" doc:"Only violations of this directive (script-src-elem)"`
+	Search    string `json:"search" doc:"Matches the blocked, document and source URIs"`
+}
+
+// AuditQuery are the query parameters of GET /admin/audit and /admin/audit/export
+type AuditQuery struct {
+	Page       int    `json:"page" doc:"Page number (default 1, ignored by export)"`
+	PageSize   int    `json:"pageSize" doc:"Items per page (default 20, max 100, ignored by export)"`
+	Action     string `json:"action" doc:"Only this action (user.update, auth.login_failed)"`
+	ActorID    string `json:"actorId" doc:"Only entries by this user"`
+	TargetType string `json:"targetType" doc:"Only entries about user, setting or file targets"`
+	TargetID   string `json:"targetId" doc:"Only entries about this target (user ID, setting key, file path)"`
+	From       string `json:"from" doc:"Entries at or after this time (RFC 3339 or YYYY-MM-DD)"`
+	To         string `json:"to" doc:"Entries before this time (RFC 3339 or YYYY-MM-DD)"`
+	Search     string `json:"search" doc:"Matches the actor email, target ID, IP and request ID"`
+}
+
+// CSPReportsCleared is returned by DELETE /admin/csp-reports
+type CSPReportsCleared struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// adminErrors are returned by every admin route besides 401
+var adminErrors = []int{fiber.StatusForbidden}
+
+// Docs describes every API route for the OpenAPI document.
+// Keys are relative to the version prefix (/api/v1).
+var Docs = openapi.Operations{
+	// Auth
+	"POST /auth/register": {
+		Summary: "Register a new account", Tags: []string{"Auth"},
+		Description: "Creates a user and signs them in. The refresh token is set as an httpOnly cookie.",
+		Body:        services.RegisterInput{}, Response: services.AuthResult{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusConflict},
+	},
+	"POST /auth/login": {
+		Summary: "Sign in", Tags: []string{"Auth"},
+		Description: "The refresh token is set as an httpOnly cookie.",
+		Body:        services.LoginInput{}, Response: services.AuthResult{},
+		Errors: []int{fiber.StatusUnauthorized, fiber.StatusForbidden},
+	},
+	"POST /auth/refresh": {
+		Summary: "Refresh the access token", Tags: []string{"Auth"},
+		Description: "Uses the refresh token cookie.",
+		Response:    RefreshResponse{},
+		Errors:      []int{fiber.StatusUnauthorized, fiber.StatusForbidden},
+	},
+	"POST /auth/logout": {
+		Summary: "Sign out", Tags: []string{"Auth"},
+		Description: "Revokes the refresh token and clears its cookie.",
+		Response:    MessageResponse{},
+	},
+	"GET /auth/me": {
+		Summary: "Get the current user", Tags: []string{"Auth"}, Auth: true,
+		Response: models.UserResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"PUT /auth/profile": {
+		Summary: "Update the current user's profile", Tags: []string{"Auth"}, Auth: true,
+		Body: services.UpdateProfileInput{}, Response: models.UserResponse{},
+		Errors: []int{fiber.StatusForbidden},
+	},
+	"PUT /auth/change-password": {
+		Summary: "Change the current user's password", Tags: []string{"Auth"}, Auth: true,
+		Body: services.ChangePasswordInput{}, Response: MessageResponse{},
+		Errors: []int{fiber.StatusForbidden},
+	},
+	"POST /auth/forgot-password": {
+		Summary: "Request a password reset email", Tags: []string{"Auth"},
+		Description: "Always succeeds, so it can't be used to find registered emails.",
+		Body:        handlers.ForgotPasswordRequest{}, Response: MessageResponse{},
+	},
+	"POST /auth/validate-reset-token": {
+		Summary: "Check a password reset token", Tags: []string{"Auth"},
+		Body: handlers.ValidateTokenRequest{}, Response: ValidateTokenResponse{},
+	},
+	"POST /auth/reset-password": {
+		Summary: "Reset the password with a reset token", Tags: []string{"Auth"},
+		Body: handlers.ResetPasswordRequest{}, Response: MessageResponse{},
+	},
+
+	// Uploads
+	"POST /upload": {
+		Summary: "Upload a file", Tags: []string{"Uploads"}, Auth: true,
+		Body: UploadForm{}, Multipart: true, Response: UploadResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden}, Idempotent: true,
+	},
+	"POST /upload/multiple": {
+		Summary: "Upload up to 10 files", Tags: []string{"Uploads"}, Auth: true,
+		Description: "Files that fail are listed in errors; the others are still stored.",
+		Body:        UploadMultipleForm{}, Multipart: true, Response: UploadMultipleResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden}, Idempotent: true,
+	},
+	"DELETE /upload/*": {
+		Summary: "Delete an uploaded file", Tags: []string{"Uploads"}, Auth: true,
+		Description: "The path is the file key.",
+		Response:    MessageResponse{}, Errors: []int{fiber.StatusForbidden},
+	},
+
+	// Organizations
+	"GET /orgs": {
+		Summary: "List the current user's organizations", Tags: []string{"Organizations"}, Auth: true,
+		Response: []models.UserOrganizationResponse{}, Errors: []int{fiber.StatusForbidden},
+	},
+	"POST /orgs": {
+		Summary: "Create an organization", Tags: []string{"Organizations"}, Auth: true,
+		Body: services.CreateOrganizationInput{}, Response: models.OrganizationResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden, fiber.StatusConflict},
+	},
+	"POST /orgs/switch": {
+		Summary: "Switch the active organization", Tags: []string{"Organizations"}, Auth: true,
+		Description: "Returns a new access token scoped to the organization.",
+		Body:        services.SwitchOrganizationInput{}, Response: services.AuthResult{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"POST /orgs/invitations/accept": {
+		Summary: "Accept an invitation", Tags: []string{"Organizations"}, Auth: true,
+		Body: services.AcceptInvitationInput{}, Response: AcceptInvitationResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	"GET /orgs/current/members": {
+		Summary: "List members of the active organization", Tags: []string{"Organizations"}, Auth: true,
+		Response: []models.MemberResponse{}, Errors: []int{fiber.StatusForbidden},
+	},
+	"PUT /orgs/current/members/:userId": {
+		Summary: "Change a member's role", Tags: []string{"Organizations"}, Auth: true,
+		Description: "Requires the admin role in the organization.",
+		Body:        services.UpdateMemberRoleInput{}, Response: MemberRoleResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"DELETE /orgs/current/members/:userId": {
+		Summary: "Remove a member or leave the organization", Tags: []string{"Organizations"}, Auth: true,
+		Response: MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"GET /orgs/current/invitations": {
+		Summary: "List pending invitations", Tags: []string{"Organizations"}, Auth: true,
+		Response: []models.InvitationResponse{}, Errors: []int{fiber.StatusForbidden},
+	},
+	"POST /orgs/current/invitations": {
+		Summary: "Invite a member by email", Tags: []string{"Organizations"}, Auth: true,
+		Body: services.InviteMemberInput{}, Response: models.InvitationResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden, fiber.StatusConflict},
+	},
+	"DELETE /orgs/current/invitations/:id": {
+		Summary: "Revoke an invitation", Tags: []string{"Organizations"}, Auth: true,
+		Response: MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+
+	// Admin
+	"GET /admin/dashboard": {
+		Summary: "Get dashboard statistics", Tags: []string{"Admin"}, Auth: true,
+		Response: admin.DashboardStats{}, Errors: adminErrors,
+	},
+	"GET /admin/users": {
+		Summary: "List users", Tags: []string{"Admin"}, Auth: true,
+		Query: UsersQuery{}, Response: admin.ListResult{}, Errors: adminErrors,
+	},
+	"GET /admin/users/:id": {
+		Summary: "Get a user", Tags: []string{"Admin"}, Auth: true,
+		Response: models.AdminUserResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"POST /admin/users": {
+		Summary: "Create a user", Tags: []string{"Admin"}, Auth: true,
+		Body: admin.CreateUserInput{}, Response: models.AdminUserResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden, fiber.StatusConflict}, Idempotent: true,
+	},
+	"PUT /admin/users/:id": {
+		Summary: "Update a user", Tags: []string{"Admin"}, Auth: true,
+		Body: admin.UpdateUserInput{}, Response: models.AdminUserResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict}, IfMatch: true,
+	},
+	"DELETE /admin/users/:id": {
+		Summary: "Delete a user", Tags: []string{"Admin"}, Auth: true,
+		Response: MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"POST /admin/users/:id/suspend": {
+		Summary: "Suspend a user", Tags: []string{"Admin"}, Auth: true,
+		Description: "Revokes the user's sessions.",
+		Body:        admin.SuspendUserInput{}, Response: models.AdminUserResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"POST /admin/users/:id/unsuspend": {
+		Summary: "Lift a suspension", Tags: []string{"Admin"}, Auth: true,
+		Response: models.AdminUserResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"GET /admin/organizations": {
+		Summary: "List organizations", Tags: []string{"Admin"}, Auth: true,
+		Query: OrganizationsQuery{}, Response: admin.OrganizationListResult{}, Errors: adminErrors,
+	},
+	"GET /admin/organizations/:id": {
+		Summary: "Get an organization", Tags: []string{"Admin"}, Auth: true,
+		Response: admin.AdminOrganizationResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"PUT /admin/organizations/:id": {
+		Summary: "Update an organization", Tags: []string{"Admin"}, Auth: true,
+		Body: admin.UpdateOrganizationInput{}, Response: admin.AdminOrganizationResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	"DELETE /admin/organizations/:id": {
+		Summary: "Delete an organization", Tags: []string{"Admin"}, Auth: true,
+		Response: MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"GET /admin/organizations/:id/members": {
+		Summary: "List members of an organization", Tags: []string{"Admin"}, Auth: true,
+		Response: []models.MemberResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"POST /admin/organizations/:id/members": {
+		Summary: "Add a member to an organization", Tags: []string{"Admin"}, Auth: true,
+		Body: admin.AddMemberInput{}, Response: MemberRoleResponse{},
+		Status: fiber.StatusCreated, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict},
+	},
+	"PUT /admin/organizations/:id/members/:userId": {
+		Summary: "Change a member's role", Tags: []string{"Admin"}, Auth: true,
+		Body: admin.SetMemberRoleInput{}, Response: MemberRoleResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"DELETE /admin/organizations/:id/members/:userId": {
+		Summary: "Remove a member from an organization", Tags: []string{"Admin"}, Auth: true,
+		Response: MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"GET /admin/files": {
+		Summary: "List uploaded files", Tags: []string{"Admin"}, Auth: true,
+		Query: FilesQuery{}, Response: adminHandlers.ListFilesResult{}, Errors: adminErrors,
+	},
+	"DELETE /admin/files/*": {
+		Summary: "Delete an uploaded file", Tags: []string{"Admin"}, Auth: true,
+		Description: "The path is relative to the upload directory.",
+		Response:    MessageResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"GET /admin/settings": {
+		Summary: "List settings", Tags: []string{"Admin"}, Auth: true,
+		Query: SettingsQuery{}, Response: []models.AppSettingsResponse{}, Errors: adminErrors,
+	},
+	"GET /admin/settings/:key": {
+		Summary: "Get a setting", Tags: []string{"Admin"}, Auth: true,
+		Response: models.AppSettingsResponse{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+	},
+	"PUT /admin/settings/:key": {
+		Summary: "Update a setting", Tags: []string{"Admin"}, Auth: true,
+		Body: adminHandlers.UpdateInput{}, Response: models.AppSettingsResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}, IfMatch: true,
+	},
+	"PUT /admin/settings": {
+		Summary: "Update several settings at once", Tags: []string{"Admin"}, Auth: true,
+		Description: "All changes are applied in one transaction. If-Match is checked against the full list.",
+		Body:        admin.UpdateBatchInput{}, Response: []models.AppSettingsResponse{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}, IfMatch: true,
+	},
+	"GET /admin/csp-reports": {
+		Summary: "List CSP violations", Tags: []string{"Admin"}, Auth: true,
+		Description: "Distinct violations, most recently seen first.",
+		Query:       CSPReportsQuery{}, Response: admin.CSPReportListResult{}, Errors: adminErrors,
+	},
+	"DELETE /admin/csp-reports": {
+		Summary: "Clear CSP violations", Tags: []string{"Admin"}, Auth: true,
+		Response: CSPReportsCleared{}, Errors: adminErrors,
+	},
+	"GET /admin/audit": {
+		Summary: "List the audit log", Tags: []string{"Admin"}, Auth: true,
+		Description: "Admin mutations and security events, newest first. changes holds the fields that differ " +
+			"between before and after (from is null for creations, to for deletions).",
+		Query: AuditQuery{}, Response: admin.AuditListResult{}, Errors: adminErrors,
+	},
+	"GET /admin/audit/export": {
+		Summary: "Export the audit log as CSV", Tags: []string{"Admin"}, Auth: true,
+		Description: fmt.Sprintf("Entries matching the filters, newest first, at most %d. "+
+			"changes and metadata are JSON encoded.", admin.MaxAuditExportRows),
+		Query: AuditQuery{}, ResponseType: "text/csv", Errors: adminErrors,
+	},
+	"POST /admin/config/reload": {
+		Summary: "Reload configuration", Tags: []string{"Admin"}, Auth: true,
+		Description: "Re-reads the config file and environment like SIGHUP. Invalid configuration is rejected " +
+			"and the current one kept. Keys only read at startup are listed in restartRequired.",
+		Response: config.ReloadResult{}, Errors: []int{fiber.StatusForbidden, fiber.StatusUnprocessableEntity},
+	},
+
+	// Security
+	"POST /csp-report": {
+		Summary: "Report CSP violations", Tags: []string{"Security"},
+		Description: "Sent by browsers: a report-uri document ({\"csp-report\": {...}}) or a report-to batch. " +
+			"Identical violations are counted once.",
+		Body: map[string]interface{}{}, BodyTypes: []string{csp.MIMECSPReport, csp.MIMEReportsJSON, fiber.MIMEApplicationJSON},
+		Status: fiber.StatusNoContent, Errors: []int{fiber.StatusRequestEntityTooLarge, fiber.StatusTooManyRequests},
+	},
+}
+
+// Spec builds the OpenAPI document of the latest API version from the routes
+// registered on app. Problems list routes and Docs entries that don't match.
+func Spec(app *fiber.App, versions *apiversion.Registry) (*openapi.Document, []string) {
+	latest := versions.Latest().Name
+	return openapi.Build(openapi.Config{
+		Title:       "backend-go-fiber API",
+		Description: fmt.Sprintf("Routes are also served unversioned under %s (latest version, or the one in the %s header).", apiversion.Prefix, apiversion.Header),
+		Version:     latest,
+		Prefix:      apiversion.Prefix + "/" + latest,
+	}, app.GetRoutes(true), Docs)
+}
+
+// DocsApp returns an app with the API routes mounted on nil handlers. It is
+// enough to build the document without a database or configuration.
+func DocsApp(versions *apiversion.Registry) *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	next := func(c *fiber.Ctx) error { return c.Next() }
+	Mount(app, versions, Handlers{ActiveAccount: next, Idempotent: next, CSPReportLimit: next})
+	return app
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDocsCoverRoutes(t *testing.T) {
+	versions, err := Versions()
+	if err != nil {
+		t.Fatalf("Versions failed: %v", err)
+	}
+
+	doc, problems := Spec(DocsApp(versions), versions)
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	committed, err := os.ReadFile("../../api/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read committed document: %v", err)
+	}
+	if !bytes.Equal(data, committed) {
+		t.Error("api/openapi.json is out of date, run: go run ./cmd/openapi")
+	}
+}
@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"backend-go-fiber/internal/apiversion"
+	"backend-go-fiber/internal/handlers"
+	adminHandlers "backend-go-fiber/internal/handlers/admin"
+	"backend-go-fiber/internal/middleware"
+	"backend-go-fiber/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Handlers holds the handlers and shared middleware the API routes are wired to
+type Handlers struct {
+	DB *gorm.DB
+
+	// ActiveAccount blocks suspended users (middleware.ActiveAccount)
+	ActiveAccount fiber.Handler
+
+	// Idempotent enables Idempotency-Key replay (middleware.Idempotency)
+	Idempotent fiber.Handler
+
+	// CSPReportLimit rate limits CSP violation reports (csp.ReportRateLimit)
+	CSPReportLimit fiber.Handler
+
+	Auth          *handlers.AuthHandler
+	PasswordReset *handlers.PasswordResetHandler
+	Upload        *handlers.UploadHandler
+	Organization  *handlers.OrganizationHandler
+	CSPReport     *handlers.CSPReportHandler
+
+	Dashboard     *adminHandlers.DashboardHandler
+	Users         *adminHandlers.UsersHandler
+	Files         *adminHandlers.FilesHandler
+	Settings      *adminHandlers.SettingsHandler
+	Organizations *adminHandlers.OrganizationsHandler
+	CSPReports    *adminHandlers.CSPReportsHandler
+	Config        *adminHandlers.ConfigHandler
+	Audit         *adminHandlers.AuditHandler
+}
+
+// Versions returns the API versions, oldest first.
+//
+// To add a version: append it here, give changed routes per-version handlers
+// with versions.Switch(apiversion.Handlers{...}), and deprecate the old version:
+//
+//	apiversion.Version{Name: "v1", Deprecated: &apiversion.Deprecation{Since: ..., Sunset: ...}}
+func Versions() (*apiversion.Registry, error) {
+	return apiversion.NewRegistry(
+		apiversion.Version{Name: "v1"},
+	)
+}
+
+// Mount registers the API under /api and under /api/<version> for every version
+func Mount(app *fiber.App, versions *apiversion.Registry, h Handlers) {
+	RegisterAPI(app.Group(apiversion.Prefix), h)
+	for _, version := range versions.Versions() {
+		RegisterAPI(app.Group(apiversion.Prefix+"/"+version.Name), h)
+	}
+}
+
+// RegisterAPI registers the API routes on api.
+// Every route must be described in Docs, or the OpenAPI check fails.
+func RegisterAPI(api fiber.Router, h Handlers) {
+	// Auth routes: /api/auth/*
+	auth := api.Group("/auth")
+	auth.Post("/register", h.Auth.Register)
+	auth.Post("/login", h.Auth.Login)
+	auth.Post("/refresh", h.Auth.Refresh)
+	auth.Post("/logout", h.Auth.Logout)
+	auth.Get("/me", middleware.AuthMiddleware(), h.ActiveAccount, h.Auth.Me)
+	auth.Put("/profile", middleware.AuthMiddleware(), h.ActiveAccount, h.Auth.UpdateProfile)
+	auth.Put("/change-password", middleware.AuthMiddleware(), h.ActiveAccount, h.Auth.ChangePassword)
+
+	// Password reset routes: /api/auth/*
+	auth.Post("/forgot-password", h.PasswordReset.ForgotPassword)
+	auth.Post("/validate-reset-token", h.PasswordReset.ValidateToken)
+	auth.Post("/reset-password", h.PasswordReset.ResetPassword)
+
+	// Upload routes: /api/upload/*
+	uploads := api.Group("/upload")
+	uploads.Post("/", middleware.AuthMiddleware(), h.ActiveAccount, h.Idempotent, h.Upload.UploadSingle)
+	uploads.Post("/multiple", middleware.AuthMiddleware(), h.ActiveAccount, h.Idempotent, h.Upload.UploadMultiple)
+	uploads.Delete("/*", middleware.AuthMiddleware(), h.ActiveAccount, h.Upload.Delete)
+
+	// Organization routes: /api/orgs/*
+	orgs := api.Group("/orgs", middleware.AuthMiddleware(), h.ActiveAccount)
+	orgs.Get("/", h.Organization.List)
+	orgs.Post("/", h.Organization.Create)
+	orgs.Post("/switch", h.Organization.Switch)
+	orgs.Post("/invitations/accept", h.Organization.AcceptInvitation)
+
+	// Active organization: /api/orgs/current/*
+	currentOrg := orgs.Group("/current", middleware.OrgMember(h.DB))
+	currentOrg.Get("/members", h.Organization.ListMembers)
+	currentOrg.Put("/members/:userId", middleware.RequireOrgRole(models.OrgRoleAdmin), h.Organization.UpdateMember)
+	currentOrg.Delete("/members/:userId", h.Organization.RemoveMember)
+	currentOrg.Get("/invitations", middleware.RequireOrgRole(models.OrgRoleAdmin), h.Organization.ListInvitations)
+	currentOrg.Post("/invitations", middleware.RequireOrgRole(models.OrgRoleAdmin), h.Organization.Invite)
+	currentOrg.Delete("/invitations/:id", middleware.RequireOrgRole(models.OrgRoleAdmin), h.Organization.RevokeInvitation)
+
+	// CSP violation reports from browsers: /api/csp-report
+	api.Post("/csp-report", h.CSPReportLimit, h.CSPReport.Report)
+
+	// Admin routes group with auth + admin middleware
+	adminGroup := api.Group("/admin", middleware.AuthMiddleware(), middleware.AdminOnly(h.DB))
+
+	// Dashboard
+	adminGroup.Get("/dashboard", h.Dashboard.GetStats)
+
+	// Users CRUD
+	adminGroup.Get("/users", h.Users.List)
+	adminGroup.Get("/users/:id", h.Users.Get)
+	adminGroup.Post("/users", h.Idempotent, h.Users.Create)
+	adminGroup.Put("/users/:id", h.Users.Update)
+	adminGroup.Delete("/users/:id", h.Users.Delete)
+	adminGroup.Post("/users/:id/suspend", h.Users.Suspend)
+	adminGroup.Post("/users/:id/unsuspend", h.Users.Unsuspend)
+
+	// Organizations
+	adminGroup.Get("/organizations", h.Organizations.List)
+	adminGroup.Get("/organizations/:id", h.Organizations.Get)
+	adminGroup.Put("/organizations/:id", h.Organizations.Update)
+	adminGroup.Delete("/organizations/:id", h.Organizations.Delete)
+	adminGroup.Get("/organizations/:id/members", h.Organizations.ListMembers)
+	adminGroup.Post("/organizations/:id/members", h.Organizations.AddMember)
+	adminGroup.Put("/organizations/:id/members/:userId", h.Organizations.UpdateMember)
+	adminGroup.Delete("/organizations/:id/members/:userId", h.Organizations.RemoveMember)
+
+	// Files
+	adminGroup.Get("/files", h.Files.List)
+	adminGroup.Delete("/files/*", h.Files.Delete)
+
+	// Settings
+	adminGroup.Get("/settings", h.Settings.GetAll)
+	adminGroup.Get("/settings/:key", h.Settings.Get)
+	adminGroup.Put("/settings/:key", h.Settings.Update)
+	adminGroup.Put("/settings", h.Settings.UpdateBatch)
+
+	// CSP violation reports
+	adminGroup.Get("/csp-reports", h.CSPReports.List)
+	adminGroup.Delete("/csp-reports", h.CSPReports.Clear)
+
+	// Configuration reload (same as SIGHUP)
+	adminGroup.Post("/config/reload", h.Config.Reload)
+
+	// Audit log
+	adminGroup.Get("/audit", h.Audit.List)
+	adminGroup.Get("/audit/export", h.Audit.Export)
+
+	// ==========================================================================
+	// Add your routes here
+	// ==========================================================================
+	// Example:
+	// users := api.Group("/users")
+	// users.Get("/", middleware.AuthMiddleware(), userHandler.List)
+	// users.Get("/:id", middleware.AuthMiddleware(), userHandler.Get)
+	// ==========================================================================
+}
@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MaxAuditExportRows bounds one CSV export; narrow the filters for more
+const MaxAuditExportRows = 50000
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// AuditListParams contains pagination and filtering parameters
+type AuditListParams struct {
+	Page       int        `json:"page"`
+	PageSize   int        `json:"pageSize"`
+	Action     string     `json:"action"`
+	ActorID    string     `json:"actorId"`
+	TargetType string     `json:"targetType"`
+	TargetID   string     `json:"targetId"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+	Search     string     `json:"search"`
+}
+
+// AuditListResult contains paginated list result
+type AuditListResult struct {
+	Items      []models.AuditLog `json:"items"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+	TotalPages int               `json:"totalPages"`
+}
+
+// query applies the filters of params
+func (s *AuditService) query(ctx context.Context, params AuditListParams) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&models.AuditLog{})
+
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+	if params.ActorID != "" {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+	if params.TargetType != "" {
+		query = query.Where("target_type = ?", params.TargetType)
+	}
+	if params.TargetID != "" {
+		query = query.Where("target_id = ?", params.TargetID)
+	}
+	if params.From != nil {
+		query = query.Where("created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("created_at < ?", *params.To)
+	}
+
+	// Search filter (escape wildcards to prevent SQL injection)
+	if params.Search != "" {
+		searchPattern := "%" + escapeLikeWildcards(params.Search) + "%"
+		query = query.Where("actor_email LIKE ? ESCAPE '\\' OR target_id LIKE ? ESCAPE '\\' OR ip LIKE ? ESCAPE '\\' OR request_id LIKE ? ESCAPE '\\'",
+			searchPattern, searchPattern, searchPattern, searchPattern)
+	}
+
+	return query
+}
+
+// List returns audit entries, newest first
+func (s *AuditService) List(ctx context.Context, params AuditListParams) (*AuditListResult, error) {
+	// Defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	query := s.query(ctx, params)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.AuditLog, 0)
+	offset := (params.Page - 1) * params.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(params.PageSize).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+
+	return &AuditListResult{
+		Items:      entries,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Recent returns the latest limit entries
+func (s *AuditService) Recent(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	entries := make([]models.AuditLog, 0, limit)
+	err := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// auditCSVHeader are the columns of Export
+var auditCSVHeader = []string{
+	"id", "created_at", "action", "actor_id", "actor_email", "target_type", "target_id",
+	"changes", "metadata", "ip", "request_id", "user_agent",
+}
+
+// Export writes the entries matching params (pagination is ignored) to w as
+// CSV, newest first and at most MaxAuditExportRows. Changes and metadata are
+// JSON encoded.
+func (s *AuditService) Export(ctx context.Context, params AuditListParams, w io.Writer) error {
+	rows, err := s.query(ctx, params).Order("created_at DESC").Limit(MaxAuditExportRows).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	out := csv.NewWriter(w)
+	if err := out.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var entry models.AuditLog
+		if err := s.db.ScanRows(rows, &entry); err != nil {
+			return err
+		}
+
+		actorID := ""
+		if entry.ActorID != nil {
+			actorID = *entry.ActorID
+		}
+		changes, err := jsonColumn(entry.Changes)
+		if err != nil {
+			return err
+		}
+		metadata, err := jsonColumn(entry.Metadata)
+		if err != nil {
+			return err
+		}
+
+		if err := out.Write([]string{
+			entry.ID,
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Action,
+			actorID,
+			csvSafe(entry.ActorEmail),
+			entry.TargetType,
+			csvSafe(entry.TargetID),
+			changes,
+			metadata,
+			entry.IP,
+			entry.RequestID,
+			csvSafe(entry.UserAgent),
+		}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// jsonColumn encodes a changes or metadata map, empty when there is none
+func jsonColumn[T ~map[string]V, V any](v T) (string, error) {
+	if len(v) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// csvSafe keeps spreadsheets from evaluating user controlled values as
+// formulas (CSV injection)
+func csvSafe(value string) string {
+	if value != "" && (value[0] == '=' || value[0] == '+' || value[0] == '-' || value[0] == '@' || value[0] == '\t' || value[0] == '\r') {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/audit"
+)
+
+func seedAuditLog(t *testing.T, service *AuditService) {
+	t.Helper()
+	adminID := "admin-1"
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []models.AuditLog{
+		{Action: audit.ActionLogin, ActorID: &adminID, ActorEmail: "admin@example.com", TargetType: audit.TargetUser, TargetID: adminID, CreatedAt: base},
+		{Action: audit.ActionUserUpdate, ActorID: &adminID, ActorEmail: "admin@example.com", TargetType: audit.TargetUser, TargetID: "user-1",
+			Changes: models.AuditChanges{"role": {From: "user", To: "admin"}}, CreatedAt: base.Add(time.Hour)},
+		{Action: audit.ActionLoginFailed, ActorEmail: "=HYPERLINK(\"x\")", IP: "198.51.100.9",
+			Metadata: models.AuditMetadata{"reason": "invalid_credentials"}, CreatedAt: base.Add(2 * time.Hour)},
+	}
+	for i := range entries {
+		if err := service.db.Create(&entries[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditList(t *testing.T) {
+	service := NewAuditService(setupTestDB(t))
+	seedAuditLog(t, service)
+
+	from := time.Date(2026, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		params   AuditListParams
+		expected []string
+	}{
+		{"newest first", AuditListParams{}, []string{audit.ActionLoginFailed, audit.ActionUserUpdate, audit.ActionLogin}},
+		{"action", AuditListParams{Action: audit.ActionUserUpdate}, []string{audit.ActionUserUpdate}},
+		{"actor", AuditListParams{ActorID: "admin-1"}, []string{audit.ActionUserUpdate, audit.ActionLogin}},
+		{"target", AuditListParams{TargetType: audit.TargetUser, TargetID: "user-1"}, []string{audit.ActionUserUpdate}},
+		{"from", AuditListParams{From: &from}, []string{audit.ActionLoginFailed, audit.ActionUserUpdate}},
+		{"to", AuditListParams{To: &from}, []string{audit.ActionLogin}},
+		{"search ip", AuditListParams{Search: "198.51.100"}, []string{audit.ActionLoginFailed}},
+		{"search escapes wildcards", AuditListParams{Search: "%"}, []string{}},
+		{"page", AuditListParams{Page: 2, PageSize: 2}, []string{audit.ActionLogin}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.List(context.Background(), tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actions := make([]string, len(result.Items))
+			for i, item := range result.Items {
+				actions[i] = item.Action
+			}
+			if len(actions) != len(tt.expected) {
+				t.Fatalf("actions = %v, expected %v", actions, tt.expected)
+			}
+			for i := range actions {
+				if actions[i] != tt.expected[i] {
+					t.Fatalf("actions = %v, expected %v", actions, tt.expected)
+				}
+			}
+		})
+	}
+
+	result, _ := service.List(context.Background(), AuditListParams{PageSize: 2})
+	if result.Total != 3 || result.TotalPages != 2 {
+		t.Errorf("total = %d, pages = %d", result.Total, result.TotalPages)
+	}
+	if c := result.Items[1].Changes["role"]; c.From != "user" || c.To != "admin" {
+		t.Errorf("changes = %v", result.Items[1].Changes)
+	}
+}
+
+func TestAuditExport(t *testing.T) {
+	service := NewAuditService(setupTestDB(t))
+	seedAuditLog(t, service)
+
+	var buf bytes.Buffer
+	if err := service.Export(context.Background(), AuditListParams{PageSize: 1}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("records = %v, expected the header and every entry", records)
+	}
+	if records[0][2] != "action" || records[1][2] != audit.ActionLoginFailed {
+		t.Errorf("records = %v", records)
+	}
+
+	failed := records[1]
+	if failed[4] != `'=HYPERLINK("x")` {
+		t.Errorf("formula not neutralized: %q", failed[4])
+	}
+	if failed[8] != `{"reason":"invalid_credentials"}` || failed[3] != "" {
+		t.Errorf("failed login = %v", failed)
+	}
+	if update := records[2]; update[7] != `{"role":{"from":"user","to":"admin"}}` {
+		t.Errorf("changes = %q", update[7])
+	}
+}
+
+func TestDashboardRecentActivity(t *testing.T) {
+	db := setupTestDB(t)
+	auditService := NewAuditService(db)
+	seedAuditLog(t, auditService)
+
+	stats, err := NewDashboardService(db, auditService).GetStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.RecentActivity) != 3 {
+		t.Fatalf("activity = %+v", stats.RecentActivity)
+	}
+	if latest := stats.RecentActivity[0]; latest.Type != "warning" || latest.Message != "Failed sign-in" {
+		t.Errorf("latest = %+v", latest)
+	}
+	if update := stats.RecentActivity[1]; update.Type != "info" || update.TargetID != "user-1" || update.ActorEmail != "admin@example.com" {
+		t.Errorf("update = %+v", update)
+	}
+}
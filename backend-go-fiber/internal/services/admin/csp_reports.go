@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"context"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CSPReportsService struct {
+	db *gorm.DB
+}
+
+func NewCSPReportsService(db *gorm.DB) *CSPReportsService {
+	return &CSPReportsService{db: db}
+}
+
+// CSPReportListParams contains pagination and filtering parameters
+type CSPReportListParams struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"pageSize"`
+	Directive string `json:"directive"`
+	Search    string `json:"search"`
+}
+
+// CSPReportListResult contains paginated list result
+type CSPReportListResult struct {
+	Items      []models.CSPReport `json:"items"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"pageSize"`
+	TotalPages int                `json:"totalPages"`
+}
+
+// List returns violation reports, most recently seen first
+func (s *CSPReportsService) List(ctx context.Context, params CSPReportListParams) (*CSPReportListResult, error) {
+	// Defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.CSPReport{})
+
+	if params.Directive != "" {
+		query = query.Where("effective_directive = ?", params.Directive)
+	}
+
+	// Search filter (escape wildcards to prevent SQL injection)
+	if params.Search != "" {
+		searchPattern := "%" + escapeLikeWildcards(params.Search) + "%"
+		query = query.Where("blocked_uri LIKE ? ESCAPE '\\' OR document_uri LIKE ? ESCAPE '\\' OR source_file LIKE ? ESCAPE '\\'",
+			searchPattern, searchPattern, searchPattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	reports := make([]models.CSPReport, 0)
+	offset := (params.Page - 1) * params.PageSize
+	if err := query.Order("last_seen_at DESC").Offset(offset).Limit(params.PageSize).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+
+	return &CSPReportListResult{
+		Items:      reports,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Clear deletes all reports, e.g. after a policy fix, and returns how many were removed
+func (s *CSPReportsService) Clear(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("1 = 1").Delete(&models.CSPReport{})
+	return result.RowsAffected, result.Error
+}
@@ -1,21 +1,27 @@
 package admin
 
 import (
+	"context"
 	"time"
 
 	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/audit"
 
 	"gorm.io/gorm"
 )
 
 type DashboardService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	audit *AuditService
 }
 
-func NewDashboardService(db *gorm.DB) *DashboardService {
-	return &DashboardService{db: db}
+func NewDashboardService(db *gorm.DB, auditService *AuditService) *DashboardService {
+	return &DashboardService{db: db, audit: auditService}
 }
 
+// recentActivityLimit is the number of audit entries shown on the dashboard
+const recentActivityLimit = 10
+
 // DashboardStats contains dashboard statistics
 type DashboardStats struct {
 	TotalUsers        int64              `json:"totalUsers"`
@@ -38,27 +44,82 @@ type RecentUser struct {
 
 // ActivityLogEntry represents an activity log entry
 type ActivityLogEntry struct {
-	Type      string    `json:"type"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
+	ID         string    `json:"id"`
+	Type       string    `json:"type"` // info or warning
+	Action     string    `json:"action"`
+	Message    string    `json:"message"`
+	ActorEmail string    `json:"actorEmail"`
+	TargetType string    `json:"targetType"`
+	TargetID   string    `json:"targetId"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// activityMessages describe audit actions on the dashboard
+var activityMessages = map[string]string{
+	audit.ActionUserCreate:    "User created",
+	audit.ActionUserUpdate:    "User updated",
+	audit.ActionUserDelete:    "User deleted",
+	audit.ActionUserSuspend:   "User suspended",
+	audit.ActionUserUnsuspend: "User unsuspended",
+
+	audit.ActionSettingUpdate: "Setting changed",
+	audit.ActionFileDelete:    "File deleted",
+
+	audit.ActionLogin:                "Signed in",
+	audit.ActionLoginFailed:          "Failed sign-in",
+	audit.ActionLogout:               "Signed out",
+	audit.ActionRefreshRejected:      "Refresh token rejected",
+	audit.ActionPasswordChange:       "Password changed",
+	audit.ActionPasswordResetRequest: "Password reset requested",
+	audit.ActionPasswordReset:        "Password reset",
+}
+
+// warningActions are shown as warnings on the dashboard
+var warningActions = map[string]bool{
+	audit.ActionLoginFailed:     true,
+	audit.ActionRefreshRejected: true,
+	audit.ActionUserSuspend:     true,
+	audit.ActionUserDelete:      true,
+}
+
+func toActivityLogEntry(entry models.AuditLog) ActivityLogEntry {
+	activity := ActivityLogEntry{
+		ID:         entry.ID,
+		Type:       "info",
+		Action:     entry.Action,
+		Message:    activityMessages[entry.Action],
+		ActorEmail: entry.ActorEmail,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Timestamp:  entry.CreatedAt,
+	}
+	if warningActions[entry.Action] {
+		activity.Type = "warning"
+	}
+	if activity.Message == "" {
+		activity.Message = entry.Action
+	}
+	return activity
 }
 
 // GetStats returns dashboard statistics
-func (s *DashboardService) GetStats() (*DashboardStats, error) {
+func (s *DashboardService) GetStats(ctx context.Context) (*DashboardStats, error) {
+	db := s.db.WithContext(ctx)
+
 	stats := &DashboardStats{}
 
 	// Total users
-	if err := s.db.Model(&models.User{}).Count(&stats.TotalUsers).Error; err != nil {
+	if err := db.Model(&models.User{}).Count(&stats.TotalUsers).Error; err != nil {
 		return nil, err
 	}
 
 	// Active users
-	if err := s.db.Model(&models.User{}).Where("is_active = ?", true).Count(&stats.ActiveUsers).Error; err != nil {
+	if err := db.Model(&models.User{}).Where("is_active = ?", true).Count(&stats.ActiveUsers).Error; err != nil {
 		return nil, err
 	}
 
 	// Admin users
-	if err := s.db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&stats.AdminUsers).Error; err != nil {
+	if err := db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&stats.AdminUsers).Error; err != nil {
 		return nil, err
 	}
 
@@ -68,23 +129,23 @@ func (s *DashboardService) GetStats() (*DashboardStats, error) {
 	monthStart := todayStart.AddDate(0, -1, 0)
 
 	// New users today
-	if err := s.db.Model(&models.User{}).Where("created_at >= ?", todayStart).Count(&stats.NewUsersToday).Error; err != nil {
+	if err := db.Model(&models.User{}).Where("created_at >= ?", todayStart).Count(&stats.NewUsersToday).Error; err != nil {
 		return nil, err
 	}
 
 	// New users this week
-	if err := s.db.Model(&models.User{}).Where("created_at >= ?", weekStart).Count(&stats.NewUsersThisWeek).Error; err != nil {
+	if err := db.Model(&models.User{}).Where("created_at >= ?", weekStart).Count(&stats.NewUsersThisWeek).Error; err != nil {
 		return nil, err
 	}
 
 	// New users this month
-	if err := s.db.Model(&models.User{}).Where("created_at >= ?", monthStart).Count(&stats.NewUsersThisMonth).Error; err != nil {
+	if err := db.Model(&models.User{}).Where("created_at >= ?", monthStart).Count(&stats.NewUsersThisMonth).Error; err != nil {
 		return nil, err
 	}
 
 	// Recent users (last 5)
 	var users []models.User
-	if err := s.db.Model(&models.User{}).Order("created_at DESC").Limit(5).Find(&users).Error; err != nil {
+	if err := db.Model(&models.User{}).Order("created_at DESC").Limit(5).Find(&users).Error; err != nil {
 		return nil, err
 	}
 
@@ -98,9 +159,15 @@ func (s *DashboardService) GetStats() (*DashboardStats, error) {
 		}
 	}
 
-	// Recent activity (mock for now - can be extended with real activity logging)
-	stats.RecentActivity = []ActivityLogEntry{
-		{Type: "info", Message: "Dashboard accessed", Timestamp: now},
+	// Recent activity from the audit log
+	entries, err := s.audit.Recent(ctx, recentActivityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.RecentActivity = make([]ActivityLogEntry, len(entries))
+	for i, entry := range entries {
+		stats.RecentActivity[i] = toActivityLogEntry(entry)
 	}
 
 	return stats, nil
@@ -0,0 +1,329 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrganizationNotFound = apperror.NotFound(apperror.CodeNotFound, "Organization not found")
+	ErrOrgSlugExists        = apperror.Conflict(apperror.CodeConflict, "Slug already exists")
+	ErrMembershipNotFound   = apperror.NotFound(apperror.CodeNotFound, "Membership not found")
+	ErrMemberUserNotFound   = apperror.NotFound(apperror.CodeNotFound, "User not found")
+	ErrMembershipExists     = apperror.Conflict(apperror.CodeConflict, "User is already a member")
+	ErrLastOwner            = apperror.Conflict("LAST_OWNER", "Organization must keep at least one owner")
+)
+
+type OrganizationsService struct {
+	db *gorm.DB
+}
+
+func NewOrganizationsService(db *gorm.DB) *OrganizationsService {
+	return &OrganizationsService{db: db}
+}
+
+// OrganizationListParams contains pagination and filtering parameters
+type OrganizationListParams struct {
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"`
+	Search   string `json:"search"`
+}
+
+// AdminOrganizationResponse is the response format for admin organization management
+type AdminOrganizationResponse struct {
+	models.OrganizationResponse
+	CreatedByID string `json:"createdById"`
+	MemberCount int64  `json:"memberCount"`
+}
+
+// OrganizationListResult contains paginated list result
+type OrganizationListResult struct {
+	Items      []AdminOrganizationResponse `json:"items"`
+	Total      int64                       `json:"total"`
+	Page       int                         `json:"page"`
+	PageSize   int                         `json:"pageSize"`
+	TotalPages int                         `json:"totalPages"`
+}
+
+// UpdateOrganizationInput contains input for updating an organization
+type UpdateOrganizationInput struct {
+	Name *string `json:"name" validate:"omitempty,min=2,max=100"`
+	Slug *string `json:"slug" validate:"omitempty,min=2,max=50"`
+}
+
+// AddMemberInput contains input for adding a user to an organization
+type AddMemberInput struct {
+	UserID string         `json:"userId" validate:"required"`
+	Role   models.OrgRole `json:"role" validate:"omitempty,oneof=owner admin member"`
+}
+
+// SetMemberRoleInput contains input for changing a member's role
+type SetMemberRoleInput struct {
+	Role models.OrgRole `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// List returns paginated list of organizations
+func (s *OrganizationsService) List(ctx context.Context, params OrganizationListParams) (*OrganizationListResult, error) {
+	db := s.db.WithContext(ctx)
+
+	// Defaults
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+
+	query := db.Model(&models.Organization{})
+
+	// Search filter (escape wildcards to prevent SQL injection)
+	if params.Search != "" {
+		searchPattern := "%" + escapeLikeWildcards(params.Search) + "%"
+		query = query.Where("name LIKE ? ESCAPE '\\' OR slug LIKE ? ESCAPE '\\'", searchPattern, searchPattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var orgs []models.Organization
+	offset := (params.Page - 1) * params.PageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(params.PageSize).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]AdminOrganizationResponse, len(orgs))
+	for i, org := range orgs {
+		items[i] = AdminOrganizationResponse{
+			OrganizationResponse: org.ToResponse(),
+			CreatedByID:          org.CreatedByID,
+		}
+		if err := db.Model(&models.Membership{}).Where("organization_id = ?", org.ID).Count(&items[i].MemberCount).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+
+	return &OrganizationListResult{
+		Items:      items,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetByID returns an organization by ID
+func (s *OrganizationsService) GetByID(ctx context.Context, id string) (*AdminOrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	result := &AdminOrganizationResponse{
+		OrganizationResponse: org.ToResponse(),
+		CreatedByID:          org.CreatedByID,
+	}
+	if err := db.Model(&models.Membership{}).Where("organization_id = ?", org.ID).Count(&result.MemberCount).Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Update updates an organization's name or slug
+func (s *OrganizationsService) Update(ctx context.Context, id string, input UpdateOrganizationInput) (*AdminOrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	if input.Slug != nil && *input.Slug != org.Slug {
+		var count int64
+		if err := db.Unscoped().Model(&models.Organization{}).Where("slug = ? AND id != ?", *input.Slug, id).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrOrgSlugExists
+		}
+		org.Slug = *input.Slug
+	}
+	if input.Name != nil {
+		org.Name = *input.Name
+	}
+
+	if err := db.Save(&org).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Delete soft deletes an organization and clears it as the active organization of its members
+func (s *OrganizationsService) Delete(ctx context.Context, id string) error {
+	db := s.db.WithContext(ctx)
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrganizationNotFound
+		}
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("active_organization_id = ?", id).
+			Update("active_organization_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&org).Error
+	})
+}
+
+// ListMembers returns all members of an organization
+func (s *OrganizationsService) ListMembers(ctx context.Context, id string) ([]models.MemberResponse, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var memberships []models.Membership
+	if err := s.db.WithContext(ctx).Joins("User").Where("memberships.organization_id = ?", id).
+		Order("memberships.created_at ASC").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MemberResponse, 0, len(memberships))
+	for _, m := range memberships {
+		if m.User.ID == "" {
+			continue // User was soft deleted
+		}
+		result = append(result, m.ToMemberResponse())
+	}
+
+	return result, nil
+}
+
+// AddMember adds an existing user to an organization
+func (s *OrganizationsService) AddMember(ctx context.Context, id string, input AddMemberInput) (*models.Membership, error) {
+	db := s.db.WithContext(ctx)
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", input.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberUserNotFound
+		}
+		return nil, err
+	}
+
+	var count int64
+	if err := db.Model(&models.Membership{}).Where("organization_id = ? AND user_id = ?", id, input.UserID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrMembershipExists
+	}
+
+	membership := &models.Membership{
+		OrganizationID: id,
+		UserID:         input.UserID,
+		Role:           input.Role,
+	}
+	if err := db.Create(membership).Error; err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+// SetMemberRole changes the role of a member, keeping at least one owner
+func (s *OrganizationsService) SetMemberRole(ctx context.Context, id, userID string, role models.OrgRole) (*models.Membership, error) {
+	membership, err := s.GetMembership(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, id, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	membership.Role = role
+	if err := s.db.WithContext(ctx).Save(membership).Error; err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+// RemoveMember removes a user from an organization, keeping at least one owner
+func (s *OrganizationsService) RemoveMember(ctx context.Context, id, userID string) error {
+	membership, err := s.GetMembership(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if membership.Role == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, id, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(membership).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND active_organization_id = ?", userID, id).
+			Update("active_organization_id", nil).Error
+	})
+}
+
+// GetMembership returns the membership of userID in organization id
+func (s *OrganizationsService) GetMembership(ctx context.Context, id, userID string) (*models.Membership, error) {
+	var membership models.Membership
+	if err := s.db.WithContext(ctx).Where("organization_id = ? AND user_id = ?", id, userID).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMembershipNotFound
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (s *OrganizationsService) ensureAnotherOwner(ctx context.Context, id, userID string) error {
+	var owners int64
+	if err := s.db.WithContext(ctx).Model(&models.Membership{}).
+		Where("organization_id = ? AND role = ? AND user_id != ?", id, models.OrgRoleOwner, userID).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -1,25 +1,81 @@
 package admin
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/utils"
 
 	"gorm.io/gorm"
 )
 
+// InvalidSettingError is returned when a value is rejected by the setting's validator
+type InvalidSettingError struct {
+	Key string
+	Err error
+}
+
+func (e *InvalidSettingError) Error() string {
+	return fmt.Sprintf("invalid value for %s: %v", e.Key, e.Err)
+}
+
+func (e *InvalidSettingError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrSettingNotFound = apperror.NotFound(apperror.CodeNotFound, "Setting not found")
+
+	errSettingChanged  = ErrPreconditionFailed.WithMessage("Setting was changed by someone else, reload and try again")
+	errSettingsChanged = ErrPreconditionFailed.WithMessage("Settings were changed by someone else, reload and try again")
+)
+
 type SettingsService struct {
-	db *gorm.DB
+	db         *gorm.DB
+	validators map[string]func(value string) error
+	onChange   []func()
 }
 
 func NewSettingsService(db *gorm.DB) *SettingsService {
-	return &SettingsService{db: db}
+	return &SettingsService{
+		db:         db,
+		validators: make(map[string]func(string) error),
+	}
+}
+
+// RegisterValidator rejects updates of key whose value fails validate
+func (s *SettingsService) RegisterValidator(key string, validate func(value string) error) {
+	s.validators[key] = validate
+}
+
+// OnChange registers a callback run after settings were updated (e.g. to reload caches)
+func (s *SettingsService) OnChange(fn func()) {
+	s.onChange = append(s.onChange, fn)
+}
+
+func (s *SettingsService) validate(key, value string) error {
+	if validate, ok := s.validators[key]; ok {
+		if err := validate(value); err != nil {
+			return apperror.Validation(utils.FieldError{Field: key, Message: err.Error()}).
+				Wrap(&InvalidSettingError{Key: key, Err: err})
+		}
+	}
+	return nil
+}
+
+func (s *SettingsService) changed() {
+	for _, fn := range s.onChange {
+		fn()
+	}
 }
 
 // GetAll returns all settings
-func (s *SettingsService) GetAll() ([]models.AppSettingsResponse, error) {
+func (s *SettingsService) GetAll(ctx context.Context) ([]models.AppSettingsResponse, error) {
 	var settings []models.AppSettings
-	if err := s.db.Order("setting_group ASC, key ASC").Find(&settings).Error; err != nil {
+	if err := s.db.WithContext(ctx).Order("setting_group ASC, key ASC").Find(&settings).Error; err != nil {
 		return nil, err
 	}
 
@@ -32,11 +88,11 @@ func (s *SettingsService) GetAll() ([]models.AppSettingsResponse, error) {
 }
 
 // GetByKey returns a setting by key
-func (s *SettingsService) GetByKey(key string) (*models.AppSettings, error) {
+func (s *SettingsService) GetByKey(ctx context.Context, key string) (*models.AppSettings, error) {
 	var setting models.AppSettings
-	if err := s.db.First(&setting, "key = ?", key).Error; err != nil {
+	if err := s.db.WithContext(ctx).First(&setting, "key = ?", key).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("setting not found")
+			return nil, ErrSettingNotFound
 		}
 		return nil, err
 	}
@@ -44,9 +100,9 @@ func (s *SettingsService) GetByKey(key string) (*models.AppSettings, error) {
 }
 
 // GetByGroup returns settings by group
-func (s *SettingsService) GetByGroup(group string) ([]models.AppSettingsResponse, error) {
+func (s *SettingsService) GetByGroup(ctx context.Context, group string) ([]models.AppSettingsResponse, error) {
 	var settings []models.AppSettings
-	if err := s.db.Where("setting_group = ?", group).Order("key ASC").Find(&settings).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("setting_group = ?", group).Order("key ASC").Find(&settings).Error; err != nil {
 		return nil, err
 	}
 
@@ -69,28 +125,84 @@ type UpdateBatchInput struct {
 	Settings []UpdateSettingInput `json:"settings" validate:"required,dive"`
 }
 
-// Update updates a setting by key
-func (s *SettingsService) Update(key string, value string) (*models.AppSettings, error) {
-	setting, err := s.GetByKey(key)
+// Update updates a setting by key.
+// A non-empty ifMatch must match the ETag of the setting's current representation.
+func (s *SettingsService) Update(ctx context.Context, key string, value string, ifMatch string) (*models.AppSettings, error) {
+	db := s.db.WithContext(ctx)
+
+	setting, err := s.GetByKey(ctx, key)
 	if err != nil {
 		return nil, err
 	}
 
+	if ifMatch != "" {
+		etag, err := utils.ETag(setting.ToResponse())
+		if err != nil {
+			return nil, err
+		}
+		if !utils.ETagMatches(ifMatch, etag) {
+			return nil, errSettingChanged
+		}
+	}
+
+	if err := s.validate(key, value); err != nil {
+		return nil, err
+	}
+
+	readAt := setting.UpdatedAt
 	setting.Value = value
-	if err := s.db.Save(setting).Error; err != nil {
+	if ifMatch != "" {
+		// Only write if nobody saved the setting since it was read above
+		result := db.Model(setting).Where("updated_at = ?", readAt).Update("value", value)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, errSettingChanged
+		}
+	} else if err := db.Save(setting).Error; err != nil {
 		return nil, err
 	}
+	s.changed()
 
 	return setting, nil
 }
 
-// UpdateBatch updates multiple settings at once
-func (s *SettingsService) UpdateBatch(inputs []UpdateSettingInput) ([]models.AppSettingsResponse, error) {
-	tx := s.db.Begin()
+// UpdateBatch updates multiple settings at once.
+// A non-empty ifMatch must match the ETag of the full settings list (GET /api/admin/settings).
+func (s *SettingsService) UpdateBatch(ctx context.Context, inputs []UpdateSettingInput, ifMatch string) ([]models.AppSettingsResponse, error) {
+	for _, input := range inputs {
+		if err := s.validate(input.Key, input.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	tx := s.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 
+	if ifMatch != "" {
+		var current []models.AppSettings
+		if err := tx.Order("setting_group ASC, key ASC").Find(&current).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		responses := make([]models.AppSettingsResponse, len(current))
+		for i, setting := range current {
+			responses[i] = setting.ToResponse()
+		}
+		etag, err := utils.ETag(responses)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if !utils.ETagMatches(ifMatch, etag) {
+			tx.Rollback()
+			return nil, errSettingsChanged
+		}
+	}
+
 	for _, input := range inputs {
 		if err := tx.Model(&models.AppSettings{}).Where("key = ?", input.Key).Update("value", input.Value).Error; err != nil {
 			tx.Rollback()
@@ -101,16 +213,17 @@ func (s *SettingsService) UpdateBatch(inputs []UpdateSettingInput) ([]models.App
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	s.changed()
 
-	return s.GetAll()
+	return s.GetAll(ctx)
 }
 
 // CreateSetting creates a new setting
-func (s *SettingsService) CreateSetting(setting *models.AppSettings) error {
-	return s.db.Create(setting).Error
+func (s *SettingsService) CreateSetting(ctx context.Context, setting *models.AppSettings) error {
+	return s.db.WithContext(ctx).Create(setting).Error
 }
 
 // DeleteSetting deletes a setting by key
-func (s *SettingsService) DeleteSetting(key string) error {
-	return s.db.Where("key = ?", key).Delete(&models.AppSettings{}).Error
+func (s *SettingsService) DeleteSetting(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&models.AppSettings{}).Error
 }
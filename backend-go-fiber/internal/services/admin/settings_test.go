@@ -0,0 +1,132 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/utils"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Every connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.AppSettings{}, &models.AuditLog{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestSettingsUpdateIfMatch(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewSettingsService(db)
+	db.Create(&models.AppSettings{Key: "site_name", Value: "Old", Type: models.SettingTypeString})
+
+	setting, _ := service.GetByKey(context.Background(), "site_name")
+	etag, _ := utils.ETag(setting.ToResponse())
+
+	updated, err := service.Update(context.Background(), "site_name", "New", etag)
+	if err != nil {
+		t.Fatalf("Update with current ETag failed: %v", err)
+	}
+	if updated.Value != "New" {
+		t.Errorf("Expected value New, got %s", updated.Value)
+	}
+
+	// The editor still holds the old ETag
+	if _, err := service.Update(context.Background(), "site_name", "Newer", etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed for stale ETag, got %v", err)
+	}
+
+	// Without If-Match the update is unconditional
+	if _, err := service.Update(context.Background(), "site_name", "Newest", ""); err != nil {
+		t.Errorf("Unconditional update failed: %v", err)
+	}
+
+	all, _ := service.GetAll(context.Background())
+	listETag, _ := utils.ETag(all)
+	if _, err := service.UpdateBatch(context.Background(), []UpdateSettingInput{{Key: "site_name", Value: "Batch"}}, listETag); err != nil {
+		t.Errorf("Batch update with current ETag failed: %v", err)
+	}
+	if _, err := service.UpdateBatch(context.Background(), []UpdateSettingInput{{Key: "site_name", Value: "Again"}}, listETag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed for stale list ETag, got %v", err)
+	}
+}
+
+func TestUsersUpdateIfMatch(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUsersService(db)
+
+	user, err := service.Create(context.Background(), CreateUserInput{Email: "editor@example.com", Password: "Password123"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	user, _ = service.GetByID(context.Background(), user.ID)
+	etag, _ := utils.ETag(user.ToAdminResponse())
+
+	name := "First"
+	updated, err := service.Update(context.Background(), user.ID, UpdateUserInput{Name: &name}, etag)
+	if err != nil {
+		t.Fatalf("Update with current ETag failed: %v", err)
+	}
+
+	// The returned user carries the new version
+	newETag, _ := utils.ETag(updated.ToAdminResponse())
+	if newETag == etag {
+		t.Error("Expected ETag to change after update")
+	}
+
+	name = "Second"
+	if _, err := service.Update(context.Background(), user.ID, UpdateUserInput{Name: &name}, etag); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Expected ErrPreconditionFailed for stale ETag, got %v", err)
+	}
+
+	// The ETag from the update response is accepted, as a GET would return it
+	stored, _ := service.GetByID(context.Background(), user.ID)
+	if storedETag, _ := utils.ETag(stored.ToAdminResponse()); storedETag != newETag {
+		t.Errorf("Update response ETag %s differs from stored %s", newETag, storedETag)
+	}
+	if _, err := service.Update(context.Background(), user.ID, UpdateUserInput{Name: &name}, newETag); err != nil {
+		t.Errorf("Update with ETag from previous response failed: %v", err)
+	}
+}
+
+func TestUsersDeleteRevokesSessions(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUsersService(db)
+
+	user, err := service.Create(context.Background(), CreateUserInput{Email: "leaving@example.com", Password: "Password123"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	db.Create(&models.RefreshToken{
+		TokenDigest: models.TokenDigest{TokenHash: models.HashToken("session")},
+		UserID:      user.ID,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+
+	if err := service.Delete(context.Background(), user.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected refresh tokens to be revoked, %d left", count)
+	}
+}
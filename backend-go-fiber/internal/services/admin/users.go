@@ -1,10 +1,12 @@
 package admin
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"time"
 
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/utils"
 
@@ -19,6 +21,17 @@ func escapeLikeWildcards(s string) string {
 	return s
 }
 
+// ErrPreconditionFailed is returned when an If-Match precondition doesn't hold:
+// the resource was modified since the client read it
+var ErrPreconditionFailed = apperror.PreconditionFailed("Resource was changed by someone else, reload and try again")
+
+var (
+	ErrUserNotFound = apperror.NotFound(apperror.CodeNotFound, "User not found")
+	ErrEmailExists  = apperror.Conflict(apperror.CodeConflict, "Email already exists")
+
+	errUserChanged = ErrPreconditionFailed.WithMessage("User was changed by someone else, reload and try again")
+)
+
 type UsersService struct {
 	db *gorm.DB
 }
@@ -65,8 +78,19 @@ type UpdateUserInput struct {
 	IsActive *bool        `json:"isActive"`
 }
 
+// SuspendUserInput contains input for suspending a user
+type SuspendUserInput struct {
+	Reason string     `json:"reason" validate:"required,max=500"`
+	Until  *time.Time `json:"until"` // Optional end of the suspension (RFC 3339)
+}
+
+var (
+	ErrCannotSuspendSelf    = apperror.BadRequest(apperror.CodeValidation, "You cannot suspend your own account")
+	ErrInvalidSuspensionEnd = apperror.BadRequest(apperror.CodeValidation, "Suspension end must be in the future")
+)
+
 // List returns paginated list of users
-func (s *UsersService) List(params ListParams) (*ListResult, error) {
+func (s *UsersService) List(ctx context.Context, params ListParams) (*ListResult, error) {
 	// Defaults
 	if params.Page < 1 {
 		params.Page = 1
@@ -82,7 +106,7 @@ func (s *UsersService) List(params ListParams) (*ListResult, error) {
 	}
 
 	// Build query
-	query := s.db.Model(&models.User{})
+	query := s.db.WithContext(ctx).Model(&models.User{})
 
 	// Search filter (escape wildcards to prevent SQL injection)
 	if params.Search != "" {
@@ -144,11 +168,11 @@ func (s *UsersService) List(params ListParams) (*ListResult, error) {
 }
 
 // GetByID returns a user by ID
-func (s *UsersService) GetByID(id string) (*models.User, error) {
+func (s *UsersService) GetByID(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
-	if err := s.db.First(&user, "id = ?", id).Error; err != nil {
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -156,11 +180,13 @@ func (s *UsersService) GetByID(id string) (*models.User, error) {
 }
 
 // Create creates a new user
-func (s *UsersService) Create(input CreateUserInput) (*models.User, error) {
+func (s *UsersService) Create(ctx context.Context, input CreateUserInput) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if email already exists
 	var existingUser models.User
-	if err := s.db.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("email already exists")
+	if err := db.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
+		return nil, ErrEmailExists
 	}
 
 	// Hash password
@@ -187,25 +213,39 @@ func (s *UsersService) Create(input CreateUserInput) (*models.User, error) {
 		IsActive:     isActive,
 	}
 
-	if err := s.db.Create(user).Error; err != nil {
+	if err := db.Create(user).Error; err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-// Update updates an existing user
-func (s *UsersService) Update(id string, input UpdateUserInput) (*models.User, error) {
-	user, err := s.GetByID(id)
+// Update updates an existing user.
+// A non-empty ifMatch must match the ETag of the user's current representation.
+func (s *UsersService) Update(ctx context.Context, id string, input UpdateUserInput, ifMatch string) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
+	user, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if ifMatch != "" {
+		etag, err := utils.ETag(user.ToAdminResponse())
+		if err != nil {
+			return nil, err
+		}
+		if !utils.ETagMatches(ifMatch, etag) {
+			return nil, errUserChanged
+		}
+	}
+	readAt := user.UpdatedAt
+
 	// Check if new email already exists
 	if input.Email != nil && *input.Email != user.Email {
 		var existingUser models.User
-		if err := s.db.Where("email = ? AND id != ?", *input.Email, id).First(&existingUser).Error; err == nil {
-			return nil, errors.New("email already exists")
+		if err := db.Where("email = ? AND id != ?", *input.Email, id).First(&existingUser).Error; err == nil {
+			return nil, ErrEmailExists
 		}
 		user.Email = *input.Email
 	}
@@ -226,34 +266,117 @@ func (s *UsersService) Update(id string, input UpdateUserInput) (*models.User, e
 	if input.Role != nil {
 		user.Role = *input.Role
 	}
+	deactivated := false
 	if input.IsActive != nil {
+		if *input.IsActive {
+			clearSuspension(user)
+		} else {
+			deactivated = user.IsActive
+		}
 		user.IsActive = *input.IsActive
 	}
 
-	if err := s.db.Save(user).Error; err != nil {
+	if ifMatch != "" {
+		// Only write if nobody saved the user since it was read above
+		result := db.Model(user).Where("updated_at = ?", readAt).Select("*").Updates(user)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, errUserChanged
+		}
+	} else if err := db.Save(user).Error; err != nil {
+		return nil, err
+	}
+
+	// Deactivation ends all sessions, like a suspension
+	if deactivated {
+		if err := db.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// Suspend deactivates a user, records who did it and why, and revokes all refresh tokens
+func (s *UsersService) Suspend(ctx context.Context, id string, actorID string, input SuspendUserInput) (*models.User, error) {
+	if id == actorID {
+		return nil, ErrCannotSuspendSelf
+	}
+	if input.Until != nil && !input.Until.After(time.Now()) {
+		return nil, ErrInvalidSuspensionEnd
+	}
+
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reason := input.Reason
+	user.IsActive = false
+	user.SuspendedAt = &now
+	user.SuspendedUntil = input.Until
+	user.SuspensionReason = &reason
+	user.SuspendedByID = &actorID
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-// Delete soft deletes a user
-func (s *UsersService) Delete(id string) error {
-	user, err := s.GetByID(id)
+// Unsuspend reactivates a user and clears the suspension details
+func (s *UsersService) Unsuspend(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Soft delete
-	if err := s.db.Delete(user).Error; err != nil {
+	user.IsActive = true
+	clearSuspension(user)
+
+	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// clearSuspension resets the suspension details of a user
+func clearSuspension(user *models.User) {
+	user.SuspendedAt = nil
+	user.SuspendedUntil = nil
+	user.SuspensionReason = nil
+	user.SuspendedByID = nil
+}
+
+// Delete soft deletes a user and revokes all refresh tokens
+func (s *UsersService) Delete(ctx context.Context, id string) error {
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Soft delete
+		if err := tx.Delete(user).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error
+	})
 }
 
 // UpdateLastLogin updates the last login timestamp
-func (s *UsersService) UpdateLastLogin(id string) error {
+func (s *UsersService) UpdateLastLogin(ctx context.Context, id string) error {
 	now := time.Now()
-	return s.db.Model(&models.User{}).Where("id = ?", id).Update("last_login_at", now).Error
+	return s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("last_login_at", now).Error
 }
@@ -0,0 +1,189 @@
+// Package audit records admin mutations and security events in the audit log
+// (models.AuditLog). Handlers call Log.Record with the actor and target; the
+// client IP, request ID and user agent come from the request context, where
+// middleware.AuditMiddleware puts them.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"backend-go-fiber/internal/models"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Actions
+const (
+	ActionUserCreate    = "user.create"
+	ActionUserUpdate    = "user.update"
+	ActionUserDelete    = "user.delete"
+	ActionUserSuspend   = "user.suspend"
+	ActionUserUnsuspend = "user.unsuspend"
+
+	ActionOrganizationUpdate       = "organization.update"
+	ActionOrganizationDelete       = "organization.delete"
+	ActionOrganizationMemberAdd    = "organization.member_add"
+	ActionOrganizationMemberUpdate = "organization.member_update"
+	ActionOrganizationMemberRemove = "organization.member_remove"
+
+	ActionSettingUpdate   = "setting.update"
+	ActionFileDelete      = "file.delete"
+	ActionCSPReportsClear = "csp_reports.clear"
+	ActionConfigReload    = "config.reload"
+
+	ActionLogin                = "auth.login"
+	ActionLoginFailed          = "auth.login_failed"
+	ActionLogout               = "auth.logout"
+	ActionRefreshRejected      = "auth.refresh_rejected"
+	ActionPasswordChange       = "auth.password_change"
+	ActionPasswordResetRequest = "auth.password_reset_request"
+	ActionPasswordReset        = "auth.password_reset"
+)
+
+// Target types
+const (
+	TargetUser         = "user"
+	TargetOrganization = "organization"
+	TargetSetting      = "setting"
+	TargetFile         = "file"
+	TargetCSPReports   = "csp_reports"
+	TargetConfig       = "config"
+)
+
+// ignoredFields change on every update and would only add noise to diffs
+var ignoredFields = map[string]bool{"updatedAt": true}
+
+// Entry is an event to record. Before and After are the target as returned by
+// the API (e.g. models.AdminUserResponse); only the fields that differ are
+// stored. Leave Before nil for creations and After nil for deletions.
+type Entry struct {
+	Action string
+
+	ActorID    string
+	ActorEmail string
+
+	TargetType string
+	TargetID   string
+
+	Before any
+	After  any
+
+	Metadata map[string]string
+}
+
+// Request describes the HTTP request an event happened in
+type Request struct {
+	IP        string
+	RequestID string
+	UserAgent string
+
+	// ClientIdentity is mapped from a verified client certificate (mTLS)
+	ClientIdentity string
+}
+
+type requestKey struct{}
+
+// WithRequest returns a copy of ctx carrying req
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFrom returns the request stored by WithRequest
+func RequestFrom(ctx context.Context) Request {
+	req, _ := ctx.Value(requestKey{}).(Request)
+	return req
+}
+
+// Log writes audit entries
+type Log struct {
+	db *gorm.DB
+}
+
+// New creates a log stored in db
+func New(db *gorm.DB) *Log {
+	return &Log{db: db}
+}
+
+// Record stores e. The audited action already happened, so failures are
+// logged rather than returned.
+func (l *Log) Record(ctx context.Context, e Entry) {
+	changes, err := Diff(e.Before, e.After)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", e.Action).Msg("Failed to diff audit entry")
+	}
+
+	req := RequestFrom(ctx)
+	entry := models.AuditLog{
+		Action:         e.Action,
+		ActorEmail:     e.ActorEmail,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		Changes:        changes,
+		Metadata:       e.Metadata,
+		IP:             req.IP,
+		RequestID:      req.RequestID,
+		UserAgent:      req.UserAgent,
+		ClientIdentity: req.ClientIdentity,
+	}
+	if e.ActorID != "" {
+		entry.ActorID = &e.ActorID
+	}
+
+	// Not canceled with the request: a client hanging up must not drop the entry
+	if err := l.db.WithContext(context.WithoutCancel(ctx)).Create(&entry).Error; err != nil {
+		log.Ctx(ctx).Error().Err(err).
+			Str("action", e.Action).
+			Str("target_id", e.TargetID).
+			Msg("Failed to record audit entry")
+	}
+}
+
+// Diff compares the JSON fields of before and after. Either may be nil.
+func Diff(before, after any) (models.AuditChanges, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.AuditChanges{}
+	for name, value := range from {
+		if ignoredFields[name] {
+			continue
+		}
+		if next, ok := to[name]; !ok || !reflect.DeepEqual(value, next) {
+			changes[name] = models.AuditChange{From: value, To: to[name]}
+		}
+	}
+	for name, value := range to {
+		if _, ok := from[name]; !ok && !ignoredFields[name] {
+			changes[name] = models.AuditChange{To: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+// fields decodes the JSON form of v into a map
+func fields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
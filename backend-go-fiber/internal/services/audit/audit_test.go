@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type account struct {
+	Email     string  `json:"email"`
+	Name      *string `json:"name"`
+	IsActive  bool    `json:"isActive"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+func TestDiff(t *testing.T) {
+	name := "Ann"
+	before := account{Email: "ann@example.com", IsActive: true, UpdatedAt: "monday"}
+	after := account{Email: "ann@example.com", Name: &name, IsActive: false, UpdatedAt: "tuesday"}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %v, expected name and isActive", changes)
+	}
+	if c := changes["name"]; c.From != nil || c.To != "Ann" {
+		t.Errorf("name = %+v", c)
+	}
+	if c := changes["isActive"]; c.From != true || c.To != false {
+		t.Errorf("isActive = %+v", c)
+	}
+
+	// Creations and deletions list every field
+	created, _ := Diff(nil, before)
+	if c, ok := created["email"]; !ok || c.From != nil || c.To != "ann@example.com" {
+		t.Errorf("created = %v", created)
+	}
+	deleted, _ := Diff(before, nil)
+	if c, ok := deleted["email"]; !ok || c.From != "ann@example.com" || c.To != nil {
+		t.Errorf("deleted = %v", deleted)
+	}
+
+	if unchanged, _ := Diff(before, before); unchanged != nil {
+		t.Errorf("unchanged = %v", unchanged)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithRequest(context.Background(), Request{IP: "203.0.113.7", RequestID: "req-1", UserAgent: "curl", ClientIdentity: "service:billing"})
+	New(db).Record(ctx, Entry{
+		Action:     ActionUserUpdate,
+		ActorID:    "admin-1",
+		ActorEmail: "admin@example.com",
+		TargetType: TargetUser,
+		TargetID:   "user-1",
+		Before:     account{Email: "old@example.com"},
+		After:      account{Email: "new@example.com"},
+		Metadata:   map[string]string{"source": "test"},
+	})
+	New(db).Record(context.Background(), Entry{Action: ActionLoginFailed, ActorEmail: "who@example.com"})
+
+	var entries []models.AuditLog
+	if err := db.Order("action DESC").Find(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	update := entries[0]
+	if update.ActorID == nil || *update.ActorID != "admin-1" || update.TargetID != "user-1" {
+		t.Errorf("actor and target not recorded: %+v", update)
+	}
+	if update.IP != "203.0.113.7" || update.RequestID != "req-1" || update.UserAgent != "curl" || update.ClientIdentity != "service:billing" {
+		t.Errorf("request not recorded: %+v", update)
+	}
+	if c := update.Changes["email"]; c.From != "old@example.com" || c.To != "new@example.com" || len(update.Changes) != 1 {
+		t.Errorf("changes = %v", update.Changes)
+	}
+	if update.Metadata["source"] != "test" {
+		t.Errorf("metadata = %v", update.Metadata)
+	}
+
+	// Anonymous events have no actor ID
+	if failed := entries[1]; failed.ActorID != nil || failed.Changes != nil || failed.ActorEmail != "who@example.com" {
+		t.Errorf("anonymous entry = %+v", failed)
+	}
+}
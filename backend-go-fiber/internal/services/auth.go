@@ -1,16 +1,52 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/i18n"
 	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/utils"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrUserExists          = apperror.Conflict("USER_EXISTS", "user already exists")
+	ErrInvalidCredentials  = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid credentials")
+	ErrInvalidRefreshToken = apperror.Unauthorized("INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
+	ErrIncorrectPassword   = apperror.BadRequest("INVALID_PASSWORD", "current password is incorrect")
+
+	// ErrAccountSuspended is returned when a suspended user tries to log in or refresh a session
+	ErrAccountSuspended = apperror.Forbidden("ACCOUNT_SUSPENDED", "Your account has been suspended")
+)
+
+// Causes of ErrInvalidRefreshToken; clients can't tell them apart
+var (
+	errUnknownRefreshToken = errors.New("invalid refresh token")
+	errExpiredRefreshToken = errors.New("refresh token expired")
+)
+
+// AccountSuspendedError carries the suspension details that may be shown to the user
+type AccountSuspendedError struct {
+	Until  *time.Time
+	Reason *string
+}
+
+func (e *AccountSuspendedError) Error() string {
+	return "account suspended"
+}
+
+// Unwrap returns ErrAccountSuspended, with the end date in the message if there is one
+func (e *AccountSuspendedError) Unwrap() error {
+	if e.Until == nil {
+		return ErrAccountSuspended
+	}
+	return ErrAccountSuspended.WithMessage(ErrAccountSuspended.Message + " until " + e.Until.UTC().Format(time.RFC3339))
+}
+
 type AuthService struct {
 	db *gorm.DB
 }
@@ -36,11 +72,13 @@ type AuthResult struct {
 	ExpiresIn   int                 `json:"expiresIn"`
 }
 
-func (s *AuthService) Register(input RegisterInput) (*AuthResult, error) {
+func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*AuthResult, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if user exists
 	var existing models.User
-	if err := s.db.Where("email = ?", input.Email).First(&existing).Error; err == nil {
-		return nil, errors.New("user already exists")
+	if err := db.Where("email = ?", input.Email).First(&existing).Error; err == nil {
+		return nil, ErrUserExists
 	}
 
 	// Hash password
@@ -56,15 +94,12 @@ func (s *AuthService) Register(input RegisterInput) (*AuthResult, error) {
 		Name:         input.Name,
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
+	if err := db.Create(&user).Error; err != nil {
 		return nil, err
 	}
 
 	// Generate access token
-	accessToken, err := utils.GenerateAccessToken(utils.JWTPayload{
-		UserID: user.ID,
-		Email:  user.Email,
-	})
+	accessToken, err := s.issueAccessToken(ctx, &user)
 	if err != nil {
 		return nil, err
 	}
@@ -76,25 +111,29 @@ func (s *AuthService) Register(input RegisterInput) (*AuthResult, error) {
 	}, nil
 }
 
-func (s *AuthService) Login(input LoginInput) (*AuthResult, error) {
+func (s *AuthService) Login(ctx context.Context, input LoginInput) (*AuthResult, error) {
+	db := s.db.WithContext(ctx)
+
 	var user models.User
-	if err := s.db.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		return nil, errors.New("invalid credentials")
+	if err := db.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		return nil, ErrInvalidCredentials
 	}
 
 	if !utils.VerifyPassword(input.Password, user.PasswordHash) {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+
+	// Checked after the password so that account status isn't revealed to strangers
+	if err := s.checkSuspension(ctx, &user); err != nil {
+		return nil, err
 	}
 
 	// Update last login timestamp
 	now := time.Now()
 	user.LastLoginAt = &now
-	s.db.Model(&user).Update("last_login_at", now)
+	db.Model(&user).Update("last_login_at", now)
 
-	accessToken, err := utils.GenerateAccessToken(utils.JWTPayload{
-		UserID: user.ID,
-		Email:  user.Email,
-	})
+	accessToken, err := s.issueAccessToken(ctx, &user)
 	if err != nil {
 		return nil, err
 	}
@@ -106,41 +145,57 @@ func (s *AuthService) Login(input LoginInput) (*AuthResult, error) {
 	}, nil
 }
 
-func (s *AuthService) CreateRefreshToken(userID string) (string, error) {
-	token := uuid.New().String()
+// CreateRefreshToken issues a new refresh token.
+// Only its digest is stored; the returned plaintext goes to the client cookie.
+func (s *AuthService) CreateRefreshToken(ctx context.Context, userID string) (string, error) {
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
 	expiresAt := time.Now().AddDate(0, 0, utils.GetRefreshTokenExpiresDays())
 
 	refreshToken := models.RefreshToken{
-		Token:     token,
-		UserID:    userID,
-		ExpiresAt: expiresAt,
+		TokenDigest: models.TokenDigest{TokenHash: models.HashToken(token)},
+		UserID:      userID,
+		ExpiresAt:   expiresAt,
 	}
 
-	if err := s.db.Create(&refreshToken).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(&refreshToken).Error; err != nil {
 		return "", err
 	}
 
 	return token, nil
 }
 
-func (s *AuthService) RefreshAccessToken(refreshToken string) (*struct {
+func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*struct {
 	AccessToken string `json:"accessToken"`
 	ExpiresIn   int    `json:"expiresIn"`
 }, error) {
+	db := s.db.WithContext(ctx)
+
 	var storedToken models.RefreshToken
-	if err := s.db.Preload("User").Where("token = ?", refreshToken).First(&storedToken).Error; err != nil {
-		return nil, errors.New("invalid refresh token")
+	if err := db.Preload("User").Where("token_hash = ?", models.HashToken(refreshToken)).First(&storedToken).Error; err != nil {
+		return nil, ErrInvalidRefreshToken.Wrap(errUnknownRefreshToken)
+	}
+
+	// Preload leaves User empty when the user was (soft) deleted
+	if storedToken.User.ID == "" {
+		db.Delete(&storedToken)
+		return nil, ErrInvalidRefreshToken.Wrap(errUnknownRefreshToken)
 	}
 
 	if storedToken.ExpiresAt.Before(time.Now()) {
-		s.db.Delete(&storedToken)
-		return nil, errors.New("refresh token expired")
+		db.Delete(&storedToken)
+		return nil, ErrInvalidRefreshToken.Wrap(errExpiredRefreshToken)
 	}
 
-	accessToken, err := utils.GenerateAccessToken(utils.JWTPayload{
-		UserID: storedToken.User.ID,
-		Email:  storedToken.User.Email,
-	})
+	if err := s.checkSuspension(ctx, &storedToken.User); err != nil {
+		// Kill every session of the suspended user, not only this one
+		s.RevokeAllRefreshTokens(ctx, storedToken.UserID)
+		return nil, err
+	}
+
+	accessToken, err := s.issueAccessToken(ctx, &storedToken.User)
 	if err != nil {
 		return nil, err
 	}
@@ -154,13 +209,141 @@ func (s *AuthService) RefreshAccessToken(refreshToken string) (*struct {
 	}, nil
 }
 
-func (s *AuthService) RevokeRefreshToken(token string) error {
-	return s.db.Where("token = ?", token).Delete(&models.RefreshToken{}).Error
+// issueAccessToken signs an access token carrying the user's active organization claim
+func (s *AuthService) issueAccessToken(ctx context.Context, user *models.User) (string, error) {
+	payload := utils.JWTPayload{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   string(user.Role),
+	}
+	if user.Locale != nil {
+		payload.Locale = *user.Locale
+	}
+
+	if user.ActiveOrganizationID != nil {
+		var membership models.Membership
+		err := s.db.WithContext(ctx).Where("organization_id = ? AND user_id = ?", *user.ActiveOrganizationID, user.ID).First(&membership).Error
+		if err == nil {
+			payload.OrgID = membership.OrganizationID
+			payload.OrgRole = string(membership.Role)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+	}
+
+	return utils.GenerateAccessToken(payload)
 }
 
-func (s *AuthService) GetUserByID(userID string) (*models.User, error) {
+// SwitchOrganization makes orgID the user's active organization and issues a new access token
+func (s *AuthService) SwitchOrganization(ctx context.Context, userID, orgID string) (*AuthResult, error) {
+	db := s.db.WithContext(ctx)
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var membership models.Membership
+	if err := db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotOrgMember
+		}
+		return nil, err
+	}
+
+	if err := db.Model(user).Update("active_organization_id", orgID).Error; err != nil {
+		return nil, err
+	}
+	user.ActiveOrganizationID = &orgID
+
+	accessToken, err := s.issueAccessToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthResult{
+		User:        user.ToResponse(),
+		AccessToken: accessToken,
+		ExpiresIn:   utils.GetExpiresInSeconds(),
+	}, nil
+}
+
+// RefreshRejection names why RefreshAccessToken refused a token, for the
+// audit log: "unknown" (never issued, or revoked by logout; a client
+// presenting it again may be replaying a stolen token), "expired" or
+// "suspended". It returns "" for other errors.
+func RefreshRejection(err error) string {
+	switch {
+	case errors.Is(err, errUnknownRefreshToken):
+		return "unknown"
+	case errors.Is(err, errExpiredRefreshToken):
+		return "expired"
+	case errors.Is(err, ErrAccountSuspended):
+		return "suspended"
+	}
+	return ""
+}
+
+// RefreshTokenUser returns the owner of a refresh token
+func (s *AuthService) RefreshTokenUser(ctx context.Context, token string) (*models.User, error) {
+	var storedToken models.RefreshToken
+	if err := s.db.WithContext(ctx).Preload("User").Where("token_hash = ?", models.HashToken(token)).First(&storedToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken.Wrap(errUnknownRefreshToken)
+		}
+		return nil, err
+	}
+	if storedToken.User.ID == "" {
+		return nil, ErrInvalidRefreshToken.Wrap(errUnknownRefreshToken)
+	}
+	return &storedToken.User, nil
+}
+
+func (s *AuthService) RevokeRefreshToken(ctx context.Context, token string) error {
+	return s.db.WithContext(ctx).Where("token_hash = ?", models.HashToken(token)).Delete(&models.RefreshToken{}).Error
+}
+
+// RevokeAllRefreshTokens deletes all refresh tokens of a user (logs out every session)
+func (s *AuthService) RevokeAllRefreshTokens(ctx context.Context, userID string) error {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
+}
+
+// checkSuspension rejects suspended users and lifts suspensions that have run out
+func (s *AuthService) checkSuspension(ctx context.Context, user *models.User) error {
+	if user.IsSuspended() {
+		return &AccountSuspendedError{
+			Until:  user.SuspendedUntil,
+			Reason: user.SuspensionReason,
+		}
+	}
+
+	if !user.IsActive {
+		// Temporary suspension has expired - reactivate the account
+		if err := s.db.WithContext(ctx).Model(user).Updates(map[string]interface{}{
+			"is_active":         true,
+			"suspended_at":      nil,
+			"suspended_until":   nil,
+			"suspension_reason": nil,
+			"suspended_by_id":   nil,
+		}).Error; err != nil {
+			return err
+		}
+		user.IsActive = true
+		user.SuspendedAt = nil
+		user.SuspendedUntil = nil
+		user.SuspensionReason = nil
+		user.SuspendedByID = nil
+	}
+
+	return nil
+}
+
+func (s *AuthService) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -168,18 +351,33 @@ func (s *AuthService) GetUserByID(userID string) (*models.User, error) {
 
 // UpdateProfileInput represents the profile update request
 type UpdateProfileInput struct {
+	// Display name; omitted keeps the current one
 	Name *string `json:"name" validate:"omitempty,max=100"`
+
+	// Preferred locale; omitted keeps the current one, "" clears it
+	Locale *string `json:"locale" validate:"omitempty,locale"`
 }
 
-// UpdateProfile updates the user's name
-func (s *AuthService) UpdateProfile(userID string, input UpdateProfileInput) (*models.User, error) {
+// UpdateProfile updates the user's name and preferred locale
+func (s *AuthService) UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
 	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return nil, errors.New("user not found")
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound.Wrap(err)
 	}
 
-	user.Name = input.Name
-	if err := s.db.Save(&user).Error; err != nil {
+	if input.Name != nil {
+		user.Name = input.Name
+	}
+	if input.Locale != nil {
+		user.Locale = nil
+		if *input.Locale != "" {
+			locale, _ := i18n.Default().Match(*input.Locale)
+			user.Locale = &locale
+		}
+	}
+	if err := db.Save(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -193,14 +391,16 @@ type ChangePasswordInput struct {
 }
 
 // ChangePassword changes the user's password after verifying the current one
-func (s *AuthService) ChangePassword(userID string, input ChangePasswordInput) error {
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, input ChangePasswordInput) error {
+	db := s.db.WithContext(ctx)
+
 	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
-		return errors.New("user not found")
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return ErrUserNotFound.Wrap(err)
 	}
 
 	if !utils.VerifyPassword(input.CurrentPassword, user.PasswordHash) {
-		return errors.New("current password is incorrect")
+		return ErrIncorrectPassword
 	}
 
 	newHash, err := utils.HashPassword(input.NewPassword)
@@ -209,7 +409,7 @@ func (s *AuthService) ChangePassword(userID string, input ChangePasswordInput) e
 	}
 
 	user.PasswordHash = newHash
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := db.Save(&user).Error; err != nil {
 		return err
 	}
 
@@ -1,11 +1,14 @@
 package services
 
 import (
-	"os"
+	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"backend-go-fiber/internal/apperror"
 	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/utils"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,7 +25,13 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate the schema
-	err = db.AutoMigrate(&models.User{}, &models.RefreshToken{})
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.RefreshToken{},
+		&models.Organization{},
+		&models.Membership{},
+		&models.OrganizationInvitation{},
+	)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -30,16 +39,16 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
-// setupTestEnv sets up environment variables for testing
+// setupTestEnv configures JWTs for testing
 func setupTestEnv() func() {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	os.Setenv("REFRESH_TOKEN_EXPIRES_DAYS", "7")
+	utils.ConfigureJWT(utils.JWTConfig{
+		Secret:           "test-secret-that-is-at-least-32-characters-long",
+		ExpiresIn:        15 * time.Minute,
+		RefreshTokenDays: 7,
+	})
 
 	return func() {
-		os.Unsetenv("JWT_SECRET")
-		os.Unsetenv("JWT_EXPIRES_IN")
-		os.Unsetenv("REFRESH_TOKEN_EXPIRES_DAYS")
+		utils.ConfigureJWT(utils.JWTConfig{})
 	}
 }
 
@@ -68,7 +77,7 @@ func TestRegister(t *testing.T) {
 		Password: "password123",
 	}
 
-	result, err := service.Register(input)
+	result, err := service.Register(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
@@ -111,7 +120,7 @@ func TestRegisterWithName(t *testing.T) {
 		Name:     &name,
 	}
 
-	result, err := service.Register(input)
+	result, err := service.Register(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Register with name failed: %v", err)
 	}
@@ -134,13 +143,13 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 	}
 
 	// First registration should succeed
-	_, err := service.Register(input)
+	_, err := service.Register(context.Background(), input)
 	if err != nil {
 		t.Fatalf("First registration failed: %v", err)
 	}
 
 	// Second registration should fail
-	_, err = service.Register(input)
+	_, err = service.Register(context.Background(), input)
 	if err == nil {
 		t.Error("Expected error for duplicate email")
 	}
@@ -162,7 +171,7 @@ func TestLogin(t *testing.T) {
 		Email:    "login@example.com",
 		Password: "password123",
 	}
-	_, err := service.Register(registerInput)
+	_, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
@@ -173,7 +182,7 @@ func TestLogin(t *testing.T) {
 		Password: "password123",
 	}
 
-	result, err := service.Login(loginInput)
+	result, err := service.Login(context.Background(), loginInput)
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
@@ -203,7 +212,7 @@ func TestLoginWrongPassword(t *testing.T) {
 		Email:    "wrongpass@example.com",
 		Password: "correctpassword",
 	}
-	_, err := service.Register(registerInput)
+	_, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
@@ -214,7 +223,7 @@ func TestLoginWrongPassword(t *testing.T) {
 		Password: "wrongpassword",
 	}
 
-	_, err = service.Login(loginInput)
+	_, err = service.Login(context.Background(), loginInput)
 	if err == nil {
 		t.Error("Expected error for wrong password")
 	}
@@ -236,7 +245,7 @@ func TestLoginNonExistentUser(t *testing.T) {
 		Password: "password123",
 	}
 
-	_, err := service.Login(loginInput)
+	_, err := service.Login(context.Background(), loginInput)
 	if err == nil {
 		t.Error("Expected error for non-existent user")
 	}
@@ -258,13 +267,13 @@ func TestCreateRefreshToken(t *testing.T) {
 		Email:    "refresh@example.com",
 		Password: "password123",
 	}
-	result, err := service.Register(registerInput)
+	result, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
 
 	// Create refresh token
-	token, err := service.CreateRefreshToken(result.User.ID)
+	token, err := service.CreateRefreshToken(context.Background(), result.User.ID)
 	if err != nil {
 		t.Fatalf("CreateRefreshToken failed: %v", err)
 	}
@@ -275,7 +284,7 @@ func TestCreateRefreshToken(t *testing.T) {
 
 	// Verify token was created in database
 	var refreshToken models.RefreshToken
-	err = db.Where("token = ?", token).First(&refreshToken).Error
+	err = db.Where("token_hash = ?", models.HashToken(token)).First(&refreshToken).Error
 	if err != nil {
 		t.Errorf("Refresh token not found in database: %v", err)
 	}
@@ -283,6 +292,11 @@ func TestCreateRefreshToken(t *testing.T) {
 	if refreshToken.UserID != result.User.ID {
 		t.Errorf("UserID mismatch: got %s, want %s", refreshToken.UserID, result.User.ID)
 	}
+
+	// Only the digest may be stored
+	if refreshToken.TokenHash == token {
+		t.Error("Refresh token must not be stored in plaintext")
+	}
 }
 
 func TestRefreshAccessToken(t *testing.T) {
@@ -297,18 +311,18 @@ func TestRefreshAccessToken(t *testing.T) {
 		Email:    "refreshaccess@example.com",
 		Password: "password123",
 	}
-	result, err := service.Register(registerInput)
+	result, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
 
-	refreshToken, err := service.CreateRefreshToken(result.User.ID)
+	refreshToken, err := service.CreateRefreshToken(context.Background(), result.User.ID)
 	if err != nil {
 		t.Fatalf("CreateRefreshToken failed: %v", err)
 	}
 
 	// Refresh the access token
-	newTokens, err := service.RefreshAccessToken(refreshToken)
+	newTokens, err := service.RefreshAccessToken(context.Background(), refreshToken)
 	if err != nil {
 		t.Fatalf("RefreshAccessToken failed: %v", err)
 	}
@@ -329,7 +343,7 @@ func TestRefreshAccessTokenInvalid(t *testing.T) {
 
 	service := NewAuthService(db)
 
-	_, err := service.RefreshAccessToken("invalid-token")
+	_, err := service.RefreshAccessToken(context.Background(), "invalid-token")
 	if err == nil {
 		t.Error("Expected error for invalid refresh token")
 	}
@@ -337,6 +351,9 @@ func TestRefreshAccessTokenInvalid(t *testing.T) {
 	if err.Error() != "invalid refresh token" {
 		t.Errorf("Expected 'invalid refresh token' error, got: %v", err)
 	}
+	if reason := RefreshRejection(err); reason != "unknown" {
+		t.Errorf("Expected rejection reason unknown, got %q", reason)
+	}
 }
 
 func TestRefreshAccessTokenExpired(t *testing.T) {
@@ -351,21 +368,21 @@ func TestRefreshAccessTokenExpired(t *testing.T) {
 		Email:    "expired@example.com",
 		Password: "password123",
 	}
-	result, err := service.Register(registerInput)
+	result, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
 
 	// Create an expired refresh token directly in DB
 	expiredToken := models.RefreshToken{
-		Token:     "expired-token-123",
-		UserID:    result.User.ID,
-		ExpiresAt: time.Now().Add(-24 * time.Hour), // Expired yesterday
+		TokenDigest: models.TokenDigest{TokenHash: models.HashToken("expired-token-123")},
+		UserID:      result.User.ID,
+		ExpiresAt:   time.Now().Add(-24 * time.Hour), // Expired yesterday
 	}
 	db.Create(&expiredToken)
 
 	// Try to use the expired token
-	_, err = service.RefreshAccessToken("expired-token-123")
+	_, err = service.RefreshAccessToken(context.Background(), "expired-token-123")
 	if err == nil {
 		t.Error("Expected error for expired refresh token")
 	}
@@ -373,15 +390,52 @@ func TestRefreshAccessTokenExpired(t *testing.T) {
 	if err.Error() != "refresh token expired" {
 		t.Errorf("Expected 'refresh token expired' error, got: %v", err)
 	}
+	if reason := RefreshRejection(err); reason != "expired" {
+		t.Errorf("Expected rejection reason expired, got %q", reason)
+	}
 
 	// Verify the expired token was deleted
 	var count int64
-	db.Model(&models.RefreshToken{}).Where("token = ?", "expired-token-123").Count(&count)
+	db.Model(&models.RefreshToken{}).Where("token_hash = ?", models.HashToken("expired-token-123")).Count(&count)
 	if count != 0 {
 		t.Error("Expired token should have been deleted")
 	}
 }
 
+func TestRefreshAccessTokenDeletedUser(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+
+	result, err := service.Register(context.Background(), RegisterInput{
+		Email:    "deleted@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	refreshToken, err := service.CreateRefreshToken(context.Background(), result.User.ID)
+	if err != nil {
+		t.Fatalf("CreateRefreshToken failed: %v", err)
+	}
+
+	// Soft delete the user, leaving the token behind
+	db.Delete(&models.User{}, "id = ?", result.User.ID)
+
+	_, err = service.RefreshAccessToken(context.Background(), refreshToken)
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken, got: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.RefreshToken{}).Where("token_hash = ?", models.HashToken(refreshToken)).Count(&count)
+	if count != 0 {
+		t.Error("Token of the deleted user should have been revoked")
+	}
+}
+
 func TestRevokeRefreshToken(t *testing.T) {
 	db := setupTestDB(t)
 	cleanup := setupTestEnv()
@@ -394,25 +448,30 @@ func TestRevokeRefreshToken(t *testing.T) {
 		Email:    "revoke@example.com",
 		Password: "password123",
 	}
-	result, err := service.Register(registerInput)
+	result, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
 
-	refreshToken, err := service.CreateRefreshToken(result.User.ID)
+	refreshToken, err := service.CreateRefreshToken(context.Background(), result.User.ID)
 	if err != nil {
 		t.Fatalf("CreateRefreshToken failed: %v", err)
 	}
 
+	owner, err := service.RefreshTokenUser(context.Background(), refreshToken)
+	if err != nil || owner.ID != result.User.ID {
+		t.Fatalf("RefreshTokenUser = %v, %v", owner, err)
+	}
+
 	// Revoke the token
-	err = service.RevokeRefreshToken(refreshToken)
+	err = service.RevokeRefreshToken(context.Background(), refreshToken)
 	if err != nil {
 		t.Fatalf("RevokeRefreshToken failed: %v", err)
 	}
 
 	// Verify token was deleted
 	var count int64
-	db.Model(&models.RefreshToken{}).Where("token = ?", refreshToken).Count(&count)
+	db.Model(&models.RefreshToken{}).Where("token_hash = ?", models.HashToken(refreshToken)).Count(&count)
 	if count != 0 {
 		t.Error("Refresh token should have been deleted")
 	}
@@ -430,13 +489,13 @@ func TestGetUserByID(t *testing.T) {
 		Email:    "getuser@example.com",
 		Password: "password123",
 	}
-	result, err := service.Register(registerInput)
+	result, err := service.Register(context.Background(), registerInput)
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
 	}
 
 	// Get user by ID
-	user, err := service.GetUserByID(result.User.ID)
+	user, err := service.GetUserByID(context.Background(), result.User.ID)
 	if err != nil {
 		t.Fatalf("GetUserByID failed: %v", err)
 	}
@@ -457,7 +516,7 @@ func TestGetUserByIDNotFound(t *testing.T) {
 
 	service := NewAuthService(db)
 
-	_, err := service.GetUserByID("non-existent-id")
+	_, err := service.GetUserByID(context.Background(), "non-existent-id")
 	if err == nil {
 		t.Error("Expected error for non-existent user ID")
 	}
@@ -470,10 +529,7 @@ func BenchmarkRegister(b *testing.B) {
 	})
 	db.AutoMigrate(&models.User{}, &models.RefreshToken{})
 
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	defer setupTestEnv()()
 
 	service := NewAuthService(db)
 
@@ -483,7 +539,7 @@ func BenchmarkRegister(b *testing.B) {
 			Email:    "benchmark" + string(rune(i)) + "@example.com",
 			Password: "password123",
 		}
-		service.Register(input)
+		service.Register(context.Background(), input)
 	}
 }
 
@@ -493,15 +549,12 @@ func BenchmarkLogin(b *testing.B) {
 	})
 	db.AutoMigrate(&models.User{}, &models.RefreshToken{})
 
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	defer setupTestEnv()()
 
 	service := NewAuthService(db)
 
 	// Create a user to login with
-	service.Register(RegisterInput{
+	service.Register(context.Background(), RegisterInput{
 		Email:    "benchmark@example.com",
 		Password: "password123",
 	})
@@ -513,6 +566,210 @@ func BenchmarkLogin(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		service.Login(loginInput)
+		service.Login(context.Background(), loginInput)
+	}
+}
+
+func TestLoginSuspendedUser(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+
+	result, err := service.Register(context.Background(), RegisterInput{
+		Email:    "suspended@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	reason := "Terms of service violation"
+	db.Model(&models.User{}).Where("id = ?", result.User.ID).Updates(map[string]interface{}{
+		"is_active":         false,
+		"suspended_at":      time.Now(),
+		"suspension_reason": reason,
+	})
+
+	_, err = service.Login(context.Background(), LoginInput{
+		Email:    "suspended@example.com",
+		Password: "password123",
+	})
+	if !errors.Is(err, ErrAccountSuspended) {
+		t.Fatalf("Expected ErrAccountSuspended, got: %v", err)
+	}
+
+	var suspended *AccountSuspendedError
+	if !errors.As(err, &suspended) || suspended.Reason == nil || *suspended.Reason != reason {
+		t.Error("Suspension error should carry the reason")
+	}
+}
+
+func TestLoginSuspendedUntilMessage(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+
+	result, err := service.Register(context.Background(), RegisterInput{
+		Email:    "until@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	until := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	db.Model(&models.User{}).Where("id = ?", result.User.ID).Updates(map[string]interface{}{
+		"is_active":       false,
+		"suspended_at":    time.Now(),
+		"suspended_until": until,
+	})
+
+	_, err = service.Login(context.Background(), LoginInput{
+		Email:    "until@example.com",
+		Password: "password123",
+	})
+
+	appErr := apperror.From(err)
+	expected := "Your account has been suspended until " + until.Format(time.RFC3339)
+	if appErr.Code != "ACCOUNT_SUSPENDED" || appErr.Message != expected {
+		t.Errorf("Expected ACCOUNT_SUSPENDED %q, got %s %q", expected, appErr.Code, appErr.Message)
+	}
+}
+
+func TestLoginExpiredSuspension(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+
+	result, err := service.Register(context.Background(), RegisterInput{
+		Email:    "expiredsuspension@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	db.Model(&models.User{}).Where("id = ?", result.User.ID).Updates(map[string]interface{}{
+		"is_active":       false,
+		"suspended_at":    time.Now().Add(-48 * time.Hour),
+		"suspended_until": time.Now().Add(-time.Hour),
+	})
+
+	if _, err := service.Login(context.Background(), LoginInput{
+		Email:    "expiredsuspension@example.com",
+		Password: "password123",
+	}); err != nil {
+		t.Fatalf("Login after expired suspension failed: %v", err)
+	}
+
+	var user models.User
+	db.First(&user, "id = ?", result.User.ID)
+	if !user.IsActive || user.SuspendedAt != nil {
+		t.Error("Expired suspension should be lifted on login")
+	}
+}
+
+func TestRefreshSuspendedUserRevokesTokens(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+
+	result, err := service.Register(context.Background(), RegisterInput{
+		Email:    "refreshsuspended@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	tokenA, _ := service.CreateRefreshToken(context.Background(), result.User.ID)
+	service.CreateRefreshToken(context.Background(), result.User.ID)
+
+	db.Model(&models.User{}).Where("id = ?", result.User.ID).Update("is_active", false)
+
+	if _, err := service.RefreshAccessToken(context.Background(), tokenA); !errors.Is(err, ErrAccountSuspended) {
+		t.Fatalf("Expected ErrAccountSuspended, got: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.RefreshToken{}).Where("user_id = ?", result.User.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("All refresh tokens should be revoked, %d left", count)
+	}
+}
+
+func TestUpdateProfileName(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+	name := "Original"
+	result, err := service.Register(context.Background(), RegisterInput{Email: "name@example.com", Password: "password123", Name: &name})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	// Changing only the locale keeps the name
+	locale := "en"
+	user, err := service.UpdateProfile(context.Background(), result.User.ID, UpdateProfileInput{Locale: &locale})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if user.Name == nil || *user.Name != "Original" {
+		t.Errorf("Name should be kept when omitted, got %v", user.Name)
+	}
+
+	name = "Renamed"
+	if user, _ = service.UpdateProfile(context.Background(), result.User.ID, UpdateProfileInput{Name: &name}); user.Name == nil || *user.Name != "Renamed" {
+		t.Errorf("Expected name Renamed, got %v", user.Name)
+	}
+}
+
+func TestUpdateProfileLocale(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewAuthService(db)
+	result, err := service.Register(context.Background(), RegisterInput{Email: "locale@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	locale := "RU"
+	user, err := service.UpdateProfile(context.Background(), result.User.ID, UpdateProfileInput{Locale: &locale})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if user.Locale == nil || *user.Locale != "ru" {
+		t.Fatalf("Expected locale ru, got %v", user.Locale)
+	}
+
+	// The preference travels in the access token
+	login, err := service.Login(context.Background(), LoginInput{Email: "locale@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	payload, err := utils.VerifyAccessToken(login.AccessToken)
+	if err != nil || payload.Locale != "ru" {
+		t.Errorf("Expected locale claim ru, got %+v (%v)", payload, err)
+	}
+
+	// Omitting the locale keeps it, an empty one clears it
+	if user, _ = service.UpdateProfile(context.Background(), result.User.ID, UpdateProfileInput{}); user.Locale == nil {
+		t.Error("Locale should be kept when omitted")
+	}
+	locale = ""
+	if user, _ = service.UpdateProfile(context.Background(), result.User.ID, UpdateProfileInput{Locale: &locale}); user.Locale != nil {
+		t.Errorf("Locale should be cleared, got %v", *user.Locale)
 	}
 }
@@ -0,0 +1,208 @@
+// Package cors holds the CORS policies of the route groups. Policies are stored
+// in AppSettings and can be changed at runtime; middleware.CORSMiddleware applies them.
+package cors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"backend-go-fiber/internal/models"
+)
+
+// PoliciesSettingKey is the AppSettings key holding the policies (JSON object of group -> Policy)
+const PoliciesSettingKey = "cors_policies"
+
+// Route groups with their own policy
+const (
+	GroupPublic = "public"  // everything not matched by another group
+	GroupAPIKey = "api_key" // requests of machine clients carrying an API key
+	GroupAdmin  = "admin"   // /api/admin/*
+)
+
+// DefaultMaxAge is how long browsers may cache a preflight result, in seconds
+const DefaultMaxAge = 600
+
+// Policy decides which browser origins may call a route group
+type Policy struct {
+	// Origins are exact origins ("https://app.example.com"), subdomain
+	// patterns ("https://*.example.com") or "*" for any origin
+	Origins []string `json:"origins"`
+
+	// AllowCredentials lets browsers send cookies and Authorization; not allowed with "*"
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+
+	// MaxAge is sent as Access-Control-Max-Age on preflights, in seconds
+	// (0 = DefaultMaxAge, -1 = don't cache)
+	MaxAge int `json:"maxAge,omitempty"`
+}
+
+// AllowsOrigin reports whether origin matches one of the policy's origins
+func (p Policy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, pattern := range p.Origins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) || matchWildcard(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreflightMaxAge returns the Access-Control-Max-Age value in seconds
+func (p Policy) PreflightMaxAge() int {
+	switch {
+	case p.MaxAge == 0:
+		return DefaultMaxAge
+	case p.MaxAge < 0:
+		return 0
+	}
+	return p.MaxAge
+}
+
+// matchWildcard matches "scheme://*.domain[:port]" against an origin.
+// The wildcard stands for one or more subdomain labels, never the bare domain.
+func matchWildcard(pattern, origin string) bool {
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	prefix := scheme + "://"
+	if len(origin) <= len(prefix) || !strings.EqualFold(origin[:len(prefix)], prefix) {
+		return false
+	}
+
+	sub, ok := cutSuffixFold(origin[len(prefix):], "."+host)
+	return ok && sub != "" && !strings.ContainsAny(sub, "/:@")
+}
+
+func cutSuffixFold(s, suffix string) (string, bool) {
+	if len(s) < len(suffix) || !strings.EqualFold(s[len(s)-len(suffix):], suffix) {
+		return s, false
+	}
+	return s[:len(s)-len(suffix)], true
+}
+
+func (p Policy) validate(group string) error {
+	if len(p.Origins) == 0 {
+		return fmt.Errorf("%s: at least one origin is required", group)
+	}
+	if p.MaxAge < -1 {
+		return fmt.Errorf("%s: maxAge must be -1 (no caching), 0 (default) or positive", group)
+	}
+
+	for _, origin := range p.Origins {
+		if origin == "*" {
+			if p.AllowCredentials {
+				return fmt.Errorf(`%s: origin "*" cannot be combined with allowCredentials`, group)
+			}
+			continue
+		}
+		if err := validateOrigin(origin); err != nil {
+			return fmt.Errorf("%s: %w", group, err)
+		}
+	}
+	return nil
+}
+
+func validateOrigin(origin string) error {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("origin %q must look like https://example.com or https://*.example.com", origin)
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("origin %q must not have a path, query or credentials", origin)
+	}
+	if strings.Contains(strings.TrimPrefix(origin, u.Scheme+"://*."), "*") {
+		return fmt.Errorf("origin %q: only a leading *. subdomain wildcard is supported", origin)
+	}
+	return nil
+}
+
+// ParsePolicies parses and validates the policies stored in settings.
+// The public policy is required; other groups fall back to it.
+func ParsePolicies(value string) (map[string]Policy, error) {
+	var policies map[string]Policy
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		return nil, fmt.Errorf("invalid CORS policies: %w", err)
+	}
+
+	if _, ok := policies[GroupPublic]; !ok {
+		return nil, fmt.Errorf("a %q policy is required", GroupPublic)
+	}
+	for group, policy := range policies {
+		switch group {
+		case GroupPublic, GroupAPIKey, GroupAdmin:
+		default:
+			return nil, fmt.Errorf("unknown group %q (expected %s, %s or %s)", group, GroupPublic, GroupAPIKey, GroupAdmin)
+		}
+		if err := policy.validate(group); err != nil {
+			return nil, err
+		}
+	}
+
+	return policies, nil
+}
+
+// DefaultPolicies returns the policies used before they were configurable:
+// origins (config.CORS) with credentials for browsers, any origin without
+// credentials for API-key clients
+func DefaultPolicies(origins []string) map[string]Policy {
+	return map[string]Policy{
+		GroupPublic: {Origins: origins, AllowCredentials: true},
+		GroupAPIKey: {Origins: []string{"*"}},
+		GroupAdmin:  {Origins: origins, AllowCredentials: true},
+	}
+}
+
+// PoliciesSetting returns the default AppSettings row for the policies
+func PoliciesSetting(origins []string) models.AppSettings {
+	b, _ := json.Marshal(DefaultPolicies(origins))
+	return models.AppSettings{
+		Key:          PoliciesSettingKey,
+		Value:        string(b),
+		Type:         models.SettingTypeJSON,
+		Label:        "CORS Policies",
+		SettingGroup: "security",
+	}
+}
+
+// Policies holds the current policies; they are replaced atomically on reload
+type Policies struct {
+	policies atomic.Pointer[map[string]Policy]
+}
+
+// NewPolicies creates a policy set
+func NewPolicies(policies map[string]Policy) *Policies {
+	p := &Policies{}
+	p.Set(policies)
+	return p
+}
+
+// Set atomically replaces the policies
+func (p *Policies) Set(policies map[string]Policy) {
+	p.policies.Store(&policies)
+}
+
+// ApplySetting parses the policies from their settings value and applies them.
+// Invalid values are rejected and the current policies are kept.
+func (p *Policies) ApplySetting(value string) error {
+	policies, err := ParsePolicies(value)
+	if err != nil {
+		return err
+	}
+	p.Set(policies)
+	return nil
+}
+
+// For returns the policy of a group, or the public policy if the group has none
+func (p *Policies) For(group string) Policy {
+	policies := *p.policies.Load()
+	if policy, ok := policies[group]; ok {
+		return policy
+	}
+	return policies[GroupPublic]
+}
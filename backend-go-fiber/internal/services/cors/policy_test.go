@@ -0,0 +1,88 @@
+package cors
+
+import "testing"
+
+func TestAllowsOrigin(t *testing.T) {
+	policy := Policy{Origins: []string{"https://app.example.com", "https://*.partner.io", "http://localhost:3000"}}
+
+	tests := []struct {
+		origin   string
+		expected bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"https://evil.example.com", false},
+		{"http://app.example.com", false},
+		{"https://a.partner.io", true},
+		{"https://a.b.partner.io", true},
+		{"https://partner.io", false},
+		{"https://evilpartner.io", false},
+		{"https://a.partner.io.evil.com", false},
+		{"http://a.partner.io", false},
+		{"https://a.partner.io:8443", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := policy.AllowsOrigin(tt.origin); got != tt.expected {
+			t.Errorf("AllowsOrigin(%q) = %v, expected %v", tt.origin, got, tt.expected)
+		}
+	}
+
+	if !(Policy{Origins: []string{"*"}}).AllowsOrigin("https://anything.dev") {
+		t.Error(`"*" should allow any origin`)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	valid := []string{
+		`{"public": {"origins": ["https://app.example.com"], "allowCredentials": true, "maxAge": 3600}}`,
+		`{"public": {"origins": ["*"]}, "admin": {"origins": ["https://*.example.com"], "allowCredentials": true}}`,
+		`{"public": {"origins": ["http://localhost:3000"]}, "api_key": {"origins": ["*"], "maxAge": -1}}`,
+	}
+	for _, value := range valid {
+		if _, err := ParsePolicies(value); err != nil {
+			t.Errorf("ParsePolicies(%s) failed: %v", value, err)
+		}
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"admin": {"origins": ["https://app.example.com"]}}`,
+		`{"public": {"origins": []}}`,
+		`{"public": {"origins": ["*"], "allowCredentials": true}}`,
+		`{"public": {"origins": ["app.example.com"]}}`,
+		`{"public": {"origins": ["https://app.example.com/"]}}`,
+		`{"public": {"origins": ["https://app.*.com"]}}`,
+		`{"public": {"origins": ["ftp://example.com"]}}`,
+		`{"public": {"origins": ["*"], "maxAge": -5}}`,
+		`{"public": {"origins": ["*"]}, "partners": {"origins": ["*"]}}`,
+	}
+	for _, value := range invalid {
+		if _, err := ParsePolicies(value); err == nil {
+			t.Errorf("ParsePolicies(%s) should fail", value)
+		}
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	policies := NewPolicies(DefaultPolicies([]string{"http://localhost:3000"}))
+	if !policies.For(GroupAdmin).AllowsOrigin("http://localhost:3000") {
+		t.Error("Default admin policy should allow the configured origins")
+	}
+
+	if err := policies.ApplySetting(`{"public": {"origins": ["https://app.example.com"], "maxAge": 60}}`); err != nil {
+		t.Fatalf("ApplySetting failed: %v", err)
+	}
+	if got := policies.For(GroupAdmin); !got.AllowsOrigin("https://app.example.com") || got.PreflightMaxAge() != 60 {
+		t.Errorf("Groups without a policy should fall back to public, got %+v", got)
+	}
+
+	if err := policies.ApplySetting(`{"public": {"origins": []}}`); err == nil {
+		t.Error("Expected invalid setting to be rejected")
+	}
+	if !policies.For(GroupPublic).AllowsOrigin("https://app.example.com") {
+		t.Error("Rejected setting must keep the previous policies")
+	}
+}
@@ -0,0 +1,233 @@
+// Package csp builds the Content-Security-Policy header and collects the
+// violation reports browsers send back.
+package csp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReportEndpointName is the Reporting-Endpoints name used by report-to
+const ReportEndpointName = "csp-endpoint"
+
+// DefaultReportURI is where browsers send violation reports
+const DefaultReportURI = "/api/csp-report"
+
+// Directives maps directive names to their sources ("script-src": ["'self'"]).
+// A directive with no sources is sent bare ("upgrade-insecure-requests").
+type Directives map[string][]string
+
+// Config describes the policy
+type Config struct {
+	Directives Directives
+
+	// NonceDirectives get the per-request 'nonce-...' source. Browsers ignore
+	// 'unsafe-inline' in a directive that has a nonce.
+	NonceDirectives []string
+
+	// ReportOnly sends Content-Security-Policy-Report-Only: violations are
+	// reported but not blocked
+	ReportOnly bool
+
+	// ReportURI adds report-uri and report-to directives (empty disables reporting)
+	ReportURI string
+}
+
+// DefaultConfig returns the policy of the environment.
+// Scripts need the request nonce; styles keep 'unsafe-inline' because Svelte
+// sets inline style attributes, which a nonce can't allow.
+func DefaultConfig(production bool) Config {
+	if production {
+		// Production: allow common CDNs, Google Fonts, analytics
+		return Config{
+			Directives: Directives{
+				"default-src":     {"'self'"},
+				"script-src":      {"'self'", "https://www.googletagmanager.com", "https://www.google-analytics.com"},
+				"style-src":       {"'self'", "'unsafe-inline'", "https://fonts.googleapis.com"},
+				"img-src":         {"'self'", "data:", "https:", "blob:"},
+				"connect-src":     {"'self'", "https://www.google-analytics.com", "https://analytics.google.com"},
+				"font-src":        {"'self'", "https://fonts.gstatic.com"},
+				"object-src":      {"'none'"},
+				"media-src":       {"'self'"},
+				"frame-src":       {"'none'"},
+				"base-uri":        {"'self'"},
+				"form-action":     {"'self'"},
+				"frame-ancestors": {"'none'"},
+			},
+			NonceDirectives: []string{"script-src"},
+			ReportURI:       DefaultReportURI,
+		}
+	}
+
+	// Development: Vite HMR injects inline scripts and uses WebSockets
+	return Config{
+		Directives: Directives{
+			"default-src": {"'self'"},
+			"script-src":  {"'self'", "'unsafe-inline'"},
+			"style-src":   {"'self'", "'unsafe-inline'"},
+			"img-src":     {"'self'", "data:", "https:"},
+			"connect-src": {"'self'", "ws:", "wss:"},
+			"font-src":    {"'self'"},
+			"object-src":  {"'none'"},
+			"media-src":   {"'self'"},
+			"frame-src":   {"'none'"},
+		},
+		ReportURI: DefaultReportURI,
+	}
+}
+
+// Options are the configurable parts of the policy (config.CSP)
+type Options struct {
+	// Directives are merged over the defaults, an empty list removes a
+	// directive (bare ones like upgrade-insecure-requests are added by [])
+	Directives Directives
+
+	// NonceDirectives get the nonce; nil keeps the default
+	NonceDirectives []string
+
+	// ReportOnly reports violations without blocking
+	ReportOnly bool
+
+	// ReportURI is the report endpoint, "" keeps the default, "off" disables reporting
+	ReportURI string
+}
+
+// NewConfig starts from DefaultConfig, applies opts and validates the result
+func NewConfig(production bool, opts Options) (Config, error) {
+	cfg := DefaultConfig(production)
+
+	for name, sources := range opts.Directives {
+		if sources == nil || len(sources) == 0 && !bareDirectives[name] {
+			delete(cfg.Directives, name)
+			continue
+		}
+		cfg.Directives[name] = sources
+	}
+
+	if opts.NonceDirectives != nil {
+		cfg.NonceDirectives = opts.NonceDirectives
+	}
+
+	cfg.ReportOnly = opts.ReportOnly
+
+	switch opts.ReportURI {
+	case "":
+	case "off":
+		cfg.ReportURI = ""
+	default:
+		cfg.ReportURI = opts.ReportURI
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// bareDirectives take no sources
+var bareDirectives = map[string]bool{
+	"upgrade-insecure-requests": true,
+	"block-all-mixed-content":   true,
+}
+
+// Validate checks directive names and sources
+func (c Config) Validate() error {
+	var problems []string
+	for name, sources := range c.Directives {
+		if !validDirectiveName(name) {
+			problems = append(problems, fmt.Sprintf("invalid directive name %q", name))
+		}
+		for _, source := range sources {
+			if source == "" || strings.ContainsAny(source, ";, \t\r\n") {
+				problems = append(problems, fmt.Sprintf("%s: invalid source %q", name, source))
+			}
+		}
+	}
+	for _, name := range c.NonceDirectives {
+		if _, ok := c.Directives[name]; !ok {
+			problems = append(problems, fmt.Sprintf("nonce directive %q is not in the policy", name))
+		}
+	}
+	if strings.ContainsAny(c.ReportURI, `;, "`) {
+		problems = append(problems, fmt.Sprintf("invalid report URI %q", c.ReportURI))
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid CSP: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validDirectiveName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderName returns the response header carrying the policy
+func (c Config) HeaderName() string {
+	if c.ReportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// Header renders the policy for one response. default-src comes first, the
+// other directives in alphabetical order, reporting last.
+func (c Config) Header(nonce string) string {
+	names := make([]string, 0, len(c.Directives))
+	for name := range c.Directives {
+		if name != "default-src" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := c.Directives["default-src"]; ok {
+		names = append([]string{"default-src"}, names...)
+	}
+
+	parts := make([]string, 0, len(names)+2)
+	for _, name := range names {
+		sources := c.Directives[name]
+		if nonce != "" && c.hasNonce(name) {
+			sources = append(sources[:len(sources):len(sources)], "'nonce-"+nonce+"'")
+		}
+		parts = append(parts, strings.TrimSpace(name+" "+strings.Join(sources, " ")))
+	}
+
+	if c.ReportURI != "" {
+		parts = append(parts, "report-uri "+c.ReportURI, "report-to "+ReportEndpointName)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func (c Config) hasNonce(name string) bool {
+	for _, n := range c.NonceDirectives {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportingEndpoints returns the Reporting-Endpoints header value for report-to
+func (c Config) ReportingEndpoints() string {
+	return ReportEndpointName + `="` + c.ReportURI + `"`
+}
+
+// NewNonce returns a random base64 nonce (128 bits)
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,90 @@
+package csp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	cfg := Config{
+		Directives: Directives{
+			"script-src":                {"'self'"},
+			"default-src":               {"'self'"},
+			"upgrade-insecure-requests": {},
+			"base-uri":                  {"'none'"},
+		},
+		NonceDirectives: []string{"script-src"},
+		ReportURI:       "/api/csp-report",
+	}
+
+	want := "default-src 'self'; base-uri 'none'; script-src 'self' 'nonce-abc'; upgrade-insecure-requests; " +
+		"report-uri /api/csp-report; report-to csp-endpoint"
+	if got := cfg.Header("abc"); got != want {
+		t.Errorf("Header() = %q, want %q", got, want)
+	}
+
+	// The nonce must not leak into the shared directive sources
+	if got := cfg.Header("def"); strings.Contains(got, "abc") {
+		t.Errorf("second Header() kept the first nonce: %q", got)
+	}
+	if got := cfg.ReportingEndpoints(); got != `csp-endpoint="/api/csp-report"` {
+		t.Errorf("ReportingEndpoints() = %q", got)
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := NewConfig(true, Options{
+		Directives:      Directives{"img-src": {"'self'"}, "frame-src": {}, "upgrade-insecure-requests": {}},
+		NonceDirectives: []string{"script-src", "style-src"},
+		ReportOnly:      true,
+		ReportURI:       "off",
+	})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	header := cfg.Header("n")
+	for _, want := range []string{"img-src 'self';", "upgrade-insecure-requests", "script-src 'self'", "'nonce-n'"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header %q does not contain %q", header, want)
+		}
+	}
+	for _, unwanted := range []string{"frame-src", "report-uri", "blob:"} {
+		if strings.Contains(header, unwanted) {
+			t.Errorf("header %q contains %q", header, unwanted)
+		}
+	}
+	if cfg.HeaderName() != "Content-Security-Policy-Report-Only" {
+		t.Errorf("HeaderName() = %q", cfg.HeaderName())
+	}
+}
+
+func TestNewConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"bad name", Options{Directives: Directives{"Script_Src": {"'self'"}}}},
+		{"injected directive", Options{Directives: Directives{"script-src": {"'self'; object-src *"}}}},
+		{"unknown nonce directive", Options{NonceDirectives: []string{"worker-src"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConfig(false, tt.opts); err == nil {
+				t.Errorf("NewConfig(%+v): expected an error", tt.opts)
+			}
+		})
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	a, err := NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewNonce()
+	if a == b || len(a) != 24 {
+		t.Errorf("NewNonce() = %q, %q: expected distinct 24-character values", a, b)
+	}
+}
@@ -0,0 +1,272 @@
+package csp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/ratelimit"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Content types of violation reports
+const (
+	MIMECSPReport   = "application/csp-report"   // report-uri
+	MIMEReportsJSON = "application/reports+json" // report-to (Reporting API)
+)
+
+// DefaultMaxReports bounds the number of distinct violations kept
+const DefaultMaxReports = 1000
+
+// ErrInvalidReport is returned for bodies that are not violation reports
+var ErrInvalidReport = errors.New("invalid CSP report")
+
+// ReportRateLimit limits the reports one client may send: a page violating the
+// policy sends a report for every blocked resource on every visit.
+// Route "*" because it is applied to the report endpoint only.
+func ReportRateLimit() ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:    "csp_report",
+		Route:   "*",
+		Max:     30,
+		Window:  ratelimit.Duration(time.Minute),
+		KeyBy:   ratelimit.KeyByIP,
+		Message: "Too many CSP reports",
+	}
+}
+
+// Report is one violation, in the fields common to both report formats
+type Report struct {
+	DocumentURI        string
+	EffectiveDirective string
+	BlockedURI         string
+	SourceFile         string
+	LineNumber         int
+	ColumnNumber       int
+	Disposition        string
+	Sample             string
+}
+
+// legacyReport is the report-uri format: {"csp-report": {...}}
+type legacyReport struct {
+	DocumentURI        string      `json:"document-uri"`
+	ViolatedDirective  string      `json:"violated-directive"`
+	EffectiveDirective string      `json:"effective-directive"`
+	BlockedURI         string      `json:"blocked-uri"`
+	SourceFile         string      `json:"source-file"`
+	LineNumber         json.Number `json:"line-number"`
+	ColumnNumber       json.Number `json:"column-number"`
+	Disposition        string      `json:"disposition"`
+	ScriptSample       string      `json:"script-sample"`
+}
+
+// reportingAPIReport is one entry of a report-to batch
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string      `json:"documentURL"`
+		EffectiveDirective string      `json:"effectiveDirective"`
+		BlockedURL         string      `json:"blockedURL"`
+		SourceFile         string      `json:"sourceFile"`
+		LineNumber         json.Number `json:"lineNumber"`
+		ColumnNumber       json.Number `json:"columnNumber"`
+		Disposition        string      `json:"disposition"`
+		Sample             string      `json:"sample"`
+	} `json:"body"`
+}
+
+// ParseReports decodes a report-uri or report-to request body.
+// Reporting API entries of other types (deprecation, intervention) are skipped.
+func ParseReports(body []byte) ([]Report, error) {
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) == 0 {
+		return nil, ErrInvalidReport
+	}
+
+	// report-to sends a JSON array
+	if body[0] == '[' {
+		var entries []reportingAPIReport
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidReport, err)
+		}
+
+		var reports []Report
+		for _, e := range entries {
+			if e.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, Report{
+				DocumentURI:        e.Body.DocumentURL,
+				EffectiveDirective: e.Body.EffectiveDirective,
+				BlockedURI:         e.Body.BlockedURL,
+				SourceFile:         e.Body.SourceFile,
+				LineNumber:         atoi(e.Body.LineNumber),
+				ColumnNumber:       atoi(e.Body.ColumnNumber),
+				Disposition:        e.Body.Disposition,
+				Sample:             e.Body.Sample,
+			})
+		}
+		return reports, nil
+	}
+
+	var legacy struct {
+		Report *legacyReport `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReport, err)
+	}
+	if legacy.Report == nil {
+		return nil, ErrInvalidReport
+	}
+
+	r := legacy.Report
+	directive := r.EffectiveDirective
+	if directive == "" {
+		// Older browsers only send the violated directive with its sources
+		directive, _, _ = strings.Cut(r.ViolatedDirective, " ")
+	}
+	return []Report{{
+		DocumentURI:        r.DocumentURI,
+		EffectiveDirective: directive,
+		BlockedURI:         r.BlockedURI,
+		SourceFile:         r.SourceFile,
+		LineNumber:         atoi(r.LineNumber),
+		ColumnNumber:       atoi(r.ColumnNumber),
+		Disposition:        r.Disposition,
+		Sample:             r.ScriptSample,
+	}}, nil
+}
+
+func atoi(n json.Number) int {
+	i, _ := strconv.Atoi(n.String())
+	return i
+}
+
+// normalize drops query strings and fragments (they vary per visit and may
+// carry tokens) and bounds the length of every field
+func (r Report) normalize() Report {
+	r.DocumentURI = truncate(stripQuery(r.DocumentURI), 2048)
+	r.BlockedURI = truncate(stripQuery(r.BlockedURI), 2048)
+	r.SourceFile = truncate(stripQuery(r.SourceFile), 2048)
+	r.EffectiveDirective = truncate(r.EffectiveDirective, 64)
+	r.Disposition = truncate(r.Disposition, 16)
+	r.Sample = truncate(r.Sample, 256)
+	return r
+}
+
+func stripQuery(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		// Keywords like "inline", "eval" or garbage
+		return uri
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.User = nil
+	return u.String()
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// Fingerprint identifies a violation for deduplication
+func (r Report) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		r.EffectiveDirective, r.BlockedURI, r.DocumentURI, r.SourceFile,
+		strconv.Itoa(r.LineNumber), strconv.Itoa(r.ColumnNumber), r.Disposition,
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReportService stores violation reports, one row per distinct violation
+type ReportService struct {
+	db *gorm.DB
+
+	// MaxReports bounds the number of distinct violations kept; once it is
+	// reached a new violation replaces the least recently seen ones
+	MaxReports int64
+}
+
+// NewReportService creates a report service
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db, MaxReports: DefaultMaxReports}
+}
+
+// Record stores a report or counts it against an identical one
+func (s *ReportService) Record(ctx context.Context, report Report, userAgent string) error {
+	db := s.db.WithContext(ctx)
+	report = report.normalize()
+	fingerprint := report.Fingerprint()
+	now := time.Now()
+
+	counted, err := s.count(db, fingerprint, now)
+	if err != nil || counted {
+		return err
+	}
+
+	var total int64
+	if err := db.Model(&models.CSPReport{}).Count(&total).Error; err != nil {
+		return err
+	}
+	if total >= s.MaxReports {
+		// Make room: a new violation is more useful than one nobody hit for a while
+		stale := db.Model(&models.CSPReport{}).Select("id").Order("last_seen_at").Limit(int(total - s.MaxReports + 1))
+		evicted := db.Where("id IN (?)", stale).Delete(&models.CSPReport{})
+		if evicted.Error != nil {
+			return evicted.Error
+		}
+		log.Ctx(ctx).Debug().Int64("evicted", evicted.RowsAffected).Msg("CSP report store is full, evicted least recently seen violations")
+	}
+
+	err = db.Create(&models.CSPReport{
+		Fingerprint:        fingerprint,
+		DocumentURI:        report.DocumentURI,
+		EffectiveDirective: report.EffectiveDirective,
+		BlockedURI:         report.BlockedURI,
+		SourceFile:         report.SourceFile,
+		LineNumber:         report.LineNumber,
+		ColumnNumber:       report.ColumnNumber,
+		Disposition:        report.Disposition,
+		Sample:             report.Sample,
+		UserAgent:          truncate(userAgent, 512),
+		Count:              1,
+		FirstSeenAt:        now,
+		LastSeenAt:         now,
+	}).Error
+	if err != nil {
+		// Another request stored the same violation first
+		if counted, countErr := s.count(db, fingerprint, now); countErr == nil && counted {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// count increments a known violation; false if there is none
+func (s *ReportService) count(db *gorm.DB, fingerprint string, now time.Time) (bool, error) {
+	result := db.Model(&models.CSPReport{}).Where("fingerprint = ?", fingerprint).Updates(map[string]interface{}{
+		"count":        gorm.Expr("count + 1"),
+		"last_seen_at": now,
+	})
+	return result.RowsAffected > 0, result.Error
+}
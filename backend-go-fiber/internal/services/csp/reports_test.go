@@ -0,0 +1,104 @@
+package csp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestParseReports(t *testing.T) {
+	legacy := `{"csp-report": {
+		"document-uri": "https://app.example.com/login?next=/admin",
+		"violated-directive": "script-src-elem 'self'",
+		"blocked-uri": "https://evil.com/x.js",
+		"line-number": 12,
+		"disposition": "enforce"
+	}}`
+	reports, err := ParseReports([]byte(legacy))
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("ParseReports(legacy) = %v, %v", reports, err)
+	}
+	if r := reports[0]; r.EffectiveDirective != "script-src-elem" || r.LineNumber != 12 || r.BlockedURI != "https://evil.com/x.js" {
+		t.Errorf("legacy report parsed as %+v", r)
+	}
+
+	batch := `[
+		{"type": "csp-violation", "body": {"documentURL": "https://app.example.com/", "effectiveDirective": "img-src", "blockedURL": "https://cdn.test/a.png", "lineNumber": 3}},
+		{"type": "deprecation", "body": {}}
+	]`
+	reports, err = ParseReports([]byte(batch))
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("ParseReports(batch) = %v, %v", reports, err)
+	}
+	if r := reports[0]; r.EffectiveDirective != "img-src" || r.LineNumber != 3 {
+		t.Errorf("report-to entry parsed as %+v", r)
+	}
+
+	for _, body := range []string{"", "{}", "not json", `{"csp-report": "x"}`} {
+		if _, err := ParseReports([]byte(body)); !errors.Is(err, ErrInvalidReport) {
+			t.Errorf("ParseReports(%q) error = %v, want ErrInvalidReport", body, err)
+		}
+	}
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Every connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.CSPReport{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestRecordDeduplicates(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewReportService(db)
+	service.MaxReports = 2
+	ctx := context.Background()
+
+	report := Report{DocumentURI: "https://app.example.com/a?token=secret", EffectiveDirective: "script-src-elem", BlockedURI: "inline"}
+	for i := 0; i < 3; i++ {
+		if err := service.Record(ctx, report, "test"); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	// The query string varies per visit and is not part of the violation
+	report.DocumentURI = "https://app.example.com/a?token=other"
+	service.Record(ctx, report, "test")
+
+	var stored []models.CSPReport
+	db.Find(&stored)
+	if len(stored) != 1 || stored[0].Count != 4 {
+		t.Fatalf("stored %+v, want one report counted 4 times", stored)
+	}
+	if stored[0].DocumentURI != "https://app.example.com/a" {
+		t.Errorf("DocumentURI = %q, query string not stripped", stored[0].DocumentURI)
+	}
+
+	// Once the store is full new violations replace the least recently seen ones
+	for _, blocked := range []string{"https://a.test", "https://b.test"} {
+		time.Sleep(time.Millisecond) // distinct last_seen_at
+		service.Record(ctx, Report{EffectiveDirective: "img-src", BlockedURI: blocked}, "test")
+	}
+	stored = nil
+	db.Order("blocked_uri").Find(&stored)
+	if len(stored) != 2 || stored[0].BlockedURI != "https://a.test" || stored[1].BlockedURI != "https://b.test" {
+		t.Errorf("stored %+v, want the two newest violations", stored)
+	}
+}
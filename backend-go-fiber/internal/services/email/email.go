@@ -33,6 +33,20 @@ type Sender interface {
 	SendTemplate(ctx context.Context, to []string, templateName string, data map[string]interface{}) error
 }
 
+// Checker is implemented by senders that can verify their server is
+// reachable without sending an email (readiness checks)
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// Check verifies the server of sender, or does nothing if sender can't be checked
+func Check(ctx context.Context, sender Sender) error {
+	if checker, ok := sender.(Checker); ok {
+		return checker.Check(ctx)
+	}
+	return nil
+}
+
 // Config holds email service configuration
 type Config struct {
 	// Provider type: "smtp", "sendgrid", "ses", "mailgun"
@@ -71,6 +85,7 @@ const (
 	TemplateEmailVerify      = "email_verify"
 	TemplateWelcome          = "welcome"
 	TemplatePasswordChanged  = "password_changed"
+	TemplateOrgInvitation    = "org_invitation"
 )
 
 // DefaultTemplates provides basic email templates
@@ -154,6 +169,27 @@ var DefaultTemplates = map[string]Template{
 		<p style="color: #666; font-size: 14px;">If you didn't make this change, please contact support immediately.</p>
 	</div>
 </body>
+</html>`,
+	},
+	TemplateOrgInvitation: {
+		Subject: "You're invited to join {{.OrgName}}",
+		Body:    "You have been invited to join {{.OrgName}} as {{.Role}}.\n\nAccept the invitation: {{.InviteURL}}\n\nThis link expires in {{.ExpiresIn}}.",
+		HTML: `
+<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"></head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+	<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+		<h2 style="color: #3b82f6;">Join {{.OrgName}}</h2>
+		<p>You have been invited to join <strong>{{.OrgName}}</strong> as {{.Role}}.</p>
+		<p style="margin: 30px 0;">
+			<a href="{{.InviteURL}}" style="background-color: #3b82f6; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block;">
+				Accept Invitation
+			</a>
+		</p>
+		<p style="color: #666; font-size: 14px;">This link expires in {{.ExpiresIn}}.</p>
+	</div>
+</body>
 </html>`,
 	},
 }
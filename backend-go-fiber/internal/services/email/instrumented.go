@@ -0,0 +1,73 @@
+package email
+
+import (
+	"context"
+
+	"backend-go-fiber/internal/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("backend-go-fiber/email")
+
+// InstrumentedSender wraps a Sender, records a span for every email and
+// counts sent and failed emails. Recipient addresses are not recorded, only their count.
+type InstrumentedSender struct {
+	sender   Sender
+	provider string
+}
+
+// NewInstrumentedSender wraps sender; provider is reported as email.provider ("smtp", "mock")
+func NewInstrumentedSender(sender Sender, provider string) *InstrumentedSender {
+	return &InstrumentedSender{sender: sender, provider: provider}
+}
+
+// Send sends an email
+func (s *InstrumentedSender) Send(ctx context.Context, email *Email) error {
+	ctx, span := tracer.Start(ctx, "email.Send", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("email.provider", s.provider),
+			attribute.Int("email.recipients", len(email.To)),
+		))
+	defer span.End()
+
+	return record(span, "", s.sender.Send(ctx, email))
+}
+
+// SendTemplate sends an email using a named template
+func (s *InstrumentedSender) SendTemplate(ctx context.Context, to []string, templateName string, data map[string]interface{}) error {
+	ctx, span := tracer.Start(ctx, "email.SendTemplate", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("email.provider", s.provider),
+			attribute.String("email.template", templateName),
+			attribute.Int("email.recipients", len(to)),
+		))
+	defer span.End()
+
+	return record(span, templateName, s.sender.SendTemplate(ctx, to, templateName, data))
+}
+
+// Check checks the wrapped sender
+func (s *InstrumentedSender) Check(ctx context.Context) error {
+	return Check(ctx, s.sender)
+}
+
+// record finishes the span and counts the email; template is empty for plain emails
+func record(span trace.Span, template string, err error) error {
+	if template == "" {
+		template = "none"
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		metrics.Emails.WithLabelValues(template, metrics.EmailFailed).Inc()
+		return err
+	}
+
+	metrics.Emails.WithLabelValues(template, metrics.EmailSent).Inc()
+	return nil
+}
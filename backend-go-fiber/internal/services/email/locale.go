@@ -0,0 +1,18 @@
+package email
+
+import (
+	"context"
+
+	"backend-go-fiber/internal/i18n"
+)
+
+// localizedTemplate returns the template in the locale of ctx (see i18n.WithLocale).
+// The i18n catalog's translation is used when it has one, templates otherwise.
+func localizedTemplate(ctx context.Context, templates map[string]Template, name string) (Template, bool) {
+	if translated, ok := i18n.Default().Email(i18n.FromContext(ctx), name); ok {
+		return Template{Subject: translated.Subject, Body: translated.Body, HTML: translated.HTML}, true
+	}
+
+	tmpl, ok := templates[name]
+	return tmpl, ok
+}
@@ -3,6 +3,8 @@ package email
 import (
 	"context"
 
+	"backend-go-fiber/internal/i18n"
+
 	"github.com/rs/zerolog/log"
 )
 
@@ -47,11 +49,12 @@ func (s *MockSender) SendTemplate(ctx context.Context, to []string, templateName
 	log.Info().
 		Strs("to", to).
 		Str("template", templateName).
+		Str("locale", i18n.FromContext(ctx)).
 		Interface("data", data).
 		Msg("📧 [MOCK] Template email sent")
 
 	// Create email from template for storage
-	tmpl, ok := s.templates[templateName]
+	tmpl, ok := localizedTemplate(ctx, s.templates, templateName)
 	if ok {
 		email := Email{
 			To:       to,
@@ -6,7 +6,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"html/template"
+	"net"
 	"net/smtp"
+	"strconv"
 	"strings"
 	texttemplate "text/template"
 
@@ -159,9 +161,39 @@ func (s *SMTPSender) sendTLS(addr string, auth smtp.Auth, from string, to []stri
 	return client.Quit()
 }
 
+// Check connects to the SMTP server and greets it with EHLO
+func (s *SMTPSender) Check(ctx context.Context) error {
+	addr := net.JoinHostPort(s.config.SMTPHost, strconv.Itoa(s.config.SMTPPort))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if s.config.SMTPUseTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.config.SMTPHost})
+	}
+
+	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return fmt.Errorf("EHLO failed: %w", err)
+	}
+	return client.Quit()
+}
+
 // SendTemplate sends an email using a named template
 func (s *SMTPSender) SendTemplate(ctx context.Context, to []string, templateName string, data map[string]interface{}) error {
-	tmpl, ok := s.templates[templateName]
+	tmpl, ok := localizedTemplate(ctx, s.templates, templateName)
 	if !ok {
 		return fmt.Errorf("template not found: %s", templateName)
 	}
@@ -0,0 +1,45 @@
+package email
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SwappableSender forwards to a Sender that can be replaced at runtime
+// (new SMTP credentials on config reload). Emails being sent finish on the
+// sender they started with.
+type SwappableSender struct {
+	current atomic.Pointer[senderBox]
+}
+
+// senderBox lets atomic.Pointer hold an interface value
+type senderBox struct {
+	sender Sender
+}
+
+// NewSwappableSender forwards to sender until Swap is called
+func NewSwappableSender(sender Sender) *SwappableSender {
+	s := &SwappableSender{}
+	s.Swap(sender)
+	return s
+}
+
+// Swap makes sender receive the following emails
+func (s *SwappableSender) Swap(sender Sender) {
+	s.current.Store(&senderBox{sender: sender})
+}
+
+// Check checks the current sender
+func (s *SwappableSender) Check(ctx context.Context) error {
+	return Check(ctx, s.current.Load().sender)
+}
+
+// Send sends an email
+func (s *SwappableSender) Send(ctx context.Context, email *Email) error {
+	return s.current.Load().sender.Send(ctx, email)
+}
+
+// SendTemplate sends an email using a named template
+func (s *SwappableSender) SendTemplate(ctx context.Context, to []string, templateName string, data map[string]interface{}) error {
+	return s.current.Load().sender.SendTemplate(ctx, to, templateName, data)
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// MemoryStore keeps records in process memory.
+// Keys are not shared between prefork processes or instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	lastGC  time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord)}
+}
+
+// Reserve implements Store
+func (s *MemoryStore) Reserve(_ context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastGC) > time.Minute {
+		s.lastGC = now
+		for k, r := range s.records {
+			if !now.Before(r.expiresAt) {
+				delete(s.records, k)
+			}
+		}
+	}
+
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		record := existing.Record
+		return &record, nil
+	}
+
+	s.records[key] = memoryRecord{
+		Record:    Record{Fingerprint: fingerprint},
+		expiresAt: now.Add(lockTTL),
+	}
+	return nil, nil
+}
+
+// Complete implements Store
+func (s *MemoryStore) Complete(_ context.Context, key string, resp Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	record.Completed = true
+	record.Status = resp.Status
+	record.ContentType = resp.ContentType
+	record.Body = append([]byte(nil), resp.Body...)
+	record.expiresAt = time.Now().Add(ttl)
+	s.records[key] = record
+	return nil
+}
+
+// Release implements Store
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Close implements Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
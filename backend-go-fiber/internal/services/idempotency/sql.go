@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLStore keeps records in the idempotency_records table, so all processes
+// and instances sharing the database see the same keys
+type SQLStore struct {
+	db   *gorm.DB
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewSQLStore creates the table if needed and starts the expired record cleanup.
+// gcInterval <= 0 disables the cleanup (expired records are still ignored).
+func NewSQLStore(db *gorm.DB, gcInterval time.Duration) (*SQLStore, error) {
+	if err := db.AutoMigrate(&models.IdempotencyRecord{}); err != nil {
+		return nil, err
+	}
+
+	s := &SQLStore{
+		db:   db,
+		done: make(chan struct{}),
+	}
+
+	if gcInterval > 0 {
+		s.wg.Add(1)
+		go s.gc(gcInterval)
+	}
+
+	return s, nil
+}
+
+// Reserve implements Store. The insert is the lock: the primary key lets only
+// one of several concurrent inserts succeed.
+func (s *SQLStore) Reserve(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	// An expired record no longer blocks the key
+	if err := db.Where("key = ? AND expires_at <= ?", key, now).
+		Delete(&models.IdempotencyRecord{}).Error; err != nil {
+		return nil, err
+	}
+
+	record := models.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(lockTTL),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyRecord
+	if err := db.Where("key = ?", key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released between our insert and read - report it as in progress,
+			// the client retries anyway
+			return &Record{Fingerprint: fingerprint}, nil
+		}
+		return nil, err
+	}
+
+	return &Record{
+		Fingerprint: existing.Fingerprint,
+		Completed:   existing.CompletedAt != nil,
+		Status:      existing.Status,
+		ContentType: existing.ContentType,
+		Body:        existing.Body,
+	}, nil
+}
+
+// Complete implements Store
+func (s *SQLStore) Complete(ctx context.Context, key string, resp Response, ttl time.Duration) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{
+			"status":       resp.Status,
+			"content_type": resp.ContentType,
+			"body":         resp.Body,
+			"completed_at": now,
+			"expires_at":   now.Add(ttl),
+		}).Error
+}
+
+// Release implements Store
+func (s *SQLStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&models.IdempotencyRecord{}).Error
+}
+
+// Close stops the cleanup goroutine and waits for a running cleanup.
+// The database connection is owned by the caller.
+func (s *SQLStore) Close() error {
+	s.once.Do(func() { close(s.done) })
+	s.wg.Wait()
+	return nil
+}
+
+func (s *SQLStore) gc(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.db.Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyRecord{})
+		}
+	}
+}
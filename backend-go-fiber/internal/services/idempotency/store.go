@@ -0,0 +1,80 @@
+// Package idempotency stores requests made with an Idempotency-Key header and
+// their responses, see middleware.Idempotency.
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Store backends
+const (
+	BackendSQL    = "sql"
+	BackendMemory = "memory"
+)
+
+// Record is a request seen with an idempotency key
+type Record struct {
+	Fingerprint string
+	Completed   bool
+
+	// Response of the first request, set once Completed
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Response is what Complete stores for replay
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Store is the pluggable storage behind the idempotency middleware.
+// Implementations must make Reserve atomic: of several concurrent calls for
+// the same key exactly one may win.
+type Store interface {
+	// Reserve claims key for a request with the given fingerprint. The claim
+	// expires after lockTTL if the request never completes (crashed process).
+	// If the key is already claimed or completed, the existing record is
+	// returned and nothing is changed.
+	Reserve(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Record, error)
+
+	// Complete stores the response for key and keeps it for ttl
+	Complete(ctx context.Context, key string, resp Response, ttl time.Duration) error
+
+	// Release drops the claim so the request can be retried
+	Release(ctx context.Context, key string) error
+
+	// Close releases background resources
+	Close() error
+}
+
+// Config holds idempotency store configuration
+type Config struct {
+	// Backend is "sql" (default) or "memory"
+	Backend string
+
+	// TTL is how long responses are kept for replay
+	TTL time.Duration
+
+	// GCInterval is how often the sql backend deletes expired records
+	GCInterval time.Duration
+}
+
+// NewStore creates the store for cfg.Backend.
+// The memory backend is only correct for a single process without prefork.
+func NewStore(cfg Config, db *gorm.DB) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendSQL:
+		return NewSQLStore(db, cfg.GCInterval)
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown idempotency store: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,97 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupSQLStore creates a SQL store on an in-memory SQLite database
+func setupSQLStore(t *testing.T) *SQLStore {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Every connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	s, err := NewSQLStore(db, 0)
+	if err != nil {
+		t.Fatalf("Failed to create SQL store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	t.Run("first reserve wins", func(t *testing.T) {
+		existing, err := s.Reserve(ctx, "k1", "fp", time.Minute)
+		if err != nil || existing != nil {
+			t.Fatalf("Expected key to be claimed, got %+v, %v", existing, err)
+		}
+
+		existing, err = s.Reserve(ctx, "k1", "fp", time.Minute)
+		if err != nil {
+			t.Fatalf("Reserve failed: %v", err)
+		}
+		if existing == nil || existing.Completed {
+			t.Fatalf("Expected in-progress record, got %+v", existing)
+		}
+	})
+
+	t.Run("completed response is returned", func(t *testing.T) {
+		s.Reserve(ctx, "k2", "fp", time.Minute)
+		resp := Response{Status: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+		if err := s.Complete(ctx, "k2", resp, time.Hour); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+
+		existing, err := s.Reserve(ctx, "k2", "other", time.Minute)
+		if err != nil {
+			t.Fatalf("Reserve failed: %v", err)
+		}
+		if existing == nil || !existing.Completed || existing.Fingerprint != "fp" {
+			t.Fatalf("Expected completed record with original fingerprint, got %+v", existing)
+		}
+		if existing.Status != 201 || string(existing.Body) != `{"id":1}` || existing.ContentType != "application/json" {
+			t.Errorf("Unexpected stored response: %+v", existing)
+		}
+	})
+
+	t.Run("release frees the key", func(t *testing.T) {
+		s.Reserve(ctx, "k3", "fp", time.Minute)
+		if err := s.Release(ctx, "k3"); err != nil {
+			t.Fatalf("Release failed: %v", err)
+		}
+		if existing, _ := s.Reserve(ctx, "k3", "fp", time.Minute); existing != nil {
+			t.Errorf("Expected released key to be claimable, got %+v", existing)
+		}
+	})
+
+	t.Run("expired claim frees the key", func(t *testing.T) {
+		s.Reserve(ctx, "k4", "fp", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		if existing, _ := s.Reserve(ctx, "k4", "fp", time.Minute); existing != nil {
+			t.Errorf("Expected expired key to be claimable, got %+v", existing)
+		}
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	testStore(t, setupSQLStore(t))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
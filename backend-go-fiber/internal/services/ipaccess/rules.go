@@ -0,0 +1,154 @@
+// Package ipaccess holds the IP allow and deny lists of the route groups. Rules
+// are stored in AppSettings and can be changed at runtime; middleware.IPAccessMiddleware applies them.
+package ipaccess
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/utils"
+)
+
+// RulesSettingKey is the AppSettings key holding the rules (JSON array of Rule)
+const RulesSettingKey = "ip_access_rules"
+
+// Rule restricts the clients of a route group
+type Rule struct {
+	Name string `json:"name"`
+
+	// Route is an exact path ("/api/auth/login") or a prefix ending in "*"
+	// ("/api/admin/*", "*"). Paths are unversioned and case-insensitive: /API/v1/admin matches /api/admin/*.
+	Route string `json:"route"`
+
+	// Allow lists the only IPs and CIDR ranges that may call the route (empty = any)
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists IPs and CIDR ranges that are always rejected, even if allowed
+	Deny []string `json:"deny,omitempty"`
+
+	allow utils.IPList
+	deny  utils.IPList
+}
+
+// Matches reports whether the rule applies to path
+func (r Rule) Matches(path string) bool {
+	if prefix, ok := strings.CutSuffix(r.Route, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == r.Route || path == r.Route+"/"
+}
+
+// Permits reports whether ip passes the rule: not denied and, if the rule has
+// an allow list, in it. Unparseable IPs only pass rules without an allow list.
+func (r Rule) Permits(ip string) bool {
+	if r.deny.Contains(ip) {
+		return false
+	}
+	return len(r.allow) == 0 || r.allow.Contains(ip)
+}
+
+func (r *Rule) compile() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("name is required")
+	case r.Route == "":
+		return fmt.Errorf("rule %q: route is required", r.Name)
+	case len(r.Allow) == 0 && len(r.Deny) == 0:
+		return fmt.Errorf("rule %q: allow or deny is required", r.Name)
+	}
+
+	// Matched against apiversion.CanonicalPath, which is lower case
+	r.Route = strings.ToLower(r.Route)
+
+	var err error
+	if r.allow, err = parseList(r.Allow); err != nil {
+		return fmt.Errorf("rule %q: allow: %w", r.Name, err)
+	}
+	if r.deny, err = parseList(r.Deny); err != nil {
+		return fmt.Errorf("rule %q: deny: %w", r.Name, err)
+	}
+	return nil
+}
+
+func parseList(entries []string) (utils.IPList, error) {
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" || strings.Contains(entry, ",") {
+			return nil, fmt.Errorf("invalid entry %q: one IP or CIDR range per entry", entry)
+		}
+	}
+	return utils.ParseIPList(strings.Join(entries, ","))
+}
+
+// ParseRules parses and validates the rules stored in settings
+func ParseRules(value string) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("invalid IP access rules: %w", err)
+	}
+
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, err
+		}
+		if names[rules[i].Name] {
+			return nil, fmt.Errorf("duplicate rule name %q", rules[i].Name)
+		}
+		names[rules[i].Name] = true
+	}
+
+	return rules, nil
+}
+
+// RulesSetting returns the default AppSettings row for the rules: no restrictions
+func RulesSetting() models.AppSettings {
+	return models.AppSettings{
+		Key:          RulesSettingKey,
+		Value:        "[]",
+		Type:         models.SettingTypeJSON,
+		Label:        "IP Access Rules",
+		SettingGroup: "security",
+	}
+}
+
+// Rules holds the current rules; they are replaced atomically on reload
+type Rules struct {
+	rules atomic.Pointer[[]Rule]
+}
+
+// NewRules creates an empty rule set
+func NewRules() *Rules {
+	r := &Rules{}
+	r.rules.Store(&[]Rule{})
+	return r
+}
+
+// ApplySetting parses the rules from their settings value and applies them.
+// Invalid values are rejected and the current rules are kept.
+func (r *Rules) ApplySetting(value string) error {
+	rules, err := ParseRules(value)
+	if err != nil {
+		return err
+	}
+	r.rules.Store(&rules)
+	return nil
+}
+
+// Len returns the number of rules
+func (r *Rules) Len() int {
+	return len(*r.rules.Load())
+}
+
+// Check returns the first rule matching path that rejects ip.
+// Every matching rule must permit the client.
+func (r *Rules) Check(path, ip string) (Rule, bool) {
+	for _, rule := range *r.rules.Load() {
+		if rule.Matches(path) && !rule.Permits(ip) {
+			return rule, false
+		}
+	}
+	return Rule{}, true
+}
@@ -0,0 +1,75 @@
+package ipaccess
+
+import "testing"
+
+func TestParseRules(t *testing.T) {
+	valid := []string{
+		`[]`,
+		`[{"name": "admin", "route": "/api/admin/*", "allow": ["10.8.0.0/16", "198.51.100.7"]}]`,
+		`[{"name": "abuse", "route": "*", "deny": ["203.0.113.0/24", "2001:db8::/32"]}]`,
+	}
+	for _, value := range valid {
+		if _, err := ParseRules(value); err != nil {
+			t.Errorf("ParseRules(%s) error = %v", value, err)
+		}
+	}
+
+	invalid := []string{
+		`{}`,
+		`[{"route": "*", "deny": ["10.0.0.1"]}]`,
+		`[{"name": "a", "deny": ["10.0.0.1"]}]`,
+		`[{"name": "a", "route": "*"}]`,
+		`[{"name": "a", "route": "*", "allow": ["10.0.0.0/33"]}]`,
+		`[{"name": "a", "route": "*", "deny": ["10.0.0.1, 10.0.0.2"]}]`,
+		`[{"name": "a", "route": "*", "deny": ["10.0.0.1"]}, {"name": "a", "route": "/x", "deny": ["10.0.0.2"]}]`,
+	}
+	for _, value := range invalid {
+		if _, err := ParseRules(value); err == nil {
+			t.Errorf("ParseRules(%s): expected an error", value)
+		}
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+	rules := NewRules()
+	if _, ok := rules.Check("/api/admin/users", "203.0.113.5"); !ok {
+		t.Fatal("empty rules must allow every client")
+	}
+
+	err := rules.ApplySetting(`[
+		{"name": "abuse", "route": "*", "deny": ["203.0.113.0/24"]},
+		{"name": "admin", "route": "/api/Admin/*", "allow": ["10.8.0.0/16", "203.0.113.9"]}
+	]`)
+	if err != nil {
+		t.Fatalf("ApplySetting() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		ip   string
+		rule string // rejecting rule, "" if allowed
+	}{
+		{"/api/auth/login", "198.51.100.1", ""},
+		{"/api/auth/login", "203.0.113.5", "abuse"},
+		{"/health", "203.0.113.5", "abuse"},
+		{"/api/admin/users", "10.8.3.4", ""},
+		{"/api/admin/users", "198.51.100.1", "admin"},
+		{"/api/admin/users", "203.0.113.9", "abuse"}, // deny wins over allow
+		{"/api/admin/users", "not-an-ip", "admin"},
+		{"/api/administrators", "198.51.100.1", ""},
+	}
+	for _, tt := range tests {
+		rule, ok := rules.Check(tt.path, tt.ip)
+		if ok != (tt.rule == "") || rule.Name != tt.rule {
+			t.Errorf("Check(%s, %s) = %q, %v; expected rule %q", tt.path, tt.ip, rule.Name, ok, tt.rule)
+		}
+	}
+
+	// Invalid settings keep the current rules
+	if err := rules.ApplySetting(`[{"name": "x"}]`); err == nil {
+		t.Fatal("ApplySetting() accepted an invalid rule")
+	}
+	if rules.Len() != 2 {
+		t.Errorf("Len() = %d after a rejected update, expected 2", rules.Len())
+	}
+}
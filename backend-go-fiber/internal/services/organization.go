@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/i18n"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/email"
+	"backend-go-fiber/internal/utils"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrganizationNotFound = apperror.NotFound(apperror.CodeNotFound, "Organization not found")
+	ErrNotOrgMember         = apperror.NotFound("NOT_ORG_MEMBER", "User is not a member of this organization")
+	ErrOrgSlugTaken         = apperror.Conflict(apperror.CodeConflict, "Organization slug already exists")
+	ErrInvalidOrgSlug       = apperror.BadRequest(apperror.CodeValidation, "Organization slug is invalid")
+	ErrInsufficientOrgRole  = apperror.Forbidden(apperror.CodeForbidden, "Insufficient organization role")
+	ErrLastOwner            = apperror.Conflict("LAST_OWNER", "Organization must keep at least one owner")
+	ErrAlreadyMember        = apperror.Conflict(apperror.CodeConflict, "User is already a member of this organization")
+	ErrInvalidInvitation    = apperror.BadRequest("INVALID_INVITATION", "Invalid or expired invitation")
+	ErrInvitationMismatch   = apperror.Forbidden("INVITATION_EMAIL_MISMATCH", "Invitation was sent to a different email")
+)
+
+// invitationTTL is how long an organization invitation stays valid
+const invitationTTL = 7 * 24 * time.Hour
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// OrganizationService handles organizations, memberships and invitations
+type OrganizationService struct {
+	db          *gorm.DB
+	emailSender email.Sender
+	frontendURL string // base of links in emails
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(db *gorm.DB, emailSender email.Sender, frontendURL string) *OrganizationService {
+	return &OrganizationService{
+		db:          db,
+		emailSender: emailSender,
+		frontendURL: frontendURL,
+	}
+}
+
+// CreateOrganizationInput represents the organization creation request
+type CreateOrganizationInput struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	Slug string `json:"slug" validate:"omitempty,min=2,max=50"`
+}
+
+// InviteMemberInput represents the invitation request
+type InviteMemberInput struct {
+	Email string         `json:"email" validate:"required,email"`
+	Role  models.OrgRole `json:"role" validate:"omitempty,oneof=owner admin member"`
+}
+
+// UpdateMemberRoleInput represents the member role change request
+type UpdateMemberRoleInput struct {
+	Role models.OrgRole `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// AcceptInvitationInput represents the invitation acceptance request
+type AcceptInvitationInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// SwitchOrganizationInput represents the active organization switch request
+type SwitchOrganizationInput struct {
+	OrganizationID string `json:"organizationId" validate:"required"`
+}
+
+// Create creates an organization and makes the creator its owner.
+// The new organization becomes active if the user has no active organization yet.
+func (s *OrganizationService) Create(ctx context.Context, userID string, input CreateOrganizationInput) (*models.Organization, error) {
+	db := s.db.WithContext(ctx)
+
+	slug := Slugify(input.Slug)
+	if slug == "" {
+		slug = Slugify(input.Name)
+	}
+	if slug == "" {
+		return nil, ErrInvalidOrgSlug
+	}
+
+	var count int64
+	if err := db.Unscoped().Model(&models.Organization{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrOrgSlugTaken
+	}
+
+	org := &models.Organization{
+		Name:        input.Name,
+		Slug:        slug,
+		CreatedByID: userID,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.Membership{
+			OrganizationID: org.ID,
+			UserID:         userID,
+			Role:           models.OrgRoleOwner,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ? AND active_organization_id IS NULL", userID).
+			Update("active_organization_id", org.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// ListForUser returns all organizations the user belongs to
+func (s *OrganizationService) ListForUser(ctx context.Context, userID string) ([]models.UserOrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var memberships []models.Membership
+	if err := db.Joins("Organization").Where("memberships.user_id = ?", userID).
+		Order("memberships.created_at ASC").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.UserOrganizationResponse, 0, len(memberships))
+	for _, m := range memberships {
+		if m.Organization.ID == "" {
+			continue // Organization was soft deleted
+		}
+		result = append(result, models.UserOrganizationResponse{
+			OrganizationResponse: m.Organization.ToResponse(),
+			Role:                 m.Role,
+			IsActive:             user.ActiveOrganizationID != nil && *user.ActiveOrganizationID == m.OrganizationID,
+		})
+	}
+
+	return result, nil
+}
+
+// GetMembership returns the membership of a user in an organization
+func (s *OrganizationService) GetMembership(ctx context.Context, orgID, userID string) (*models.Membership, error) {
+	var membership models.Membership
+	if err := s.db.WithContext(ctx).Scopes(utils.TenantScope(orgID)).Where("user_id = ?", userID).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotOrgMember
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// ListMembers returns all members of an organization
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID string) ([]models.MemberResponse, error) {
+	var memberships []models.Membership
+	if err := s.db.WithContext(ctx).Joins("User").Scopes(utils.TenantScope(orgID)).
+		Order("memberships.created_at ASC").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MemberResponse, 0, len(memberships))
+	for _, m := range memberships {
+		if m.User.ID == "" {
+			continue // User was soft deleted
+		}
+		result = append(result, m.ToMemberResponse())
+	}
+
+	return result, nil
+}
+
+// UpdateMemberRole changes the role of a member.
+// Only owners can grant or revoke the owner role, and the last owner cannot be demoted.
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, orgID string, actor *models.Membership, memberUserID string, role models.OrgRole) (*models.Membership, error) {
+	if !actor.Role.AtLeast(models.OrgRoleAdmin) {
+		return nil, ErrInsufficientOrgRole
+	}
+
+	member, err := s.GetMembership(ctx, orgID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if (member.Role == models.OrgRoleOwner || role == models.OrgRoleOwner) && actor.Role != models.OrgRoleOwner {
+		return nil, ErrInsufficientOrgRole
+	}
+
+	if member.Role == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, orgID, memberUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.db.WithContext(ctx).Save(member).Error; err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a user from an organization.
+// Members may always remove themselves; removing others requires the admin role.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID string, actor *models.Membership, memberUserID string) error {
+	member, err := s.GetMembership(ctx, orgID, memberUserID)
+	if err != nil {
+		return err
+	}
+
+	if actor.UserID != memberUserID {
+		if !actor.Role.AtLeast(models.OrgRoleAdmin) {
+			return ErrInsufficientOrgRole
+		}
+		if member.Role == models.OrgRoleOwner && actor.Role != models.OrgRoleOwner {
+			return ErrInsufficientOrgRole
+		}
+	}
+
+	if member.Role == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, orgID, memberUserID); err != nil {
+			return err
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(member).Error; err != nil {
+			return err
+		}
+
+		// Drop the org claim for the removed user on their next token refresh
+		return tx.Model(&models.User{}).
+			Where("id = ? AND active_organization_id = ?", memberUserID, orgID).
+			Update("active_organization_id", nil).Error
+	})
+}
+
+// Invite creates an invitation and emails it to the invitee
+func (s *OrganizationService) Invite(ctx context.Context, orgID string, actor *models.Membership, input InviteMemberInput) (*models.OrganizationInvitation, error) {
+	db := s.db.WithContext(ctx)
+
+	if !actor.Role.AtLeast(models.OrgRoleAdmin) {
+		return nil, ErrInsufficientOrgRole
+	}
+
+	role := input.Role
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+	if !actor.Role.AtLeast(role) {
+		return nil, ErrInsufficientOrgRole
+	}
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	emailAddr := strings.ToLower(strings.TrimSpace(input.Email))
+
+	// Reject invitations for existing members
+	var existing int64
+	if err := db.Model(&models.Membership{}).
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Scopes(utils.TenantScope(orgID)).
+		Where("LOWER(users.email) = ?", emailAddr).
+		Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyMember
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.OrganizationInvitation{
+		OrganizationID: orgID,
+		Email:          emailAddr,
+		Role:           role,
+		TokenDigest:    models.TokenDigest{TokenHash: models.HashToken(token)},
+		InvitedByID:    actor.UserID,
+		ExpiresAt:      time.Now().Add(invitationTTL),
+		Token:          token,
+	}
+
+	// Replace any pending invitation for the same address
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Scopes(utils.TenantScope(orgID)).Where("email = ? AND accepted_at IS NULL", emailAddr).
+			Delete(&models.OrganizationInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.emailSender.SendTemplate(ctx, []string{emailAddr}, email.TemplateOrgInvitation, map[string]interface{}{
+		"OrgName":   org.Name,
+		"Role":      string(role),
+		"InviteURL": s.frontendURL + "/invitations/accept?token=" + token,
+		"ExpiresIn": i18n.T(ctx, "7 days"),
+	}); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("email", emailAddr).Str("org", orgID).Msg("Failed to send organization invitation email")
+		// Don't fail the request - the invitation can be re-sent
+	}
+
+	return invitation, nil
+}
+
+// ListInvitations returns pending invitations of an organization
+func (s *OrganizationService) ListInvitations(ctx context.Context, orgID string) ([]models.InvitationResponse, error) {
+	var invitations []models.OrganizationInvitation
+	if err := s.db.WithContext(ctx).Scopes(utils.TenantScope(orgID)).Where("accepted_at IS NULL AND expires_at > ?", time.Now()).
+		Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]models.InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		result[i] = inv.ToResponse()
+	}
+
+	return result, nil
+}
+
+// RevokeInvitation deletes a pending invitation
+func (s *OrganizationService) RevokeInvitation(ctx context.Context, orgID string, actor *models.Membership, invitationID string) error {
+	if !actor.Role.AtLeast(models.OrgRoleAdmin) {
+		return ErrInsufficientOrgRole
+	}
+
+	result := s.db.WithContext(ctx).Scopes(utils.TenantScope(orgID)).Where("id = ? AND accepted_at IS NULL", invitationID).
+		Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidInvitation
+	}
+
+	return nil
+}
+
+// AcceptInvitation adds the user to the inviting organization.
+// The invitation must have been sent to the user's email address.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID, token string) (*models.Membership, error) {
+	db := s.db.WithContext(ctx)
+
+	var invitation models.OrganizationInvitation
+	if err := db.Where("token_hash = ?", models.HashToken(token)).First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	if !invitation.IsPending() {
+		return nil, ErrInvalidInvitation
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationMismatch
+	}
+
+	if _, err := s.GetMembership(ctx, invitation.OrganizationID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	}
+
+	membership := &models.Membership{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           invitation.Role,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&invitation).Update("accepted_at", &now).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ? AND active_organization_id IS NULL", userID).
+			Update("active_organization_id", invitation.OrganizationID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return membership, nil
+}
+
+// CleanupExpiredInvitations removes expired, unaccepted invitations (call periodically)
+func (s *OrganizationService) CleanupExpiredInvitations(ctx context.Context) error {
+	result := s.db.WithContext(ctx).Where("accepted_at IS NULL AND expires_at < ?", time.Now()).Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Debug().Int64("count", result.RowsAffected).Msg("Cleaned up expired organization invitations")
+	}
+
+	return nil
+}
+
+// ensureAnotherOwner fails if userID is the only owner of the organization
+func (s *OrganizationService) ensureAnotherOwner(ctx context.Context, orgID, userID string) error {
+	var owners int64
+	if err := s.db.WithContext(ctx).Model(&models.Membership{}).Scopes(utils.TenantScope(orgID)).
+		Where("role = ? AND user_id != ?", models.OrgRoleOwner, userID).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// Slugify converts a name into a URL-safe organization slug
+func Slugify(name string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "-")
+	return strings.Trim(slug, "-")
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend-go-fiber/internal/i18n"
+	"backend-go-fiber/internal/models"
+	"backend-go-fiber/internal/services/email"
+	"backend-go-fiber/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// createTestUser registers a user and returns its ID
+func createTestUser(t *testing.T, db *gorm.DB, emailAddr string) string {
+	result, err := NewAuthService(db).Register(context.Background(), RegisterInput{
+		Email:    emailAddr,
+		Password: "password123",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	return result.User.ID
+}
+
+func TestCreateOrganization(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewOrganizationService(db, email.NewMockSender(email.Config{}), "http://localhost:3000")
+	userID := createTestUser(t, db, "owner@example.com")
+
+	org, err := service.Create(context.Background(), userID, CreateOrganizationInput{Name: "Acme Corp"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if org.Slug != "acme-corp" {
+		t.Errorf("Slug mismatch: got %s, want acme-corp", org.Slug)
+	}
+
+	membership, err := service.GetMembership(context.Background(), org.ID, userID)
+	if err != nil {
+		t.Fatalf("Creator should be a member: %v", err)
+	}
+	if membership.Role != models.OrgRoleOwner {
+		t.Errorf("Creator role mismatch: got %s, want owner", membership.Role)
+	}
+
+	// First organization becomes the active one
+	var user models.User
+	db.First(&user, "id = ?", userID)
+	if user.ActiveOrganizationID == nil || *user.ActiveOrganizationID != org.ID {
+		t.Error("First organization should become the active organization")
+	}
+
+	// Duplicate slug is rejected
+	if _, err := service.Create(context.Background(), userID, CreateOrganizationInput{Name: "Other", Slug: "acme-corp"}); !errors.Is(err, ErrOrgSlugTaken) {
+		t.Errorf("Expected ErrOrgSlugTaken, got: %v", err)
+	}
+}
+
+func TestInviteAndAcceptInvitation(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	sender := email.NewMockSender(email.Config{})
+	service := NewOrganizationService(db, sender, "http://localhost:3000")
+	ownerID := createTestUser(t, db, "owner@example.com")
+	inviteeID := createTestUser(t, db, "invitee@example.com")
+	otherID := createTestUser(t, db, "other@example.com")
+
+	org, err := service.Create(context.Background(), ownerID, CreateOrganizationInput{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	owner, _ := service.GetMembership(context.Background(), org.ID, ownerID)
+
+	invitation, err := service.Invite(context.Background(), org.ID, owner, InviteMemberInput{
+		Email: "Invitee@Example.com",
+		Role:  models.OrgRoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("Invite failed: %v", err)
+	}
+	if sender.GetLastEmail() == nil {
+		t.Error("Invitation email should be sent")
+	}
+
+	// Only the invited address can accept
+	if _, err := service.AcceptInvitation(context.Background(), otherID, invitation.Token); !errors.Is(err, ErrInvitationMismatch) {
+		t.Errorf("Expected ErrInvitationMismatch, got: %v", err)
+	}
+
+	membership, err := service.AcceptInvitation(context.Background(), inviteeID, invitation.Token)
+	if err != nil {
+		t.Fatalf("AcceptInvitation failed: %v", err)
+	}
+	if membership.Role != models.OrgRoleAdmin {
+		t.Errorf("Role mismatch: got %s, want admin", membership.Role)
+	}
+
+	// Invitation cannot be reused
+	if _, err := service.AcceptInvitation(context.Background(), inviteeID, invitation.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("Expected ErrInvalidInvitation, got: %v", err)
+	}
+
+	// Admins cannot invite owners
+	admin, _ := service.GetMembership(context.Background(), org.ID, inviteeID)
+	if _, err := service.Invite(context.Background(), org.ID, admin, InviteMemberInput{
+		Email: "new@example.com",
+		Role:  models.OrgRoleOwner,
+	}); !errors.Is(err, ErrInsufficientOrgRole) {
+		t.Errorf("Expected ErrInsufficientOrgRole, got: %v", err)
+	}
+
+	// Inviting the same address again replaces the pending invitation
+	for range 2 {
+		if _, err := service.Invite(context.Background(), org.ID, owner, InviteMemberInput{Email: "new@example.com"}); err != nil {
+			t.Fatalf("Invite failed: %v", err)
+		}
+	}
+	pending, _ := service.ListInvitations(context.Background(), org.ID)
+	if len(pending) != 1 {
+		t.Errorf("Expected 1 pending invitation, got %d", len(pending))
+	}
+}
+
+func TestInvitationEmailLocale(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	sender := email.NewMockSender(email.Config{})
+	service := NewOrganizationService(db, sender, "http://localhost:3000")
+	ownerID := createTestUser(t, db, "owner@example.com")
+
+	org, err := service.Create(context.Background(), ownerID, CreateOrganizationInput{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	owner, _ := service.GetMembership(context.Background(), org.ID, ownerID)
+
+	for locale, subject := range map[string]string{
+		"en": email.DefaultTemplates[email.TemplateOrgInvitation].Subject,
+		"ru": "Приглашение в {{.OrgName}}",
+	} {
+		ctx := i18n.WithLocale(context.Background(), locale)
+		if _, err := service.Invite(ctx, org.ID, owner, InviteMemberInput{Email: "invitee@example.com", Role: models.OrgRoleMember}); err != nil {
+			t.Fatalf("Invite failed: %v", err)
+		}
+		if got := sender.GetLastEmail().Subject; got != subject {
+			t.Errorf("Locale %s: expected subject %q, got %q", locale, subject, got)
+		}
+	}
+}
+
+func TestLastOwnerProtection(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewOrganizationService(db, email.NewMockSender(email.Config{}), "http://localhost:3000")
+	ownerID := createTestUser(t, db, "owner@example.com")
+
+	org, _ := service.Create(context.Background(), ownerID, CreateOrganizationInput{Name: "Acme"})
+	owner, _ := service.GetMembership(context.Background(), org.ID, ownerID)
+
+	if _, err := service.UpdateMemberRole(context.Background(), org.ID, owner, ownerID, models.OrgRoleMember); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("Expected ErrLastOwner on demotion, got: %v", err)
+	}
+
+	if err := service.RemoveMember(context.Background(), org.ID, owner, ownerID); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("Expected ErrLastOwner on removal, got: %v", err)
+	}
+}
+
+func TestSwitchOrganization(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	authService := NewAuthService(db)
+	orgService := NewOrganizationService(db, email.NewMockSender(email.Config{}), "http://localhost:3000")
+	userID := createTestUser(t, db, "user@example.com")
+	strangerID := createTestUser(t, db, "stranger@example.com")
+
+	orgA, _ := orgService.Create(context.Background(), userID, CreateOrganizationInput{Name: "Org A"})
+	orgB, _ := orgService.Create(context.Background(), userID, CreateOrganizationInput{Name: "Org B"})
+	orgC, _ := orgService.Create(context.Background(), strangerID, CreateOrganizationInput{Name: "Org C"})
+
+	// Login issues the org claim of the active (first) organization
+	login, err := authService.Login(context.Background(), LoginInput{Email: "user@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	payload, _ := utils.VerifyAccessToken(login.AccessToken)
+	if payload.OrgID != orgA.ID || payload.OrgRole != string(models.OrgRoleOwner) {
+		t.Errorf("Login org claim mismatch: got %s/%s, want %s/owner", payload.OrgID, payload.OrgRole, orgA.ID)
+	}
+
+	result, err := authService.SwitchOrganization(context.Background(), userID, orgB.ID)
+	if err != nil {
+		t.Fatalf("SwitchOrganization failed: %v", err)
+	}
+	payload, _ = utils.VerifyAccessToken(result.AccessToken)
+	if payload.OrgID != orgB.ID {
+		t.Errorf("Switched org claim mismatch: got %s, want %s", payload.OrgID, orgB.ID)
+	}
+
+	if _, err := authService.SwitchOrganization(context.Background(), userID, orgC.ID); !errors.Is(err, ErrNotOrgMember) {
+		t.Errorf("Expected ErrNotOrgMember, got: %v", err)
+	}
+}
+
+func TestTenantScope(t *testing.T) {
+	db := setupTestDB(t)
+	cleanup := setupTestEnv()
+	defer cleanup()
+
+	service := NewOrganizationService(db, email.NewMockSender(email.Config{}), "http://localhost:3000")
+	userA := createTestUser(t, db, "a@example.com")
+	userB := createTestUser(t, db, "b@example.com")
+	orgA, _ := service.Create(context.Background(), userA, CreateOrganizationInput{Name: "Org A"})
+	service.Create(context.Background(), userB, CreateOrganizationInput{Name: "Org B"})
+
+	var memberships []models.Membership
+	if err := db.Scopes(utils.TenantScope(orgA.ID)).Find(&memberships).Error; err != nil {
+		t.Fatalf("Scoped query failed: %v", err)
+	}
+	if len(memberships) != 1 || memberships[0].UserID != userA {
+		t.Errorf("Tenant scope leaked rows: got %d memberships", len(memberships))
+	}
+
+	// Missing tenant fails closed
+	err := db.Scopes(utils.TenantScope("")).Find(&memberships).Error
+	if !errors.Is(err, utils.ErrMissingTenant) {
+		t.Errorf("Expected ErrMissingTenant, got: %v", err)
+	}
+	if _, err := service.ListMembers(context.Background(), ""); !errors.Is(err, utils.ErrMissingTenant) {
+		t.Errorf("Expected ListMembers to require an organization, got: %v", err)
+	}
+
+	members, err := service.ListMembers(context.Background(), orgA.ID)
+	if err != nil {
+		t.Fatalf("ListMembers failed: %v", err)
+	}
+	if len(members) != 1 {
+		t.Errorf("Expected only the members of Org A, got %d", len(members))
+	}
+}
@@ -5,9 +5,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
-	"os"
 	"time"
 
+	"backend-go-fiber/internal/apperror"
+	"backend-go-fiber/internal/i18n"
 	"backend-go-fiber/internal/models"
 	"backend-go-fiber/internal/services/email"
 	"backend-go-fiber/internal/utils"
@@ -17,33 +18,37 @@ import (
 )
 
 var (
-	ErrInvalidResetToken = errors.New("invalid or expired reset token")
-	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidResetToken = apperror.BadRequest("INVALID_TOKEN", "Invalid or expired reset token")
+	ErrUserNotFound      = apperror.NotFound("USER_NOT_FOUND", "User not found")
 )
 
 // PasswordResetService handles password reset logic
 type PasswordResetService struct {
 	db          *gorm.DB
 	emailSender email.Sender
+	frontendURL string // base of links in emails
 }
 
 // NewPasswordResetService creates a new password reset service
-func NewPasswordResetService(db *gorm.DB, emailSender email.Sender) *PasswordResetService {
+func NewPasswordResetService(db *gorm.DB, emailSender email.Sender, frontendURL string) *PasswordResetService {
 	return &PasswordResetService{
 		db:          db,
 		emailSender: emailSender,
+		frontendURL: frontendURL,
 	}
 }
 
 // RequestReset creates a reset token and sends email
 // Returns nil even if user doesn't exist (security: don't reveal if email exists)
 func (s *PasswordResetService) RequestReset(ctx context.Context, emailAddr string) error {
+	db := s.db.WithContext(ctx)
+
 	// Find user by email
 	var user models.User
-	if err := s.db.Where("email = ?", emailAddr).First(&user).Error; err != nil {
+	if err := db.Where("email = ?", emailAddr).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// Don't reveal if user exists - just log and return success
-			log.Debug().Str("email", emailAddr).Msg("Password reset requested for non-existent user")
+			log.Ctx(ctx).Debug().Str("email", emailAddr).Msg("Password reset requested for non-existent user")
 			return nil
 		}
 		return err
@@ -57,44 +62,46 @@ func (s *PasswordResetService) RequestReset(ctx context.Context, emailAddr strin
 
 	// Create reset token (valid for 1 hour)
 	resetToken := &models.PasswordResetToken{
-		Token:     token,
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(1 * time.Hour),
+		TokenDigest: models.TokenDigest{TokenHash: models.HashToken(token)},
+		UserID:      user.ID,
+		ExpiresAt:   time.Now().Add(1 * time.Hour),
 	}
 
 	// Invalidate any existing tokens for this user
-	s.db.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{})
+	db.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{})
 
 	// Save new token
-	if err := s.db.Create(resetToken).Error; err != nil {
+	if err := db.Create(resetToken).Error; err != nil {
 		return err
 	}
 
 	// Build reset URL
-	frontendURL := os.Getenv("FRONTEND_URL")
-	if frontendURL == "" {
-		frontendURL = "http://localhost:3000"
-	}
-	resetURL := frontendURL + "/reset-password?token=" + token
+	resetURL := s.frontendURL + "/reset-password?token=" + token
 
-	// Send email
-	if err := s.emailSender.SendTemplate(ctx, []string{user.Email}, email.TemplatePasswordReset, map[string]interface{}{
+	// Send email in the user's language if they chose one, the request's otherwise
+	mailCtx := ctx
+	if user.Locale != nil {
+		mailCtx = i18n.WithLocale(ctx, *user.Locale)
+	}
+	if err := s.emailSender.SendTemplate(mailCtx, []string{user.Email}, email.TemplatePasswordReset, map[string]interface{}{
 		"ResetURL":  resetURL,
-		"ExpiresIn": "1 hour",
+		"ExpiresIn": i18n.T(mailCtx, "1 hour"),
 		"Name":      user.Name,
 	}); err != nil {
-		log.Error().Err(err).Str("email", emailAddr).Msg("Failed to send password reset email")
+		log.Ctx(ctx).Error().Err(err).Str("email", emailAddr).Msg("Failed to send password reset email")
 		// Don't return error to user - token was created successfully
 	}
 
-	log.Info().Str("email", emailAddr).Msg("Password reset token created")
+	log.Ctx(ctx).Info().Str("email", emailAddr).Msg("Password reset token created")
 	return nil
 }
 
 // ValidateToken checks if a reset token is valid
 func (s *PasswordResetService) ValidateToken(ctx context.Context, token string) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
 	var resetToken models.PasswordResetToken
-	err := s.db.Preload("User").Where("token = ?", token).First(&resetToken).Error
+	err := db.Preload("User").Where("token_hash = ?", models.HashToken(token)).First(&resetToken).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrInvalidResetToken
@@ -109,30 +116,32 @@ func (s *PasswordResetService) ValidateToken(ctx context.Context, token string)
 	return &resetToken.User, nil
 }
 
-// ResetPassword validates token and updates password
-func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+// ResetPassword validates token and updates password. It returns the user whose password was reset.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
 	// Find and validate token
 	var resetToken models.PasswordResetToken
-	err := s.db.Preload("User").Where("token = ?", token).First(&resetToken).Error
+	err := db.Preload("User").Where("token_hash = ?", models.HashToken(token)).First(&resetToken).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrInvalidResetToken
+			return nil, ErrInvalidResetToken
 		}
-		return err
+		return nil, err
 	}
 
 	if !resetToken.IsValid() {
-		return ErrInvalidResetToken
+		return nil, ErrInvalidResetToken
 	}
 
 	// Hash new password
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Start transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = db.Transaction(func(tx *gorm.DB) error {
 		// Update password
 		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Update("password_hash", hashedPassword).Error; err != nil {
 			return err
@@ -151,11 +160,16 @@ func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPass
 
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resetToken.User, nil
 }
 
 // CleanupExpiredTokens removes expired tokens (call periodically)
 func (s *PasswordResetService) CleanupExpiredTokens(ctx context.Context) error {
-	result := s.db.Where("expires_at < ?", time.Now()).Delete(&models.PasswordResetToken{})
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.PasswordResetToken{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Result describes the state of a client's limit after a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is when the current window ends
+	Reset time.Duration
+
+	// RetryAfter is how long a rejected client has to wait
+	RetryAfter time.Duration
+
+	// counter and ttl locate the increment of an allowed request for Release
+	counter string
+	ttl     time.Duration
+}
+
+// window is a client's state under one policy, read from the counters of the
+// current and previous fixed window. Windows are aligned to multiples of the
+// window size, so every process agrees on their keys. The sliding window is
+// approximated from both: rate = prev * (unused part of the window) + curr.
+type window struct {
+	Start int64 // start of current window (unix ms)
+	Curr  int   // requests in current window
+	Prev  int   // requests in previous window
+}
+
+// Limiter applies rate limit policies using a shared Storage.
+// Policies can be replaced at runtime with SetPolicies.
+type Limiter struct {
+	storage  Storage
+	policies atomic.Pointer[[]Policy]
+}
+
+// NewLimiter creates a limiter; a nil storage keeps counters in process memory
+func NewLimiter(storage Storage, policies []Policy) *Limiter {
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	l := &Limiter{storage: storage}
+	l.SetPolicies(policies)
+	return l
+}
+
+// SetPolicies atomically replaces the policy table
+func (l *Limiter) SetPolicies(policies []Policy) {
+	l.policies.Store(&policies)
+}
+
+// ApplySetting parses the policy table from its settings value and applies it.
+// Invalid values are rejected and the current policies are kept.
+func (l *Limiter) ApplySetting(value string) error {
+	policies, err := ParsePolicies(value)
+	if err != nil {
+		return err
+	}
+	l.SetPolicies(policies)
+	return nil
+}
+
+// Policies returns the current policy table
+func (l *Limiter) Policies() []Policy {
+	return *l.policies.Load()
+}
+
+// Match returns the policies that apply to the request
+func (l *Limiter) Match(method, path string) []Policy {
+	var matched []Policy
+	for _, p := range l.Policies() {
+		if p.Matches(method, path) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// Take counts a request for key against policy p with the given limit.
+// Rejected requests are not counted. Counting is an atomic increment in the
+// storage, so Take needs no lock and concurrent requests can't overshoot the
+// limit; a request that doesn't fit takes its increment back.
+func (l *Limiter) Take(p Policy, key string, limit int) (Result, error) {
+	size := time.Duration(p.Window)
+	now := time.Now()
+	start := now.Truncate(size)
+
+	prefix := "rl:" + p.Name + ":" + key + ":"
+	currKey := prefix + strconv.FormatInt(start.UnixMilli(), 10)
+	prevKey := prefix + strconv.FormatInt(start.Add(-size).UnixMilli(), 10)
+	// Keep the counter while it can still influence the next window
+	ttl := start.Add(2 * size).Sub(now)
+
+	prev, err := l.storage.Count(prevKey)
+	if err != nil {
+		return Result{}, err
+	}
+	curr, err := l.storage.Increment(currKey, 1, ttl)
+	if err != nil {
+		return Result{}, err
+	}
+
+	w := window{Start: start.UnixMilli(), Curr: curr, Prev: prev}
+	result := Result{
+		Limit: limit,
+		Reset: start.Add(size).Sub(now),
+	}
+
+	if w.rate(now, size) > float64(limit) {
+		if _, err := l.storage.Increment(currKey, -1, ttl); err != nil {
+			return Result{}, err
+		}
+		w.Curr--
+		result.RetryAfter = w.retryAfter(now, size, limit)
+		return result, nil
+	}
+
+	result.Allowed = true
+	result.Remaining = max(limit-int(math.Ceil(w.rate(now, size))), 0)
+	result.counter = currKey
+	result.ttl = ttl
+	return result, nil
+}
+
+// Release takes back a request counted by an allowed Take, e.g. because
+// another policy rejected it
+func (l *Limiter) Release(result Result) error {
+	if !result.Allowed {
+		return nil
+	}
+	_, err := l.storage.Increment(result.counter, -1, result.ttl)
+	return err
+}
+
+// rate is the weighted request count over the last window length
+func (w *window) rate(now time.Time, size time.Duration) float64 {
+	elapsed := float64(now.UnixMilli()-w.Start) / float64(size.Milliseconds())
+	return float64(w.Prev)*(1-elapsed) + float64(w.Curr)
+}
+
+// retryAfter is the time until one more request fits under limit
+func (w *window) retryAfter(now time.Time, size time.Duration, limit int) time.Duration {
+	budget := float64(limit - 1)
+	windowEnd := time.UnixMilli(w.Start).Add(size)
+
+	if float64(w.Curr) <= budget {
+		// Fits later in this window, once enough of the previous window has slid out
+		needed := 1 - (budget-float64(w.Curr))/float64(w.Prev)
+		return time.UnixMilli(w.Start).Add(time.Duration(needed * float64(size))).Sub(now)
+	}
+
+	// Current window becomes the previous one and has to slide out partially
+	needed := 1 - budget/float64(w.Curr)
+	return windowEnd.Add(time.Duration(needed * float64(size))).Sub(now)
+}
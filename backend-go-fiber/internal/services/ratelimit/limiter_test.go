@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies(DefaultPoliciesJSON())
+	if err != nil {
+		t.Fatalf("Default policies should be valid: %v", err)
+	}
+	if len(policies) != len(DefaultPolicies()) {
+		t.Errorf("Expected %d policies, got %d", len(DefaultPolicies()), len(policies))
+	}
+	if time.Duration(policies[1].Window) != 5*time.Minute {
+		t.Errorf("Expected login window of 5m, got %v", time.Duration(policies[1].Window))
+	}
+
+	invalid := []struct {
+		name  string
+		value string
+	}{
+		{"not json", `{`},
+		{"missing route", `[{"name":"a","max":1,"window":"1m"}]`},
+		{"zero max", `[{"name":"a","route":"*","max":0,"window":"1m"}]`},
+		{"bad window", `[{"name":"a","route":"*","max":1,"window":"soon"}]`},
+		{"bad keyBy", `[{"name":"a","route":"*","max":1,"window":"1m","keyBy":"cookie"}]`},
+		{"duplicate name", `[{"name":"a","route":"*","max":1,"window":"1m"},{"name":"a","route":"/x","max":1,"window":"1m"}]`},
+	}
+	for _, tc := range invalid {
+		if _, err := ParsePolicies(tc.value); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestRejectKeyBy(t *testing.T) {
+	policies, err := ParsePolicies(`[{"name":"a","route":"*","max":1,"window":"1m","keyBy":"api_key"}]`)
+	if err != nil {
+		t.Fatalf("ParsePolicies failed: %v", err)
+	}
+	if err := RejectKeyBy(policies, KeyByAPIKey); err == nil {
+		t.Error("Expected api_key policy to be rejected")
+	}
+	if err := RejectKeyBy(DefaultPolicies(), KeyByAPIKey); err != nil {
+		t.Errorf("Expected default policies to pass, got %v", err)
+	}
+}
+
+func TestPolicyMatches(t *testing.T) {
+	login := Policy{Method: "POST", Route: "/api/auth/login"}
+	if !login.Matches("POST", "/api/auth/login") {
+		t.Error("Expected exact route to match")
+	}
+	if login.Matches("GET", "/api/auth/login") {
+		t.Error("Expected other method not to match")
+	}
+	if login.Matches("POST", "/api/auth/login-other") {
+		t.Error("Expected exact route not to match longer path")
+	}
+
+	// Paths arrive lower-cased from apiversion.CanonicalPath
+	policies, err := ParsePolicies(`[{"name":"login","method":"POST","route":"/api/Auth/LOGIN","max":1,"window":"1m"}]`)
+	if err != nil {
+		t.Fatalf("ParsePolicies failed: %v", err)
+	}
+	if !policies[0].Matches("POST", "/api/auth/login") {
+		t.Error("Expected mixed-case route to match the canonical path")
+	}
+
+	admin := Policy{Route: "/api/admin/*"}
+	if !admin.Matches("DELETE", "/api/admin/users/1") {
+		t.Error("Expected prefix route to match")
+	}
+	if admin.Matches("GET", "/api/auth/me") {
+		t.Error("Expected prefix route not to match other paths")
+	}
+}
+
+func TestPolicyLimitFor(t *testing.T) {
+	p := Policy{Max: 10, AdminMax: 100}
+	if p.LimitFor(false) != 10 || p.LimitFor(true) != 100 {
+		t.Error("Expected separate admin tier")
+	}
+
+	p.AdminMax = 0
+	if p.LimitFor(true) != 10 {
+		t.Error("Expected admins to use Max when AdminMax is 0")
+	}
+}
+
+func TestLimiterSlidingWindow(t *testing.T) {
+	policy := Policy{Name: "test", Route: "*", Max: 2, Window: Duration(time.Minute)}
+	l := NewLimiter(nil, []Policy{policy})
+
+	first, _ := l.Take(policy, "ip:a", 2)
+	if !first.Allowed || first.Remaining != 1 {
+		t.Errorf("Expected first request allowed with 1 remaining, got %+v", first)
+	}
+	l.Take(policy, "ip:a", 2)
+
+	rejected, _ := l.Take(policy, "ip:a", 2)
+	if rejected.Allowed {
+		t.Fatal("Expected third request to be rejected")
+	}
+	// Both hits are in the current window, which has to end and slide halfway out
+	if rejected.RetryAfter <= 30*time.Second || rejected.RetryAfter > 90*time.Second {
+		t.Errorf("Expected retry after the window slides (30s-1.5m), got %v", rejected.RetryAfter)
+	}
+
+	// Other keys have their own counters
+	if other, _ := l.Take(policy, "ip:b", 2); !other.Allowed {
+		t.Error("Expected other client to be allowed")
+	}
+
+	// Halfway into the next window half of the previous hits still count
+	now := time.Now()
+	w := window{Start: now.Add(-30 * time.Second).UnixMilli(), Prev: 2}
+	if rate := w.rate(now, time.Minute); rate < 0.9 || rate > 1.1 {
+		t.Errorf("Expected weighted rate of ~1, got %v", rate)
+	}
+}
+
+func TestLimiterSetPolicies(t *testing.T) {
+	l := NewLimiter(nil, DefaultPolicies())
+	if len(l.Match("POST", "/api/auth/login")) != 2 {
+		t.Error("Expected global and login policies to match")
+	}
+
+	if err := l.ApplySetting(`[{"name":"a","route":"*","max":1,"window":"1m"}]`); err != nil {
+		t.Fatalf("ApplySetting failed: %v", err)
+	}
+	if len(l.Match("POST", "/api/auth/login")) != 1 {
+		t.Error("Expected new policies to apply")
+	}
+
+	if err := l.ApplySetting(`invalid`); err == nil {
+		t.Error("Expected invalid policies to be rejected")
+	}
+	if len(l.Policies()) != 1 {
+		t.Error("Expected previous policies to be kept")
+	}
+}
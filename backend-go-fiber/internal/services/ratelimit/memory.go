@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // zero = never expires
+}
+
+// MemoryStorage implements Storage in process memory.
+// Counters are not shared between prefork processes or instances.
+type MemoryStorage struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	lastGC  time.Time
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the value for key, or nil if it doesn't exist or has expired
+func (s *MemoryStorage) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return entry.value, nil
+}
+
+// Set stores value for key. exp = 0 means the entry never expires.
+func (s *MemoryStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	entry := memoryEntry{value: val}
+	if exp > 0 {
+		entry.expiresAt = time.Now().Add(exp)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gc()
+	s.entries[key] = entry
+	return nil
+}
+
+// Increment adds delta to the counter at key and returns the new value
+func (s *MemoryStorage) Increment(key string, delta int, ttl time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.entries[key]
+	if !ok || (!entry.expiresAt.IsZero() && !now.Before(entry.expiresAt)) {
+		s.gc()
+		entry = memoryEntry{value: []byte("0")}
+		if ttl > 0 {
+			entry.expiresAt = now.Add(ttl)
+		}
+	}
+
+	count, _ := strconv.Atoi(string(entry.value))
+	count += delta
+	entry.value = []byte(strconv.Itoa(count))
+	s.entries[key] = entry
+	return count, nil
+}
+
+// Count returns the counter at key, or 0 if it doesn't exist or has expired
+func (s *MemoryStorage) Count(key string) (int, error) {
+	val, err := s.Get(key)
+	if err != nil || val == nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(val))
+}
+
+// Delete removes the entry for key
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// Reset removes all entries
+func (s *MemoryStorage) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]memoryEntry)
+	return nil
+}
+
+// gc drops expired entries now and then, so keys of one-off clients don't
+// pile up. Must be called with s.mu held.
+func (s *MemoryStorage) gc() {
+	now := time.Now()
+	if now.Sub(s.lastGC) <= time.Minute {
+		return
+	}
+	s.lastGC = now
+	for k, e := range s.entries {
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+// Close is a no-op
+func (s *MemoryStorage) Close() error {
+	return nil
+}
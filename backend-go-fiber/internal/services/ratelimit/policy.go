@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend-go-fiber/internal/models"
+)
+
+// PoliciesSettingKey is the AppSettings key holding the policy table (JSON array of Policy)
+const PoliciesSettingKey = "rate_limit_policies"
+
+// KeyBy selects what a policy counts requests against
+type KeyBy string
+
+const (
+	KeyByIP     KeyBy = "ip"      // client IP (honors TRUSTED_PROXIES)
+	KeyByUser   KeyBy = "user"    // authenticated user id, falls back to IP
+	KeyByAPIKey KeyBy = "api_key" // verified API key, falls back to IP
+)
+
+// Unlimited disables a limit for a tier (e.g. "adminMax": -1)
+const Unlimited = -1
+
+// Duration is a time.Duration that reads and writes as a Go duration string ("1m", "1h30m")
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"1m\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Policy maps a route pattern to a limit
+type Policy struct {
+	// Name identifies the policy; counters are kept per policy
+	Name string `json:"name"`
+
+	// Method restricts the policy to one HTTP method (empty = any)
+	Method string `json:"method,omitempty"`
+
+	// Route is an exact path ("/api/auth/login") or a prefix ending in "*" ("/api/*", "*").
+	// Paths are unversioned and case-insensitive.
+	Route string `json:"route"`
+
+	// Max requests per Window for regular clients
+	Max int `json:"max"`
+
+	// AdminMax applies to admins instead of Max (0 = same as Max, -1 = unlimited)
+	AdminMax int `json:"adminMax,omitempty"`
+
+	Window Duration `json:"window"`
+
+	// KeyBy is "ip" (default), "user" or "api_key"
+	KeyBy KeyBy `json:"keyBy,omitempty"`
+
+	// Message is returned when the limit is exceeded
+	Message string `json:"message,omitempty"`
+}
+
+// Matches reports whether the policy applies to the request
+func (p Policy) Matches(method, path string) bool {
+	if p.Method != "" && !strings.EqualFold(p.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(p.Route, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == p.Route || path == p.Route+"/"
+}
+
+// LimitFor returns the limit for a client tier
+func (p Policy) LimitFor(isAdmin bool) int {
+	if isAdmin && p.AdminMax != 0 {
+		return p.AdminMax
+	}
+	return p.Max
+}
+
+func (p Policy) validate() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("name is required")
+	case p.Route == "":
+		return fmt.Errorf("policy %q: route is required", p.Name)
+	case p.Max < 1:
+		return fmt.Errorf("policy %q: max must be at least 1", p.Name)
+	case p.AdminMax < Unlimited:
+		return fmt.Errorf("policy %q: adminMax must be -1 (unlimited), 0 (same as max) or positive", p.Name)
+	case time.Duration(p.Window) < time.Second:
+		return fmt.Errorf("policy %q: window must be at least 1s", p.Name)
+	}
+
+	switch p.KeyBy {
+	case "", KeyByIP, KeyByUser, KeyByAPIKey:
+	default:
+		return fmt.Errorf("policy %q: keyBy must be ip, user or api_key", p.Name)
+	}
+
+	return nil
+}
+
+// RejectKeyBy returns an error if a policy counts requests against by, for
+// client identities the server can't resolve
+func RejectKeyBy(policies []Policy, by KeyBy) error {
+	for _, p := range policies {
+		if p.KeyBy == by {
+			return fmt.Errorf("policy %q: keyBy %s is not supported by this server", p.Name, by)
+		}
+	}
+	return nil
+}
+
+// ParsePolicies parses and validates the policy table stored in settings
+func ParsePolicies(value string) ([]Policy, error) {
+	var policies []Policy
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policies: %w", err)
+	}
+
+	names := make(map[string]bool, len(policies))
+	for i, p := range policies {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicate policy name %q", p.Name)
+		}
+		names[p.Name] = true
+		// Matched against apiversion.CanonicalPath, which is lower case
+		policies[i].Route = strings.ToLower(p.Route)
+	}
+
+	return policies, nil
+}
+
+// DefaultPolicies returns the limits used before policies were configurable
+func DefaultPolicies() []Policy {
+	return []Policy{
+		{Name: "global", Route: "*", Max: 100, AdminMax: 1000, Window: Duration(time.Minute), KeyBy: KeyByIP,
+			Message: "Too many requests, please try again later"},
+		{Name: "login", Method: "POST", Route: "/api/auth/login", Max: 5, Window: Duration(5 * time.Minute), KeyBy: KeyByIP,
+			Message: "Too many login attempts. Try again in 5 minutes."},
+		{Name: "register", Method: "POST", Route: "/api/auth/register", Max: 3, Window: Duration(time.Hour), KeyBy: KeyByIP,
+			Message: "Too many registration attempts. Try again later."},
+	}
+}
+
+// DefaultPoliciesJSON returns DefaultPolicies encoded for the settings table
+func DefaultPoliciesJSON() string {
+	b, _ := json.Marshal(DefaultPolicies())
+	return string(b)
+}
+
+// PoliciesSetting returns the default AppSettings row for the policy table
+func PoliciesSetting() models.AppSettings {
+	return models.AppSettings{
+		Key:          PoliciesSettingKey,
+		Value:        DefaultPoliciesJSON(),
+		Type:         models.SettingTypeJSON,
+		Label:        "Rate Limit Policies",
+		SettingGroup: "security",
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces limiter keys so the Redis database can be shared
+const keyPrefix = "ratelimit:"
+
+// RedisStorage implements Storage on any Redis-protocol server
+// (Redis, Valkey, KeyDB, Dragonfly). Expiration uses native key TTLs.
+type RedisStorage struct {
+	client *redis.Client
+}
+
+// NewRedisStorage connects to the server at url (redis://[user:pass@]host:port/db)
+func NewRedisStorage(url string) (*RedisStorage, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisStorage{client: client}, nil
+}
+
+// Get returns the value for key, or nil if it doesn't exist
+func (s *RedisStorage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	val, err := s.client.Get(context.Background(), keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return val, err
+}
+
+// Set stores value for key. exp = 0 means the entry never expires.
+func (s *RedisStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+	return s.client.Set(context.Background(), keyPrefix+key, val, exp).Err()
+}
+
+// incrementScript adds to a counter and sets its TTL when the counter is new,
+// in one atomic step
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) == -1 and tonumber(ARGV[2]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return count
+`)
+
+// Increment adds delta to the counter at key and returns the new value
+func (s *RedisStorage) Increment(key string, delta int, ttl time.Duration) (int, error) {
+	return incrementScript.Run(context.Background(), s.client,
+		[]string{keyPrefix + key}, delta, ttl.Milliseconds()).Int()
+}
+
+// Count returns the counter at key, or 0 if it doesn't exist
+func (s *RedisStorage) Count(key string) (int, error) {
+	val, err := s.Get(key)
+	if err != nil || val == nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(val))
+}
+
+// Delete removes the entry for key
+func (s *RedisStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.client.Del(context.Background(), keyPrefix+key).Err()
+}
+
+// Reset removes all limiter entries (other keys in the database are kept)
+func (s *RedisStorage) Reset() error {
+	ctx := context.Background()
+	iter := s.client.Scan(ctx, 0, keyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Close closes the connection pool
+func (s *RedisStorage) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"backend-go-fiber/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SQLStorage implements Storage on top of the application database.
+// Entries live in the rate_limit_entries table; expired rows are ignored on
+// read and deleted periodically.
+type SQLStorage struct {
+	db   *gorm.DB
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewSQLStorage creates the storage table if needed and starts the expired entry cleanup.
+// gcInterval <= 0 disables the cleanup (expired entries are still ignored on read).
+func NewSQLStorage(db *gorm.DB, gcInterval time.Duration) (*SQLStorage, error) {
+	if err := db.AutoMigrate(&models.RateLimitEntry{}); err != nil {
+		return nil, err
+	}
+
+	s := &SQLStorage{
+		db:   db,
+		done: make(chan struct{}),
+	}
+
+	if gcInterval > 0 {
+		s.wg.Add(1)
+		go s.gc(gcInterval)
+	}
+
+	return s, nil
+}
+
+// Get returns the value for key, or nil if it doesn't exist or has expired
+func (s *SQLStorage) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	var entry models.RateLimitEntry
+	err := s.db.Where("key = ? AND (expires_at IS NULL OR expires_at > ?)", key, time.Now()).
+		First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry.Value, nil
+}
+
+// Set stores value for key. exp = 0 means the entry never expires.
+func (s *SQLStorage) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	entry := models.RateLimitEntry{
+		Key:   key,
+		Value: val,
+	}
+	if exp > 0 {
+		expiresAt := time.Now().Add(exp)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at"}),
+	}).Create(&entry).Error
+}
+
+// incrementSQL upserts a counter in one statement, so concurrent increments
+// from any process are serialized by the row lock. Expired counters restart.
+// Supported by PostgreSQL and SQLite 3.35+.
+const incrementSQL = `INSERT INTO rate_limit_entries (key, value, count, expires_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET
+	count = CASE WHEN rate_limit_entries.expires_at <= ? THEN excluded.count ELSE rate_limit_entries.count + excluded.count END,
+	expires_at = CASE WHEN rate_limit_entries.expires_at <= ? THEN excluded.expires_at ELSE rate_limit_entries.expires_at END
+RETURNING count`
+
+// Increment adds delta to the counter at key and returns the new value
+func (s *SQLStorage) Increment(key string, delta int, ttl time.Duration) (int, error) {
+	now := time.Now()
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := now.Add(ttl)
+		expiresAt = &t
+	}
+
+	var count int
+	err := s.db.Raw(incrementSQL, key, []byte{}, delta, expiresAt, now, now).Scan(&count).Error
+	return count, err
+}
+
+// Count returns the counter at key, or 0 if it doesn't exist or has expired
+func (s *SQLStorage) Count(key string) (int, error) {
+	var entry models.RateLimitEntry
+	err := s.db.Where("key = ? AND (expires_at IS NULL OR expires_at > ?)", key, time.Now()).
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return entry.Count, err
+}
+
+// Delete removes the entry for key
+func (s *SQLStorage) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.db.Where("key = ?", key).Delete(&models.RateLimitEntry{}).Error
+}
+
+// Reset removes all entries
+func (s *SQLStorage) Reset() error {
+	return s.db.Where("1 = 1").Delete(&models.RateLimitEntry{}).Error
+}
+
+// Close stops the cleanup goroutine and waits for a running cleanup.
+// The database connection is owned by the caller.
+func (s *SQLStorage) Close() error {
+	s.once.Do(func() { close(s.done) })
+	s.wg.Wait()
+	return nil
+}
+
+func (s *SQLStorage) gc(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.db.Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now()).
+				Delete(&models.RateLimitEntry{})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Storage backends for rate limiter state
+const (
+	BackendMemory = "memory"
+	BackendSQL    = "sql"
+	BackendRedis  = "redis"
+)
+
+// Storage holds the limiter counters. Besides the fiber.Storage key/value
+// operations it has atomic counters, so concurrent requests on any number of
+// processes sharing the storage can't count past a limit.
+type Storage interface {
+	fiber.Storage
+
+	// Increment atomically adds delta to the counter at key and returns the
+	// new value. A missing or expired counter starts at 0 and expires after ttl.
+	Increment(key string, delta int, ttl time.Duration) (int, error)
+
+	// Count returns the counter at key, or 0 if it doesn't exist or has expired
+	Count(key string) (int, error)
+}
+
+// Config holds rate limiter storage configuration
+type Config struct {
+	// Backend is the storage backend: "memory" (default), "sql" or "redis"
+	Backend string
+
+	// RedisURL is used by the redis backend (e.g., "redis://localhost:6379/0")
+	RedisURL string
+
+	// GCInterval is how often the sql backend deletes expired entries
+	GCInterval time.Duration
+}
+
+// NewStorage creates the storage used by the rate limiters.
+// The memory backend is only correct for a single process without prefork.
+func NewStorage(cfg Config, db *gorm.DB) (Storage, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemoryStorage(), nil
+	case BackendSQL:
+		return NewSQLStorage(db, cfg.GCInterval)
+	case BackendRedis:
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required for redis rate limit storage")
+		}
+		return NewRedisStorage(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown rate limit storage: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,245 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupSQLStorage creates SQL storage on an in-memory SQLite database
+func setupSQLStorage(t *testing.T) *SQLStorage {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Every connection to :memory: is a separate database
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	s, err := NewSQLStorage(db, 0)
+	if err != nil {
+		t.Fatalf("Failed to create SQL storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// setupRedisStorage creates Redis storage backed by an embedded miniredis server
+func setupRedisStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+
+	s, err := NewRedisStorage("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("Failed to create Redis storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s, server
+}
+
+func testStorage(t *testing.T, s Storage, expire func(time.Duration)) {
+	// Missing key
+	val, err := s.Get("missing")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if val != nil {
+		t.Errorf("Expected nil for missing key, got %q", val)
+	}
+
+	// Set and overwrite
+	if err := s.Set("key", []byte("one"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := s.Set("key", []byte("two"), 0); err != nil {
+		t.Fatalf("Set (overwrite) failed: %v", err)
+	}
+	val, _ = s.Get("key")
+	if string(val) != "two" {
+		t.Errorf("Expected 'two', got %q", val)
+	}
+
+	// Expiration
+	s.Set("expiring", []byte("value"), time.Second)
+	expire(2 * time.Second)
+	val, _ = s.Get("expiring")
+	if val != nil {
+		t.Errorf("Expected expired key to be gone, got %q", val)
+	}
+
+	// Delete
+	if err := s.Delete("key"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	val, _ = s.Get("key")
+	if val != nil {
+		t.Errorf("Expected deleted key to be gone, got %q", val)
+	}
+
+	// Reset
+	s.Set("a", []byte("1"), 0)
+	s.Set("b", []byte("2"), 0)
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if val, _ := s.Get("a"); val != nil {
+		t.Error("Expected Reset to remove all keys")
+	}
+
+	// Counters
+	if n, err := s.Count("counter"); err != nil || n != 0 {
+		t.Errorf("Expected missing counter to be 0, got %d (%v)", n, err)
+	}
+	for want := 1; want <= 3; want++ {
+		n, err := s.Increment("counter", 1, time.Second)
+		if err != nil {
+			t.Fatalf("Increment failed: %v", err)
+		}
+		if n != want {
+			t.Errorf("Expected counter %d, got %d", want, n)
+		}
+	}
+	if n, _ := s.Increment("counter", -1, time.Second); n != 2 {
+		t.Errorf("Expected decrement to 2, got %d", n)
+	}
+	if n, _ := s.Count("counter"); n != 2 {
+		t.Errorf("Expected Count 2, got %d", n)
+	}
+	expire(2 * time.Second)
+	if n, _ := s.Count("counter"); n != 0 {
+		t.Errorf("Expected expired counter to be 0, got %d", n)
+	}
+	if n, _ := s.Increment("counter", 1, time.Second); n != 1 {
+		t.Errorf("Expected expired counter to restart at 1, got %d", n)
+	}
+}
+
+func TestSQLStorage(t *testing.T) {
+	s := setupSQLStorage(t)
+	testStorage(t, s, func(d time.Duration) {
+		// Move existing entries into the past instead of sleeping
+		s.db.Exec("UPDATE rate_limit_entries SET expires_at = ? WHERE expires_at IS NOT NULL", time.Now().Add(-d))
+	})
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage()
+	testStorage(t, s, func(d time.Duration) {
+		for k, e := range s.entries {
+			if !e.expiresAt.IsZero() {
+				e.expiresAt = e.expiresAt.Add(-d)
+				s.entries[k] = e
+			}
+		}
+	})
+}
+
+func TestRedisStorage(t *testing.T) {
+	s, server := setupRedisStorage(t)
+	testStorage(t, s, server.FastForward)
+}
+
+func TestRedisStorageInvalidURL(t *testing.T) {
+	if _, err := NewRedisStorage("not-a-url"); err == nil {
+		t.Error("Expected error for invalid URL")
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	s, err := NewStorage(Config{Backend: BackendMemory}, nil)
+	if err != nil {
+		t.Fatalf("Memory backend failed: %v", err)
+	}
+	if _, ok := s.(*MemoryStorage); !ok {
+		t.Errorf("Expected MemoryStorage, got %T", s)
+	}
+
+	if _, err := NewStorage(Config{Backend: BackendRedis}, nil); err == nil {
+		t.Error("Expected error when REDIS_URL is missing")
+	}
+
+	if _, err := NewStorage(Config{Backend: "memcached"}, nil); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+}
+
+// TestSharedLimit simulates two instances behind a load balancer:
+// both must count requests against the same limit.
+func TestSharedLimit(t *testing.T) {
+	s, _ := setupRedisStorage(t)
+
+	policy := Policy{Name: "global", Route: "*", Max: 3, Window: Duration(time.Minute)}
+	instances := []*Limiter{
+		NewLimiter(s, []Policy{policy}),
+		NewLimiter(s, []Policy{policy}),
+	}
+
+	for i := 0; i < 3; i++ {
+		result, err := instances[i%2].Take(policy, "ip:1.2.3.4", policy.Max)
+		if err != nil {
+			t.Fatalf("Take failed: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("Request %d: expected to be allowed", i+1)
+		}
+	}
+
+	result, _ := instances[1].Take(policy, "ip:1.2.3.4", policy.Max)
+	if result.Allowed {
+		t.Error("Expected rejection after limit is reached on another instance")
+	}
+}
+
+// TestConcurrentTake checks that concurrent requests can't count past the limit
+func TestConcurrentTake(t *testing.T) {
+	redisStorage, _ := setupRedisStorage(t)
+	backends := map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"sql":    setupSQLStorage(t),
+		"redis":  redisStorage,
+	}
+
+	policy := Policy{Name: "global", Route: "*", Max: 10, Window: Duration(time.Minute)}
+	for name, s := range backends {
+		t.Run(name, func(t *testing.T) {
+			instances := []*Limiter{
+				NewLimiter(s, []Policy{policy}),
+				NewLimiter(s, []Policy{policy}),
+			}
+
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			allowed := 0
+			for i := 0; i < 30; i++ {
+				wg.Add(1)
+				go func(l *Limiter) {
+					defer wg.Done()
+					result, err := l.Take(policy, "ip:1.2.3.4", policy.Max)
+					if err != nil {
+						t.Errorf("Take failed: %v", err)
+						return
+					}
+					if result.Allowed {
+						mu.Lock()
+						allowed++
+						mu.Unlock()
+					}
+				}(instances[i%2])
+			}
+			wg.Wait()
+
+			if allowed > policy.Max {
+				t.Errorf("Expected at most %d requests allowed, got %d", policy.Max, allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"backend-go-fiber/internal/models"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// SettingsCache keeps AppSettings in memory for code that reads them on every
+// request (middleware). Subscribers are notified when a value changes, so
+// settings take effect without a restart.
+//
+// Changes made through the admin API are applied immediately via Reload;
+// Watch polls the table so other processes and instances pick them up too.
+type SettingsCache struct {
+	db *gorm.DB
+
+	mu          sync.RWMutex
+	values      map[string]string
+	subscribers map[string][]func(value string)
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewSettingsCache creates a settings cache and loads the current values
+func NewSettingsCache(db *gorm.DB) (*SettingsCache, error) {
+	s := &SettingsCache{
+		db:          db,
+		values:      make(map[string]string),
+		subscribers: make(map[string][]func(string)),
+		done:        make(chan struct{}),
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload reads all settings and notifies subscribers of changed values
+func (s *SettingsCache) Reload() error {
+	var settings []models.AppSettings
+	if err := s.db.Select("key", "value").Find(&settings).Error; err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+
+	s.mu.Lock()
+	var notify []func()
+	for key, fns := range s.subscribers {
+		value, ok := values[key]
+		if old, had := s.values[key]; !ok || (had && old == value) {
+			continue
+		}
+		for _, fn := range fns {
+			fn := fn
+			notify = append(notify, func() { fn(value) })
+		}
+	}
+	s.values = values
+	s.mu.Unlock()
+
+	// Called outside the lock so subscribers can read other settings
+	for _, fn := range notify {
+		fn()
+	}
+
+	return nil
+}
+
+// Get returns the cached value of a setting
+func (s *SettingsCache) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// GetBool returns a boolean setting, or def if it is missing or invalid
+func (s *SettingsCache) GetBool(key string, def bool) bool {
+	value, ok := s.Get(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Subscribe calls fn with the current value (if the setting exists) and again
+// every time the value changes
+func (s *SettingsCache) Subscribe(key string, fn func(value string)) {
+	s.mu.Lock()
+	s.subscribers[key] = append(s.subscribers[key], fn)
+	value, ok := s.values[key]
+	s.mu.Unlock()
+
+	if ok {
+		fn(value)
+	}
+}
+
+// Watch reloads settings every interval until Close is called
+func (s *SettingsCache) Watch(interval time.Duration) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				if err := s.Reload(); err != nil {
+					log.Error().Err(err).Msg("Failed to reload settings")
+				}
+			}
+		}
+	}()
+}
+
+// Close stops Watch and waits for a running reload
+func (s *SettingsCache) Close() {
+	s.once.Do(func() { close(s.done) })
+	s.wg.Wait()
+}
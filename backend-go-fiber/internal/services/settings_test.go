@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"backend-go-fiber/internal/models"
+)
+
+func TestSettingsCache(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&models.AppSettings{}); err != nil {
+		t.Fatalf("Failed to migrate settings: %v", err)
+	}
+	db.Create(&models.AppSettings{Key: "maintenance_mode", Value: "false"})
+
+	cache, err := NewSettingsCache(db)
+	if err != nil {
+		t.Fatalf("NewSettingsCache failed: %v", err)
+	}
+	defer cache.Close()
+
+	if cache.GetBool("maintenance_mode", true) {
+		t.Error("Expected maintenance_mode to be false")
+	}
+	if !cache.GetBool("missing", true) {
+		t.Error("Expected default for missing setting")
+	}
+
+	var received []string
+	cache.Subscribe("maintenance_mode", func(value string) {
+		received = append(received, value)
+	})
+	if len(received) != 1 || received[0] != "false" {
+		t.Fatalf("Expected subscriber to get current value, got %v", received)
+	}
+
+	// Unchanged values don't notify
+	cache.Reload()
+	if len(received) != 1 {
+		t.Errorf("Expected no notification without change, got %v", received)
+	}
+
+	db.Model(&models.AppSettings{}).Where("key = ?", "maintenance_mode").Update("value", "true")
+	if err := cache.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(received) != 2 || received[1] != "true" {
+		t.Errorf("Expected subscriber to get new value, got %v", received)
+	}
+	if !cache.GetBool("maintenance_mode", false) {
+		t.Error("Expected maintenance_mode to be true after reload")
+	}
+}
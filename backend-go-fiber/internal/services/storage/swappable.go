@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
+
+// SwappableStorage forwards to a Storage that can be replaced at runtime
+// (new S3 credentials on config reload). Calls in progress finish on the
+// storage they started with.
+type SwappableStorage struct {
+	current atomic.Pointer[storageBox]
+}
+
+// storageBox lets atomic.Pointer hold an interface value
+type storageBox struct {
+	storage Storage
+}
+
+// NewSwappableStorage forwards to storage until Swap is called
+func NewSwappableStorage(storage Storage) *SwappableStorage {
+	s := &SwappableStorage{}
+	s.Swap(storage)
+	return s
+}
+
+// Swap makes storage serve the following calls
+func (s *SwappableStorage) Swap(storage Storage) {
+	s.current.Store(&storageBox{storage: storage})
+}
+
+func (s *SwappableStorage) get() Storage {
+	return s.current.Load().storage
+}
+
+// Upload stores a file and returns its info
+func (s *SwappableStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*FileInfo, error) {
+	return s.get().Upload(ctx, key, reader, size, contentType)
+}
+
+// Download retrieves a file by its key
+func (s *SwappableStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.get().Download(ctx, key)
+}
+
+// Delete removes a file by its key
+func (s *SwappableStorage) Delete(ctx context.Context, key string) error {
+	return s.get().Delete(ctx, key)
+}
+
+// GetURL returns the URL to access the file
+func (s *SwappableStorage) GetURL(ctx context.Context, key string) (string, error) {
+	return s.get().GetURL(ctx, key)
+}
+
+// Exists checks if a file exists
+func (s *SwappableStorage) Exists(ctx context.Context, key string) (bool, error) {
+	return s.get().Exists(ctx, key)
+}
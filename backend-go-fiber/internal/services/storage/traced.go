@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("backend-go-fiber/storage")
+
+// TracedStorage wraps a Storage and records a span for every call
+type TracedStorage struct {
+	storage Storage
+	backend string
+}
+
+// NewTracedStorage wraps storage; backend is reported as storage.backend ("local", "s3")
+func NewTracedStorage(storage Storage, backend string) *TracedStorage {
+	return &TracedStorage{storage: storage, backend: backend}
+}
+
+func (s *TracedStorage) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "storage."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("storage.backend", s.backend),
+			attribute.String("storage.key", key),
+		))
+}
+
+// Upload stores a file and returns its info
+func (s *TracedStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) (*FileInfo, error) {
+	ctx, span := s.start(ctx, "Upload", key)
+	defer span.End()
+	span.SetAttributes(attribute.Int64("storage.size", size))
+
+	info, err := s.storage.Upload(ctx, key, reader, size, contentType)
+	return info, endSpan(span, err)
+}
+
+// Download retrieves a file by its key
+func (s *TracedStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "Download", key)
+	defer span.End()
+
+	reader, err := s.storage.Download(ctx, key)
+	return reader, endSpan(span, err)
+}
+
+// Delete removes a file by its key
+func (s *TracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := s.start(ctx, "Delete", key)
+	defer span.End()
+
+	return endSpan(span, s.storage.Delete(ctx, key))
+}
+
+// GetURL returns the URL to access the file
+func (s *TracedStorage) GetURL(ctx context.Context, key string) (string, error) {
+	ctx, span := s.start(ctx, "GetURL", key)
+	defer span.End()
+
+	url, err := s.storage.GetURL(ctx, key)
+	return url, endSpan(span, err)
+}
+
+// Exists checks if a file exists
+func (s *TracedStorage) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, span := s.start(ctx, "Exists", key)
+	defer span.End()
+
+	exists, err := s.storage.Exists(ctx, key)
+	return exists, endSpan(span, err)
+}
+
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
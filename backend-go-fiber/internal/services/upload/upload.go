@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"backend-go-fiber/internal/metrics"
 	"backend-go-fiber/internal/services/storage"
 
 	"github.com/google/uuid"
@@ -137,6 +138,7 @@ func (s *Service) UploadFile(ctx context.Context, fileHeader *multipart.FileHead
 
 	// Preserve original filename
 	info.OriginalName = fileHeader.Filename
+	metrics.UploadBytes.Add(float64(info.Size))
 
 	return info, nil
 }
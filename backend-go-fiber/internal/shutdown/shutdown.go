@@ -0,0 +1,209 @@
+// Package shutdown stops the server in order: readiness is flipped first so
+// load balancers stop sending traffic, in-flight requests drain, background
+// workers finish and only then are shared resources (the database pool) closed.
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Config times the shutdown
+type Config struct {
+	// DrainDelay is how long the server keeps serving after /ready reports
+	// not ready, so that load balancers notice before connections are refused
+	DrainDelay time.Duration
+
+	// Timeout bounds draining requests
+	Timeout time.Duration
+
+	// WorkerTimeout bounds waiting for workers and tasks once requests have
+	// drained (0 = Timeout), so a slow drain doesn't use up their time.
+	// Resources are closed when it expires even if some work has not finished.
+	WorkerTimeout time.Duration
+}
+
+// Server is the HTTP server (*fiber.App)
+type Server interface {
+	ShutdownWithContext(ctx context.Context) error
+}
+
+type step struct {
+	name string
+	fn   func()
+}
+
+// Manager runs the shutdown sequence. Workers and closers are registered at
+// startup; Shutdown runs once.
+type Manager struct {
+	config   Config
+	draining atomic.Bool
+
+	mu      sync.Mutex
+	workers []step
+	closers []step
+
+	// Tasks started with Go, running counts by name
+	ctx     context.Context
+	cancel  context.CancelFunc
+	tasks   sync.WaitGroup
+	running map[string]int
+
+	once sync.Once
+	done chan struct{}
+}
+
+// New creates a manager
+func New(config Config) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]int),
+		done:    make(chan struct{}),
+	}
+}
+
+// Draining reports whether shutdown has started (readiness checks fail)
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Worker registers a background worker. stop must signal it and block until
+// it has finished; workers are stopped concurrently after requests drained.
+func (m *Manager) Worker(name string, stop func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = append(m.workers, step{name, stop})
+}
+
+// Closer registers a resource closed after every worker, in registration order
+func (m *Manager) Closer(name string, close func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, step{name, func() {
+		if err := close(); err != nil {
+			log.Error().Err(err).Str("resource", name).Msg("Failed to close")
+		}
+	}})
+}
+
+// Go runs fn in the background. Its context is cancelled when requests have
+// drained, and the shutdown waits for fn to return.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.mu.Lock()
+	m.running[name]++
+	m.mu.Unlock()
+
+	m.tasks.Add(1)
+	go func() {
+		defer m.tasks.Done()
+		defer func() {
+			m.mu.Lock()
+			if m.running[name]--; m.running[name] == 0 {
+				delete(m.running, name)
+			}
+			m.mu.Unlock()
+		}()
+		fn(m.ctx)
+	}()
+}
+
+// Done is closed when Shutdown has finished
+func (m *Manager) Done() <-chan struct{} {
+	return m.done
+}
+
+// Shutdown flips readiness, waits DrainDelay, stops server within Timeout,
+// waits for workers and tasks within WorkerTimeout, then closes the
+// resources. Only the first call does anything.
+func (m *Manager) Shutdown(server Server) {
+	m.once.Do(func() {
+		defer close(m.done)
+		m.draining.Store(true)
+
+		if m.config.DrainDelay > 0 {
+			log.Info().Dur("drain_delay", m.config.DrainDelay).Msg("Shutting down: reporting not ready")
+			time.Sleep(m.config.DrainDelay)
+		}
+
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), m.config.Timeout)
+		defer cancelDrain()
+
+		log.Info().Msg("Shutting down: draining requests")
+		if err := server.ShutdownWithContext(drainCtx); err != nil {
+			log.Warn().Err(err).Msg("Requests did not finish before the shutdown timeout")
+		}
+
+		workerTimeout := m.config.WorkerTimeout
+		if workerTimeout == 0 {
+			workerTimeout = m.config.Timeout
+		}
+		workerCtx, cancelWorkers := context.WithTimeout(context.Background(), workerTimeout)
+		defer cancelWorkers()
+
+		log.Info().Msg("Shutting down: waiting for background work")
+		m.cancel()
+		m.stopWorkers(workerCtx)
+
+		m.mu.Lock()
+		closers := m.closers
+		m.mu.Unlock()
+		for _, c := range closers {
+			c.fn()
+		}
+		log.Info().Msg("Shutdown complete")
+	})
+}
+
+// stopWorkers stops the workers concurrently and waits for them and the Go
+// tasks until ctx expires, logging the ones that did not finish
+func (m *Manager) stopWorkers(ctx context.Context) {
+	m.mu.Lock()
+	workers := m.workers
+	m.mu.Unlock()
+
+	var pending sync.Map
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		pending.Store(w.name, true)
+		wg.Add(1)
+		go func(w step) {
+			defer wg.Done()
+			w.fn()
+			pending.Delete(w.name)
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.tasks.Wait()
+	}()
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		var names []string
+		pending.Range(func(name, _ any) bool {
+			names = append(names, name.(string))
+			return true
+		})
+		m.mu.Lock()
+		for name := range m.running {
+			names = append(names, name)
+		}
+		m.mu.Unlock()
+		log.Warn().Strs("pending", names).Msg("Background work did not finish before the shutdown timeout")
+	}
+}
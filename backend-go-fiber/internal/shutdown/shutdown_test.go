@@ -0,0 +1,134 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the shutdown steps in order
+type recorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *recorder) add(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.steps...)
+}
+
+type fakeServer struct {
+	m   *Manager
+	rec *recorder
+}
+
+func (s fakeServer) ShutdownWithContext(ctx context.Context) error {
+	if !s.m.Draining() {
+		s.rec.add("server stopped while ready")
+	}
+	s.rec.add("server")
+	return nil
+}
+
+func TestShutdownOrder(t *testing.T) {
+	rec := &recorder{}
+	m := New(Config{DrainDelay: 20 * time.Millisecond, Timeout: time.Second})
+
+	m.Go("mailer", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		rec.add("task")
+	})
+	m.Worker("cleanup", func() { rec.add("worker") })
+	m.Closer("database", func() error { rec.add("database"); return nil })
+	m.Closer("tracing", func() error { rec.add("tracing"); return nil })
+
+	if m.Draining() {
+		t.Fatal("Draining() before shutdown")
+	}
+
+	start := time.Now()
+	m.Shutdown(fakeServer{m, rec})
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Shutdown() did not wait for the drain delay")
+	}
+
+	steps := rec.get()
+	if len(steps) != 5 || steps[0] != "server" || steps[3] != "database" || steps[4] != "tracing" {
+		t.Fatalf("steps = %v", steps)
+	}
+	if !(steps[1] == "worker" && steps[2] == "task") && !(steps[1] == "task" && steps[2] == "worker") {
+		t.Errorf("workers and tasks must stop between the server and the resources: %v", steps)
+	}
+
+	select {
+	case <-m.Done():
+	default:
+		t.Error("Done() not closed after Shutdown()")
+	}
+
+	// Later calls do nothing
+	m.Shutdown(fakeServer{m, rec})
+	if len(rec.get()) != 5 {
+		t.Error("Shutdown() ran twice")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	rec := &recorder{}
+	m := New(Config{Timeout: 20 * time.Millisecond})
+
+	release := make(chan struct{})
+	defer close(release)
+	m.Worker("stuck", func() { <-release })
+	m.Go("stuck task", func(ctx context.Context) { <-release })
+	m.Closer("database", func() error { rec.add("database"); return nil })
+
+	finished := make(chan struct{})
+	go func() {
+		m.Shutdown(fakeServer{m, rec})
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown() did not give up on stuck workers")
+	}
+	if steps := rec.get(); len(steps) != 2 || steps[1] != "database" {
+		t.Errorf("resources must be closed after the timeout: %v", steps)
+	}
+}
+
+// slowServer takes the whole drain timeout to stop
+type slowServer struct{}
+
+func (slowServer) ShutdownWithContext(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestShutdownWorkerTimeout(t *testing.T) {
+	rec := &recorder{}
+	m := New(Config{Timeout: 20 * time.Millisecond, WorkerTimeout: time.Second})
+
+	// A slow drain must not use up the time of the workers
+	m.Worker("mailer", func() {
+		time.Sleep(50 * time.Millisecond)
+		rec.add("worker")
+	})
+	m.Closer("database", func() error { rec.add("database"); return nil })
+
+	m.Shutdown(slowServer{})
+	if steps := rec.get(); len(steps) != 2 || steps[0] != "worker" {
+		t.Errorf("worker must finish within its own timeout: %v", steps)
+	}
+}
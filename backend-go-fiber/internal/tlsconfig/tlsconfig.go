@@ -0,0 +1,262 @@
+// Package tlsconfig serves TLS from certificate and key files that are reloaded
+// when they change on disk, and optionally verifies client certificates (mTLS).
+// middleware.ClientCertMiddleware requires them on the configured routes.
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Files are the PEM files of the server
+type Files struct {
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile holds the CAs client certificates are verified against
+	// (empty = client certificates are not requested)
+	ClientCAFile string
+}
+
+// ClientAuth decides where client certificates are required and who they identify
+type ClientAuth struct {
+	// Routes are exact paths ("/api/admin/config/reload") or prefixes ending
+	// in "*" ("/api/admin/*"). Paths are unversioned and case-insensitive,
+	// like ip_access_rules.
+	Routes []string
+
+	// Identities maps a certificate subject ("CN=billing,O=Acme") or common
+	// name ("billing") to the identity recorded in the audit log.
+	// Empty = every verified certificate is accepted with its common name.
+	Identities map[string]string
+}
+
+// Requires reports whether the canonical (lower case) path needs a client certificate
+func (a ClientAuth) Requires(path string) bool {
+	for _, route := range a.Routes {
+		route = strings.ToLower(route)
+		if prefix, ok := strings.CutSuffix(route, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == route || path == route+"/" {
+			return true
+		}
+	}
+	return false
+}
+
+// Identity maps a verified client certificate to an identity
+func (a ClientAuth) Identity(cert *x509.Certificate) (string, bool) {
+	if len(a.Identities) == 0 {
+		return cert.Subject.CommonName, cert.Subject.CommonName != ""
+	}
+	if identity, ok := a.Identities[cert.Subject.String()]; ok {
+		return identity, true
+	}
+	identity, ok := a.Identities[cert.Subject.CommonName]
+	return identity, ok && cert.Subject.CommonName != ""
+}
+
+// Certificates are the parsed content of Files
+type Certificates struct {
+	files     Files
+	raw       [][]byte
+	cert      tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// Load reads and parses files
+func Load(files Files) (*Certificates, error) {
+	raw, err := readAll(files)
+	if err != nil {
+		return nil, err
+	}
+	return parse(files, raw)
+}
+
+func readAll(files Files) ([][]byte, error) {
+	raw := make([][]byte, 3)
+	for i, path := range []string{files.CertFile, files.KeyFile, files.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = data
+	}
+	return raw, nil
+}
+
+func parse(files Files, raw [][]byte) (*Certificates, error) {
+	cert, err := tls.X509KeyPair(raw[0], raw[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s: %w", files.CertFile, files.KeyFile, err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("%s: %w", files.CertFile, err)
+		}
+	}
+
+	c := &Certificates{files: files, raw: raw, cert: cert}
+	if files.ClientCAFile != "" {
+		c.clientCAs = x509.NewCertPool()
+		if !c.clientCAs.AppendCertsFromPEM(raw[2]) {
+			return nil, fmt.Errorf("%s: no PEM certificates found", files.ClientCAFile)
+		}
+	}
+	return c, nil
+}
+
+// NotAfter is when the server certificate expires
+func (c *Certificates) NotAfter() time.Time {
+	return c.cert.Leaf.NotAfter
+}
+
+// Store holds the live certificates and client auth rules. Handshakes use the
+// certificates current at that moment; open connections keep theirs.
+type Store struct {
+	certs atomic.Pointer[Certificates]
+	auth  atomic.Pointer[ClientAuth]
+
+	mu   sync.Mutex
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// NewStore starts serving certs
+func NewStore(certs *Certificates, auth ClientAuth) *Store {
+	s := &Store{done: make(chan struct{})}
+	s.certs.Store(certs)
+	s.auth.Store(&auth)
+	return s
+}
+
+// Swap replaces the certificates and client auth rules (configuration reload)
+func (s *Store) Swap(certs *Certificates, auth ClientAuth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certs.Store(certs)
+	s.auth.Store(&auth)
+}
+
+// ClientAuth returns the live client auth rules
+func (s *Store) ClientAuth() ClientAuth {
+	return *s.auth.Load()
+}
+
+// Certificates returns the live certificates
+func (s *Store) Certificates() *Certificates {
+	return s.certs.Load()
+}
+
+// Reload re-reads the files and swaps the certificates if their content
+// changed. Broken files (a half-written renewal) keep the previous certificates.
+func (s *Store) Reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.certs.Load()
+	raw, err := readAll(current.files)
+	if err != nil {
+		return false, err
+	}
+	if equal(raw, current.raw) {
+		return false, nil
+	}
+
+	next, err := parse(current.files, raw)
+	if err != nil {
+		return false, err
+	}
+	s.certs.Store(next)
+	return true, nil
+}
+
+func equal(a, b [][]byte) bool {
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch reloads the files every interval until Close
+func (s *Store) Watch(interval time.Duration) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				changed, err := s.Reload()
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to reload TLS certificates, keeping previous")
+					continue
+				}
+				if changed {
+					log.Info().Time("not_after", s.Certificates().NotAfter()).Msg("TLS certificates reloaded")
+				}
+			}
+		}
+	}()
+}
+
+// Close stops Watch and waits for a running reload
+func (s *Store) Close() {
+	s.once.Do(func() { close(s.done) })
+	s.wg.Wait()
+}
+
+// ServerConfig returns the listener configuration. Client certificates are
+// requested and verified when a client CA is configured, but only required
+// by ClientCertMiddleware, so browsers can still reach the other routes.
+func (s *Store) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certs := s.certs.Load()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{certs.cert},
+			}
+			if certs.clientCAs != nil {
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				config.ClientCAs = certs.clientCAs
+			}
+			return config, nil
+		},
+	}
+}
+
+// ErrNoClientCertificate is returned by VerifiedClient without a verified certificate
+var ErrNoClientCertificate = errors.New("no verified client certificate")
+
+// VerifiedClient returns the client certificate of a connection. The chain
+// was verified during the handshake against the client CAs.
+func VerifiedClient(state *tls.ConnectionState) (*x509.Certificate, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, ErrNoClientCertificate
+	}
+	return state.VerifiedChains[0][0], nil
+}
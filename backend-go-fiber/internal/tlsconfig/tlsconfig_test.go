@@ -0,0 +1,195 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue creates a certificate for cn signed by parent (self-signed if nil)
+// and returns it with its key as PEM
+func issue(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Acme"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return cert, key,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func write(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake connects to addr and returns the server certificate's common name
+func handshake(t *testing.T, addr string, roots *x509.CertPool) string {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestStoreReload(t *testing.T) {
+	ca, caKey, caPEM, _ := issue(t, "Test CA", nil, nil)
+	_, _, certPEM, keyPEM := issue(t, "first", ca, caKey)
+
+	dir := t.TempDir()
+	files := Files{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	write(t, files.CertFile, certPEM)
+	write(t, files.KeyFile, keyPEM)
+
+	certs, err := Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(certs, ClientAuth{})
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", store.ServerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	if cn := handshake(t, ln.Addr().String(), roots); cn != "first" {
+		t.Fatalf("served %q, expected first", cn)
+	}
+
+	// Unchanged files are not reloaded
+	if changed, err := store.Reload(); changed || err != nil {
+		t.Errorf("Reload() = %v, %v; expected no change", changed, err)
+	}
+
+	// A half-written renewal keeps the previous certificate
+	_, _, certPEM, keyPEM = issue(t, "second", ca, caKey)
+	write(t, files.CertFile, certPEM)
+	if _, err := store.Reload(); err == nil {
+		t.Error("Reload() accepted a certificate with the wrong key")
+	}
+	if cn := handshake(t, ln.Addr().String(), roots); cn != "first" {
+		t.Errorf("served %q after a failed reload, expected first", cn)
+	}
+
+	// New connections get the renewed certificate
+	write(t, files.KeyFile, keyPEM)
+	if changed, err := store.Reload(); !changed || err != nil {
+		t.Fatalf("Reload() = %v, %v; expected a change", changed, err)
+	}
+	if cn := handshake(t, ln.Addr().String(), roots); cn != "second" {
+		t.Errorf("served %q after the renewal, expected second", cn)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, _, certPEM, keyPEM := issue(t, "server", nil, nil)
+	files := Files{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+	write(t, files.CertFile, certPEM)
+	write(t, files.KeyFile, keyPEM)
+
+	if _, err := Load(files); err == nil {
+		t.Error("Load() accepted a missing client CA file")
+	}
+	write(t, files.ClientCAFile, []byte("not a certificate"))
+	if _, err := Load(files); err == nil {
+		t.Error("Load() accepted a client CA file without certificates")
+	}
+	write(t, files.ClientCAFile, certPEM)
+	if _, err := Load(files); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	auth := ClientAuth{Routes: []string{"/api/Admin/*", "/api/internal"}}
+
+	for path, want := range map[string]bool{
+		"/api/admin/users":  true,
+		"/api/internal":     true,
+		"/api/internal/":    true,
+		"/api/internal/x":   false,
+		"/api/auth/login":   false,
+		"/api/administrate": false,
+	} {
+		if got := auth.Requires(path); got != want {
+			t.Errorf("Requires(%s) = %v, expected %v", path, got, want)
+		}
+	}
+
+	billing, _, _, _ := issue(t, "billing", nil, nil)
+	if id, ok := auth.Identity(billing); !ok || id != "billing" {
+		t.Errorf("Identity() without mapping = %q, %v; expected the common name", id, ok)
+	}
+
+	auth.Identities = map[string]string{
+		"CN=billing,O=Acme": "service:billing",
+		"reports":           "service:reports",
+	}
+	if id, ok := auth.Identity(billing); !ok || id != "service:billing" {
+		t.Errorf("Identity(billing) = %q, %v", id, ok)
+	}
+	reports, _, _, _ := issue(t, "reports", nil, nil)
+	if id, ok := auth.Identity(reports); !ok || id != "service:reports" {
+		t.Errorf("Identity(reports) = %q, %v", id, ok)
+	}
+	unknown, _, _, _ := issue(t, "unknown", nil, nil)
+	if _, ok := auth.Identity(unknown); ok {
+		t.Error("Identity() mapped an unknown subject")
+	}
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin creates a span for every GORM operation.
+// Spans are children of the span in the statement context, so use
+// db.WithContext(ctx) to attach queries to the request trace.
+type GormPlugin struct {
+	// DBSystem is reported as db.system ("sqlite", "postgresql")
+	DBSystem string
+}
+
+// Name implements gorm.Plugin
+func (p GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the span callbacks
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	tracer := otel.Tracer("backend-go-fiber/gorm")
+
+	register := []struct {
+		op     string
+		before func(name string, fn func(*gorm.DB)) error
+		after  func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", db.Callback().Create().Before("gorm:create").Register, db.Callback().Create().After("gorm:create").Register},
+		{"query", db.Callback().Query().Before("gorm:query").Register, db.Callback().Query().After("gorm:query").Register},
+		{"update", db.Callback().Update().Before("gorm:update").Register, db.Callback().Update().After("gorm:update").Register},
+		{"delete", db.Callback().Delete().Before("gorm:delete").Register, db.Callback().Delete().After("gorm:delete").Register},
+		{"row", db.Callback().Row().Before("gorm:row").Register, db.Callback().Row().After("gorm:row").Register},
+		{"raw", db.Callback().Raw().Before("gorm:raw").Register, db.Callback().Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range register {
+		op := r.op
+		if err := r.before("tracing:before_"+op, func(tx *gorm.DB) {
+			ctx, span := tracer.Start(tx.Statement.Context, "gorm."+op, trace.WithSpanKind(trace.SpanKindClient))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(gormSpanKey, span)
+		}); err != nil {
+			return err
+		}
+
+		if err := r.after("tracing:after_"+op, func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(gormSpanKey)
+			if !ok {
+				return
+			}
+			span := value.(trace.Span)
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("db.system", p.DBSystem),
+				attribute.String("db.sql.table", tx.Statement.Table),
+				attribute.String("db.statement", tx.Statement.SQL.String()),
+				attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+			)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
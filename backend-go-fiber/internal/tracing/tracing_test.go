@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupRecorder installs a tracer provider that keeps finished spans in memory
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Expected error for unknown exporter")
+	}
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.Use(GormPlugin{DBSystem: "sqlite"}); err != nil {
+		t.Fatalf("Failed to register plugin: %v", err)
+	}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	var count int64
+	db.WithContext(ctx).Raw("SELECT 1").Scan(&count)
+	parent.End()
+
+	var querySpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "gorm.row" {
+			querySpan = span
+		}
+	}
+	if querySpan == nil {
+		t.Fatal("Expected a gorm.row span")
+	}
+	if querySpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Expected query span to be a child of the request span")
+	}
+
+	found := false
+	for _, attr := range querySpan.Attributes() {
+		if attr.Key == "db.statement" && attr.Value.AsString() == "SELECT 1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected db.statement attribute")
+	}
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := t.TempDir() + "/traces.jsonl"
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, ServiceName: "test", FilePath: path})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "operation")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+	if !strings.Contains(string(data), `"Name":"operation"`) {
+		t.Errorf("Expected span in trace file, got %s", data)
+	}
+}
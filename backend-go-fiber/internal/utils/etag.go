@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag returns the strong entity tag of data as SendSuccess serializes it.
+// The tag identifies the data byte for byte; the response envelope
+// (meta.timestamp, meta.requestId) is not part of the resource.
+func ETag(data interface{}) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// ETagMatches reports whether an If-Match header value ("*" or a list of
+// entity tags) matches etag, using strong comparison (RFC 9110 §13.1.1):
+// weak tags never match, so an update can't rely on a weak validator.
+func ETagMatches(header, etag string) bool {
+	return etagMatches(header, etag, false)
+}
+
+// etagMatches compares header with etag; weak comparison ignores the W/ prefix,
+// as If-None-Match requires (RFC 9110 §13.1.2)
+func etagMatches(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	} else if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// setETag adds the ETag of data to 200 responses and answers a GET whose
+// If-None-Match already matches with 304. Returns true when the 304 was sent.
+func setETag(c *fiber.Ctx, data interface{}, code int) bool {
+	if code != fiber.StatusOK {
+		return false
+	}
+
+	method := c.Method()
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodPut, fiber.MethodPatch:
+	default:
+		return false
+	}
+
+	etag, err := ETag(data)
+	if err != nil {
+		return false
+	}
+	c.Set(fiber.HeaderETag, etag)
+
+	if (method == fiber.MethodGet || method == fiber.MethodHead) &&
+		etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag, true) {
+		c.Status(fiber.StatusNotModified)
+		c.Response().ResetBody()
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{`"abc"`, true},
+		{`"other", "abc"`, true},
+		{`*`, true},
+		{`W/"abc"`, false},
+		{`"other"`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := ETagMatches(tt.header, etag); got != tt.expected {
+			t.Errorf("ETagMatches(%q) = %v, expected %v", tt.header, got, tt.expected)
+		}
+	}
+
+	// Weak comparison for If-None-Match
+	if !etagMatches(`W/"abc"`, etag, true) {
+		t.Error("Expected weak comparison to ignore W/")
+	}
+}
+
+func TestSendSuccessConditionalGet(t *testing.T) {
+	data := fiber.Map{"name": "test"}
+	etag, _ := ETag(data)
+
+	app := fiber.New()
+	app.Get("/item", func(c *fiber.Ctx) error {
+		return SendSuccess(c, data)
+	})
+	app.Post("/item", func(c *fiber.Ctx) error {
+		return SendSuccess(c, data, fiber.StatusCreated)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/item", nil))
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("ETag") != etag {
+		t.Fatalf("Expected 200 with ETag %s, got %d %q", etag, resp.StatusCode, resp.Header.Get("ETag"))
+	}
+
+	req := httptest.NewRequest("GET", "/item", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, _ = app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusNotModified || len(body) != 0 {
+		t.Errorf("Expected empty 304, got %d with %d bytes", resp.StatusCode, len(body))
+	}
+
+	req = httptest.NewRequest("GET", "/item", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("Expected 304 for weak If-None-Match, got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/item", nil)
+	req.Header.Set("If-None-Match", `W/"stale"`)
+	resp, _ = app.Test(req)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected 200 for stale ETag, got %d", resp.StatusCode)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest("POST", "/item", nil))
+	if resp.Header.Get("ETag") != "" {
+		t.Error("Expected no ETag on 201 response")
+	}
+}
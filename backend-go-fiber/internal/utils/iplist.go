@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPList is a set of IP addresses and CIDR ranges
+type IPList []*net.IPNet
+
+// ParseIPList parses a comma-separated list of IPs and CIDR ranges
+// ("10.0.0.1, 192.168.0.0/16, ::1"). An empty string is an empty list.
+func ParseIPList(value string) (IPList, error) {
+	var list IPList
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range: %s", entry)
+		}
+		list = append(list, network)
+	}
+	return list, nil
+}
+
+// Contains reports whether ip is in the list
+func (l IPList) Contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range l {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
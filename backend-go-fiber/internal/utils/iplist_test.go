@@ -0,0 +1,45 @@
+package utils
+
+import "testing"
+
+func TestParseIPList(t *testing.T) {
+	list, err := ParseIPList("10.0.0.1, 192.168.0.0/16, ::1")
+	if err != nil {
+		t.Fatalf("ParseIPList failed: %v", err)
+	}
+
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"10.0.0.1", true},
+		{"10.0.0.2", false},
+		{"192.168.42.7", true},
+		{"::1", true},
+		{"2001:db8::1", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		if got := list.Contains(tt.ip); got != tt.expected {
+			t.Errorf("Contains(%q) = %v, expected %v", tt.ip, got, tt.expected)
+		}
+	}
+}
+
+func TestParseIPListEmpty(t *testing.T) {
+	list, err := ParseIPList("")
+	if err != nil {
+		t.Fatalf("ParseIPList failed: %v", err)
+	}
+	if list.Contains("127.0.0.1") {
+		t.Error("Empty list should not contain any IP")
+	}
+}
+
+func TestParseIPListInvalid(t *testing.T) {
+	for _, value := range []string{"10.0.0", "10.0.0.0/33", "localhost"} {
+		if _, err := ParseIPList(value); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
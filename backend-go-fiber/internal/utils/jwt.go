@@ -1,8 +1,7 @@
 package utils
 
 import (
-	"os"
-	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,6 +10,17 @@ import (
 type JWTPayload struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
+
+	// Global role at the time the token was issued. Only used for non-security
+	// decisions (rate limit tiers) - AdminOnly re-checks the database.
+	Role string `json:"role,omitempty"`
+
+	// Active organization (tenant) of the session, empty if the user has none
+	OrgID   string `json:"orgId,omitempty"`
+	OrgRole string `json:"orgRole,omitempty"`
+
+	// Preferred locale of the user, empty to negotiate from Accept-Language
+	Locale string `json:"locale,omitempty"`
 }
 
 type Claims struct {
@@ -18,42 +28,45 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-const minJWTSecretLength = 32
+// JWTConfig configures token signing and lifetimes (config.JWT)
+type JWTConfig struct {
+	// Secret signs access tokens; empty uses a development secret
+	Secret           string
+	ExpiresIn        time.Duration
+	RefreshTokenDays int
+}
 
-func getJWTSecret() []byte {
-	secret := os.Getenv("JWT_SECRET")
-
-	// In production, JWT_SECRET must be set and at least 32 characters
-	if os.Getenv("NODE_ENV") == "production" {
-		if secret == "" {
-			panic("JWT_SECRET environment variable is required in production")
-		}
-		if len(secret) < minJWTSecretLength {
-			panic("JWT_SECRET must be at least 32 characters in production")
-		}
-	}
+// devJWTSecret is used until a secret is configured (development and tests)
+const devJWTSecret = "dev-secret-change-in-production-min-32-chars"
 
-	// Fallback for development only
-	if secret == "" {
-		secret = "dev-secret-change-in-production-min-32-chars"
-	}
+var jwtConfig atomic.Pointer[JWTConfig]
 
-	return []byte(secret)
+func init() {
+	ConfigureJWT(JWTConfig{})
 }
 
-func getJWTExpiresIn() time.Duration {
-	expiresIn := os.Getenv("JWT_EXPIRES_IN")
-	if expiresIn == "" {
-		return 15 * time.Minute
+// ConfigureJWT sets the JWT configuration. Zero values use the defaults:
+// a development secret, 15 minute access tokens and 7 day refresh tokens.
+// Production secrets are checked by config.Validate.
+func ConfigureJWT(cfg JWTConfig) {
+	if cfg.Secret == "" {
+		cfg.Secret = devJWTSecret
 	}
-
-	// Parse duration like "15m", "1h", "7d"
-	duration, err := time.ParseDuration(expiresIn)
-	if err == nil {
-		return duration
+	if cfg.ExpiresIn <= 0 {
+		cfg.ExpiresIn = 15 * time.Minute
 	}
+	if cfg.RefreshTokenDays <= 0 {
+		cfg.RefreshTokenDays = 7
+	}
+	jwtConfig.Store(&cfg)
+}
 
-	return 15 * time.Minute
+func getJWTSecret() []byte {
+	return []byte(jwtConfig.Load().Secret)
+}
+
+func getJWTExpiresIn() time.Duration {
+	return jwtConfig.Load().ExpiresIn
 }
 
 func GenerateAccessToken(payload JWTPayload) (string, error) {
@@ -92,13 +105,5 @@ func GetExpiresInSeconds() int {
 }
 
 func GetRefreshTokenExpiresDays() int {
-	daysStr := os.Getenv("REFRESH_TOKEN_EXPIRES_DAYS")
-	if daysStr == "" {
-		return 7
-	}
-	days, err := strconv.Atoi(daysStr)
-	if err != nil {
-		return 7
-	}
-	return days
+	return jwtConfig.Load().RefreshTokenDays
 }
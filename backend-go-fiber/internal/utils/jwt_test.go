@@ -1,17 +1,20 @@
 package utils
 
 import (
-	"os"
 	"testing"
 	"time"
 )
 
+const testJWTSecret = "test-secret-that-is-at-least-32-characters-long"
+
+// setJWTConfig configures JWTs for one test and restores the defaults after it
+func setJWTConfig(tb testing.TB, cfg JWTConfig) {
+	ConfigureJWT(cfg)
+	tb.Cleanup(func() { ConfigureJWT(JWTConfig{}) })
+}
+
 func TestGenerateAccessToken(t *testing.T) {
-	// Set test environment
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(t, JWTConfig{Secret: testJWTSecret, ExpiresIn: 15 * time.Minute})
 
 	payload := JWTPayload{
 		UserID: "user-123",
@@ -40,10 +43,7 @@ func TestGenerateAccessToken(t *testing.T) {
 }
 
 func TestVerifyAccessToken(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(t, JWTConfig{Secret: testJWTSecret, ExpiresIn: 15 * time.Minute})
 
 	payload := JWTPayload{
 		UserID: "user-123",
@@ -70,8 +70,7 @@ func TestVerifyAccessToken(t *testing.T) {
 }
 
 func TestVerifyAccessTokenInvalid(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	defer os.Unsetenv("JWT_SECRET")
+	setJWTConfig(t, JWTConfig{Secret: testJWTSecret})
 
 	_, err := VerifyAccessToken("invalid-token")
 	if err == nil {
@@ -80,8 +79,7 @@ func TestVerifyAccessTokenInvalid(t *testing.T) {
 }
 
 func TestVerifyAccessTokenWrongSecret(t *testing.T) {
-	os.Setenv("JWT_SECRET", "original-secret-that-is-32-chars!")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
+	setJWTConfig(t, JWTConfig{Secret: "original-secret-that-is-32-chars!", ExpiresIn: 15 * time.Minute})
 
 	payload := JWTPayload{
 		UserID: "user-123",
@@ -94,22 +92,16 @@ func TestVerifyAccessTokenWrongSecret(t *testing.T) {
 	}
 
 	// Change secret
-	os.Setenv("JWT_SECRET", "different-secret-also-32-chars!!")
+	ConfigureJWT(JWTConfig{Secret: "different-secret-also-32-chars!!", ExpiresIn: 15 * time.Minute})
 
 	_, err = VerifyAccessToken(token)
 	if err == nil {
 		t.Error("VerifyAccessToken should fail with wrong secret")
 	}
-
-	os.Unsetenv("JWT_SECRET")
-	os.Unsetenv("JWT_EXPIRES_IN")
 }
 
 func TestVerifyAccessTokenExpired(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "1ms") // Very short expiration
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(t, JWTConfig{Secret: testJWTSecret, ExpiresIn: time.Millisecond}) // Very short expiration
 
 	payload := JWTPayload{
 		UserID: "user-123",
@@ -131,8 +123,7 @@ func TestVerifyAccessTokenExpired(t *testing.T) {
 }
 
 func TestGetExpiresInSeconds(t *testing.T) {
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(t, JWTConfig{ExpiresIn: 15 * time.Minute})
 
 	seconds := GetExpiresInSeconds()
 	expected := 15 * 60 // 15 minutes = 900 seconds
@@ -143,7 +134,7 @@ func TestGetExpiresInSeconds(t *testing.T) {
 }
 
 func TestGetExpiresInSecondsDefault(t *testing.T) {
-	os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(t, JWTConfig{})
 
 	seconds := GetExpiresInSeconds()
 	expected := 15 * 60 // Default 15 minutes
@@ -154,8 +145,7 @@ func TestGetExpiresInSecondsDefault(t *testing.T) {
 }
 
 func TestGetRefreshTokenExpiresDays(t *testing.T) {
-	os.Setenv("REFRESH_TOKEN_EXPIRES_DAYS", "14")
-	defer os.Unsetenv("REFRESH_TOKEN_EXPIRES_DAYS")
+	setJWTConfig(t, JWTConfig{RefreshTokenDays: 14})
 
 	days := GetRefreshTokenExpiresDays()
 	if days != 14 {
@@ -164,7 +154,7 @@ func TestGetRefreshTokenExpiresDays(t *testing.T) {
 }
 
 func TestGetRefreshTokenExpiresDaysDefault(t *testing.T) {
-	os.Unsetenv("REFRESH_TOKEN_EXPIRES_DAYS")
+	setJWTConfig(t, JWTConfig{})
 
 	days := GetRefreshTokenExpiresDays()
 	if days != 7 {
@@ -173,8 +163,7 @@ func TestGetRefreshTokenExpiresDaysDefault(t *testing.T) {
 }
 
 func TestGetRefreshTokenExpiresDaysInvalid(t *testing.T) {
-	os.Setenv("REFRESH_TOKEN_EXPIRES_DAYS", "not-a-number")
-	defer os.Unsetenv("REFRESH_TOKEN_EXPIRES_DAYS")
+	setJWTConfig(t, JWTConfig{RefreshTokenDays: -1})
 
 	days := GetRefreshTokenExpiresDays()
 	if days != 7 {
@@ -183,10 +172,7 @@ func TestGetRefreshTokenExpiresDaysInvalid(t *testing.T) {
 }
 
 func BenchmarkGenerateAccessToken(b *testing.B) {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "15m")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(b, JWTConfig{Secret: testJWTSecret, ExpiresIn: 15 * time.Minute})
 
 	payload := JWTPayload{
 		UserID: "user-123",
@@ -200,10 +186,7 @@ func BenchmarkGenerateAccessToken(b *testing.B) {
 }
 
 func BenchmarkVerifyAccessToken(b *testing.B) {
-	os.Setenv("JWT_SECRET", "test-secret-that-is-at-least-32-characters-long")
-	os.Setenv("JWT_EXPIRES_IN", "1h")
-	defer os.Unsetenv("JWT_SECRET")
-	defer os.Unsetenv("JWT_EXPIRES_IN")
+	setJWTConfig(b, JWTConfig{Secret: testJWTSecret, ExpiresIn: time.Hour})
 
 	payload := JWTPayload{
 		UserID: "user-123",
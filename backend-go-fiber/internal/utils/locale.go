@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"backend-go-fiber/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetLocale makes locale the language of the request: responses are translated
+// into it and services see it through the user context (i18n.FromContext)
+func SetLocale(c *fiber.Ctx, locale string) {
+	c.Locals("locale", locale)
+	c.SetUserContext(i18n.WithLocale(c.UserContext(), locale))
+	c.Set(fiber.HeaderContentLanguage, locale)
+}
+
+// Locale returns the language of the request, the default locale if none was negotiated
+func Locale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals("locale").(string); ok && locale != "" {
+		return locale
+	}
+	return i18n.Default().Fallback()
+}
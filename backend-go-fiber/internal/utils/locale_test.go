@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"backend-go-fiber/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSendValidationErrorLocalized(t *testing.T) {
+	input := struct {
+		Email string `json:"email" validate:"required,email"`
+	}{}
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		SetLocale(c, i18n.Default().Negotiate(c.Get(fiber.HeaderAcceptLanguage)))
+		return SendValidationError(c, ValidateStruct(input))
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		message        string
+		field          string
+	}{
+		{"", "Validation failed", "email is required"},
+		{"ru-RU,ru;q=0.9", "Ошибка проверки данных", "Поле «Email» обязательно для заполнения"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+		resp, _ := app.Test(req)
+
+		var body APIResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.Error == nil || len(body.Error.Details) != 1 {
+			t.Fatalf("Unexpected response %+v", body)
+		}
+		if body.Error.Message != tt.message || body.Error.Details[0].Message != tt.field {
+			t.Errorf("Accept-Language %q: got %q / %q", tt.acceptLanguage, body.Error.Message, body.Error.Details[0].Message)
+		}
+	}
+}
+
+func TestValidateLocaleTag(t *testing.T) {
+	type input struct {
+		Locale string `json:"locale" validate:"omitempty,locale"`
+	}
+
+	if errs := ValidateStruct(input{Locale: "ru"}); len(errs) != 0 {
+		t.Errorf("Expected ru to be valid, got %v", errs)
+	}
+	errs := ValidateStruct(input{Locale: "xx"})
+	if len(errs) != 1 || errs[0].Tag != "locale" || errs[0].Param != "en ru" {
+		t.Errorf("Expected locale error listing supported locales, got %+v", errs)
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Logger returns the request-scoped logger set by AccessLogMiddleware
+// (carries request_id and trace_id), or the global logger outside a request.
+// Services that receive c.UserContext() get the same logger with log.Ctx(ctx).
+func Logger(c *fiber.Ctx) *zerolog.Logger {
+	if logger, ok := c.Locals("logger").(*zerolog.Logger); ok && logger != nil {
+		return logger
+	}
+	return &log.Logger
+}
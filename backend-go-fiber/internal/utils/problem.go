@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON is the RFC 9457 Problem Details media type
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemDetails is the RFC 9457 error format, sent instead of the error
+// envelope to clients that ask for it with Accept: application/problem+json
+type ProblemDetails struct {
+	// Type identifies the kind of problem, see ProblemType
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+
+	// Instance is the request ID, for support and log lookup
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions: the error code of the envelope and field errors
+	Code   string       `json:"code"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// WantsProblem reports whether the client prefers problem documents to the envelope.
+// */* and application/json keep the envelope.
+func WantsProblem(c *fiber.Ctx) bool {
+	if !strings.Contains(c.Get(fiber.HeaderAccept), MIMEProblemJSON) {
+		return false
+	}
+	return c.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON
+}
+
+var problemTypeBaseURL atomic.Pointer[string]
+
+// SetProblemTypeBaseURL sets the base of problem type URIs (config.ProblemTypeBaseURL)
+func SetProblemTypeBaseURL(base string) {
+	problemTypeBaseURL.Store(&base)
+}
+
+// ProblemType returns the type URI of an error code: the problem type base URL
+// followed by the code in kebab case (.../problems/validation-error).
+// Without a base URL every problem is "about:blank" and the code tells them apart.
+func ProblemType(code string) string {
+	var base string
+	if p := problemTypeBaseURL.Load(); p != nil {
+		base = *p
+	}
+	if base == "" {
+		return "about:blank"
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// problemTitle is constant per type: the status text for about:blank,
+// the humanized code ("Account suspended") otherwise
+func problemTitle(problemType, code string, status int) string {
+	if problemType == "about:blank" || code == "" {
+		return http.StatusText(status)
+	}
+	title := strings.ToLower(strings.ReplaceAll(code, "_", " "))
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+func sendProblem(c *fiber.Ctx, apiError *APIError, statusCode int, requestID string) error {
+	problemType := ProblemType(apiError.Code)
+
+	return c.Status(statusCode).JSON(ProblemDetails{
+		Type:     problemType,
+		Title:    problemTitle(problemType, apiError.Code, statusCode),
+		Status:   statusCode,
+		Detail:   apiError.Message,
+		Instance: requestID,
+		Code:     apiError.Code,
+		Errors:   apiError.Details,
+	}, MIMEProblemJSON)
+}
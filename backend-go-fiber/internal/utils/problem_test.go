@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/problem+json, application/json", true},
+		{"application/json, application/problem+json;q=0.5", false},
+		{"application/json;q=0.5, application/problem+json", true},
+	}
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if WantsProblem(c) {
+			return c.SendString("problem")
+		}
+		return c.SendString("envelope")
+	})
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(fiber.HeaderAccept, tt.accept)
+		resp, _ := app.Test(req)
+		buf := make([]byte, 16)
+		n, _ := resp.Body.Read(buf)
+		if got := string(buf[:n]) == "problem"; got != tt.expected {
+			t.Errorf("Accept %q: WantsProblem = %v, expected %v", tt.accept, got, tt.expected)
+		}
+	}
+}
+
+func TestSendErrorProblemDetails(t *testing.T) {
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		c.Locals("requestId", "req-1")
+		return SendError(c, "VALIDATION_ERROR", "Validation failed", fiber.StatusBadRequest, []FieldError{
+			{Field: "email", Message: "email is required"},
+		})
+	})
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEProblemJSON)
+	resp, _ := app.Test(req)
+
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != MIMEProblemJSON {
+		t.Errorf("Expected %s, got %q", MIMEProblemJSON, ct)
+	}
+	if vary := resp.Header.Get(fiber.HeaderVary); vary != "Accept" {
+		t.Errorf("Expected Vary: Accept, got %q", vary)
+	}
+
+	var problem ProblemDetails
+	json.NewDecoder(resp.Body).Decode(&problem)
+	expected := ProblemDetails{
+		Type:     "about:blank",
+		Title:    "Bad Request",
+		Status:   fiber.StatusBadRequest,
+		Detail:   "Validation failed",
+		Instance: "req-1",
+		Code:     "VALIDATION_ERROR",
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "email" {
+		t.Errorf("Expected field errors, got %+v", problem.Errors)
+	}
+	problem.Errors = nil
+	if !reflect.DeepEqual(problem, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, problem)
+	}
+
+	// Typed problems once a base URL is configured
+	SetProblemTypeBaseURL("https://example.com/problems")
+	defer SetProblemTypeBaseURL("")
+
+	resp, _ = app.Test(req)
+	json.NewDecoder(resp.Body).Decode(&problem)
+	if problem.Type != "https://example.com/problems/validation-error" || problem.Title != "Validation error" {
+		t.Errorf("Unexpected type/title %q / %q", problem.Type, problem.Title)
+	}
+
+	// The envelope stays the default
+	resp, _ = app.Test(httptest.NewRequest("POST", "/", nil))
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != fiber.MIMEApplicationJSON {
+		t.Errorf("Expected envelope, got %q", ct)
+	}
+}
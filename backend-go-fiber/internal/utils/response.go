@@ -3,6 +3,8 @@ package utils
 import (
 	"time"
 
+	"backend-go-fiber/internal/i18n"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -29,12 +31,18 @@ type APIMeta struct {
 	RequestID string `json:"requestId,omitempty"`
 }
 
+// SendSuccess sends data in the success envelope.
+// 200 responses carry an ETag; GET requests with a matching If-None-Match get 304.
 func SendSuccess(c *fiber.Ctx, data interface{}, statusCode ...int) error {
 	code := fiber.StatusOK
 	if len(statusCode) > 0 {
 		code = statusCode[0]
 	}
 
+	if setETag(c, data, code) {
+		return nil
+	}
+
 	requestID := c.Locals("requestId")
 	var reqIDStr string
 	if requestID != nil {
@@ -51,6 +59,9 @@ func SendSuccess(c *fiber.Ctx, data interface{}, statusCode ...int) error {
 	})
 }
 
+// SendError sends an error in the error envelope, or as a problem document
+// (RFC 9457) if the client asks for one.
+// Message and field messages are translated into the request locale.
 func SendError(c *fiber.Ctx, code string, message string, statusCode int, details ...[]FieldError) error {
 	requestID := c.Locals("requestId")
 	var reqIDStr string
@@ -58,13 +69,28 @@ func SendError(c *fiber.Ctx, code string, message string, statusCode int, detail
 		reqIDStr = requestID.(string)
 	}
 
+	locale := Locale(c)
+	bundle := i18n.Default()
+
 	apiError := &APIError{
 		Code:    code,
-		Message: message,
+		Message: bundle.Error(locale, code, message),
 	}
 
-	if len(details) > 0 {
-		apiError.Details = details[0]
+	// Recorded by the access log
+	c.Locals("errorCode", code)
+
+	if len(details) > 0 && len(details[0]) > 0 {
+		apiError.Details = make([]FieldError, len(details[0]))
+		for i, fe := range details[0] {
+			apiError.Details[i] = FieldError{Field: fe.Field, Message: bundle.T(locale, fe.Message)}
+		}
+	}
+
+	// The envelope stays the default, see WantsProblem
+	c.Vary(fiber.HeaderAccept)
+	if WantsProblem(c) {
+		return sendProblem(c, apiError, statusCode, reqIDStr)
 	}
 
 	return c.Status(statusCode).JSON(APIResponse{
@@ -79,11 +105,13 @@ func SendError(c *fiber.Ctx, code string, message string, statusCode int, detail
 
 // SendValidationError sends a validation error response with field details
 func SendValidationError(c *fiber.Ctx, validationErrors []ValidationError) error {
+	locale := Locale(c)
+
 	fieldErrors := make([]FieldError, len(validationErrors))
 	for i, ve := range validationErrors {
 		fieldErrors[i] = FieldError{
 			Field:   ve.Field,
-			Message: ve.Message,
+			Message: i18n.Default().Validation(locale, ve.Tag, ve.Field, ve.Param, ve.Message),
 		}
 	}
 	return SendError(c, "VALIDATION_ERROR", "Validation failed", fiber.StatusBadRequest, fieldErrors)
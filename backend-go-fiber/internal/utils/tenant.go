@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMissingTenant is returned by tenant-scoped queries without an organization
+var ErrMissingTenant = errors.New("tenant scope requires an organization id")
+
+// TenantColumn is the column that holds the owning organization of a row
+const TenantColumn = "organization_id"
+
+// TenantScope restricts a GORM query to rows owned by the given organization.
+// An empty orgID fails the query instead of silently returning every tenant's rows.
+// Usage:
+//
+//	db.Scopes(utils.TenantScope(orgID)).Find(&projects)
+func TenantScope(orgID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if orgID == "" {
+			db.AddError(ErrMissingTenant)
+			return db
+		}
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: TenantColumn},
+			Value:  orgID,
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 
+	"backend-go-fiber/internal/i18n"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -21,12 +23,21 @@ func init() {
 		}
 		return name
 	})
+
+	// locale accepts the locales the i18n catalogs support
+	Validator.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return i18n.Default().Supports(fl.Field().String())
+	})
 }
 
 // ValidationError represents a single validation error
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+
+	// Failed validator tag and its parameter, used to translate Message
+	Tag   string `json:"-"`
+	Param string `json:"-"`
 }
 
 // ValidateStruct validates a struct and returns a slice of validation errors
@@ -37,6 +48,7 @@ func ValidateStruct(s interface{}) []ValidationError {
 	if err != nil {
 		for _, err := range err.(validator.ValidationErrors) {
 			var message string
+			param := err.Param()
 
 			switch err.Tag() {
 			case "required":
@@ -63,6 +75,9 @@ func ValidateStruct(s interface{}) []ValidationError {
 				message = err.Field() + " must be less than or equal to " + err.Param()
 			case "eqfield":
 				message = err.Field() + " must be equal to " + err.Param()
+			case "locale":
+				param = strings.Join(i18n.Default().Locales(), " ")
+				message = err.Field() + " must be one of: " + param
 			default:
 				message = err.Field() + " is invalid"
 			}
@@ -70,6 +85,8 @@ func ValidateStruct(s interface{}) []ValidationError {
 			errors = append(errors, ValidationError{
 				Field:   err.Field(),
 				Message: message,
+				Tag:     err.Tag(),
+				Param:   param,
 			})
 		}
 	}
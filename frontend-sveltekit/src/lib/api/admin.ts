@@ -31,8 +31,13 @@ export interface DashboardStats {
 		createdAt: string;
 	}>;
 	recentActivity: Array<{
-		type: string;
+		id: string;
+		type: 'info' | 'warning';
+		action: string;
 		message: string;
+		actorEmail: string;
+		targetType: string;
+		targetId: string;
 		timestamp: string;
 	}>;
 }